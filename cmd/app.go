@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	goerrors "errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/i18n"
+	"ypeskov/go_hillel_9/internal/jwtkeys"
+	log "ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/migrations"
+	"ypeskov/go_hillel_9/internal/password"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/server"
+	"ypeskov/go_hillel_9/server/routes"
+)
+
+func serve(cfg *config.Config, logger *log.Logger) error {
+	logger.Info("Starting the application...")
+
+	catalogue, err := i18n.New(cfg)
+	if err != nil {
+		return fmt.Errorf("error loading message catalogue: %w", err)
+	}
+
+	var db database.Database
+	if cfg.InMemoryStorage {
+		logger.Warn("IN_MEMORY_STORAGE is enabled, the data is lost on restart")
+	} else {
+		db = database.GetDB(cfg, logger)
+
+		if cfg.AutoMigrate {
+			err = autoMigrate(db, logger)
+			if err != nil {
+				return fmt.Errorf("error migrating the database: %w", err)
+			}
+		}
+	}
+
+	routes, err := newRoutes(cfg, logger, db)
+	if err != nil {
+		return err
+	}
+
+	err = server.New(cfg, routes, catalogue).Start()
+	if err != nil {
+		return fmt.Errorf("error starting the server: %w", err)
+	}
+
+	return nil
+}
+
+// autoMigrate applies the pending migrations on startup, replicas starting together wait for each other
+func autoMigrate(db database.Database, logger *log.Logger) error {
+	migrator, err := migrations.New(db, logger)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		return err
+	}
+	logger.Infof("Applied %d migration(s)", applied)
+
+	return nil
+}
+
+// newRoutes wires the services the same way for the server and the commands
+func newRoutes(cfg *config.Config, logger *log.Logger, db database.Database) (*routes.Routes, error) {
+	keys, err := jwtkeys.Load(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading JWT keys: %w", err)
+	}
+
+	hasher, err := password.NewHasher(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring password hashing: %w", err)
+	}
+
+	policy, err := password.NewPolicy(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading password policy: %w", err)
+	}
+
+	return routes.New(logger, db, cfg, keys, hasher, policy), nil
+}
+
+// app gives the commands the services of the server
+type app struct {
+	*routes.Routes
+	db database.Database
+}
+
+func newApp(cfg *config.Config, logger *log.Logger) (*app, error) {
+	if cfg.InMemoryStorage {
+		return nil, goerrors.New("IN_MEMORY_STORAGE is enabled, the commands need a database")
+	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := newRoutes(cfg, logger, db)
+	if err != nil {
+		_ = db.Close()
+
+		return nil, err
+	}
+
+	return &app{Routes: routes, db: db}, nil
+}
+
+func (a *app) Close() {
+	_ = a.db.Close()
+}
+
+func (a *app) findUser(ctx context.Context, email string) (*models.User, error) {
+	user := a.UsersService.GetUserByEmail(ctx, email)
+	if user == nil {
+		return nil, fmt.Errorf("no user with email %s", email)
+	}
+
+	return user, nil
+}
+
+// findUserTypeId returns the id of the user type with the code
+func (a *app) findUserTypeId(ctx context.Context, typeCode string) (int32, error) {
+	userTypes, err := a.UserTypeService.GetUserTypesList(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, userType := range userTypes {
+		if strings.EqualFold(userType.TypeCode, typeCode) {
+			return int32(userType.Id), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no user type %s", typeCode)
+}
+
+// parseFlags parses the arguments of a command and checks the flags it can't do without
+func parseFlags(flags *flag.FlagSet, args []string, required ...string) error {
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	for _, name := range required {
+		if flags.Lookup(name).Value.String() == "" {
+			return fmt.Errorf("-%s is required", name)
+		}
+	}
+
+	return nil
+}
+
+// readPassword reads the password from the standard input, so it doesn't end up in the shell history
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read the password: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
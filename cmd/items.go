@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"ypeskov/go_hillel_9/internal/config"
+	log "ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/services"
+)
+
+func runItems(cfg *config.Config, logger *log.Logger, args []string) error {
+	if len(args) == 0 || args[0] != "import" {
+		return fmt.Errorf("usage: items import -email E -file items.csv [-format csv] [-dry-run]")
+	}
+
+	app, err := newApp(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	return app.importItems(context.Background(), args[1:])
+}
+
+// importItems creates items of a seller from a CSV or NDJSON file the same way as POST /items/import
+func (a *app) importItems(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("items import", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the seller")
+	file := flags.String("file", "", "CSV file with a header row or NDJSON file")
+	format := flags.String("format", "", "csv or ndjson, detected by the extension of the file if not given")
+	dryRun := flags.Bool("dry-run", false, "only validate the file")
+	err := parseFlags(flags, args, "email", "file")
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+		if *format == "jsonl" {
+			*format = services.FormatNdjson
+		}
+	}
+
+	seller, err := a.findUser(ctx, *email)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	report, err := a.ItemsTransferService.ImportItems(ctx, seller, *format, f, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, rowErr := range report.Errors {
+		fmt.Printf("row %d: %v\n", rowErr.Row, rowErr.Error)
+	}
+	if report.DryRun {
+		fmt.Printf("Dry run: %d of %d row(s) are valid\n", report.Valid, report.Rows)
+	} else {
+		fmt.Printf("Imported %d of %d row(s)\n", report.Imported, report.Rows)
+	}
+
+	return nil
+}
@@ -2,13 +2,26 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"ypeskov/go_hillel_9/internal/config"
-	"ypeskov/go_hillel_9/internal/database"
 	log "ypeskov/go_hillel_9/internal/log"
-	"ypeskov/go_hillel_9/server"
-	"ypeskov/go_hillel_9/server/routes"
 )
 
+const usage = `Usage: auction [command] [arguments]
+
+Commands:
+  serve                starts the API server, the default without a command
+  migrate              up | down N | status | create [-dir db] NAME
+  user create          -email E -first-name F -last-name L [-password P] [-type BUYER] [-verified]
+  user set-role        -email E -type TYPE
+  user reset-password  -email E [-password P]
+  items import         -email E -file items.csv [-format csv] [-dry-run]
+  seed                 [-users 12] [-items 40] [-comments 80] [-sales 10] [-seed N]
+  token issue          -email E
+
+Passwords that aren't given as flags are read from the standard input.
+`
+
 func main() {
 	cfg, err := config.NewConfig()
 	if err != nil {
@@ -17,16 +30,58 @@ func main() {
 		return
 	}
 
-	logger := log.New(cfg)
-	logger.Info("Starting the application...")
+	command := "serve"
+	var args []string
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
+	}
 
-	db := database.GetDB(cfg, logger)
+	if needsValidConfig(command, args) {
+		err = cfg.Validate()
+		if err != nil {
+			fmt.Printf("Invalid config: %v\n", err)
 
-	routes := routes.New(logger, db, cfg)
+			return
+		}
+	}
+
+	logger := log.New(cfg)
+
+	switch command {
+	case "serve":
+		err = serve(cfg, logger)
+	case "migrate":
+		err = runMigrate(cfg, logger, args)
+	case "user":
+		err = runUser(cfg, logger, args)
+	case "items":
+		err = runItems(cfg, logger, args)
+	case "seed":
+		err = runSeed(cfg, logger, args)
+	case "token":
+		err = runToken(cfg, logger, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Print(usage)
+		err = fmt.Errorf("unknown command %q", command)
+	}
 
-	server := server.New(cfg, routes)
-	err = server.Start()
 	if err != nil {
-		logger.Errorf("Error starting the server: %v", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// needsValidConfig reports whether the command serves or connects to the database,
+// help and migrate create work without a valid config.
+func needsValidConfig(command string, args []string) bool {
+	switch command {
+	case "serve", "user", "items", "seed", "token":
+		return true
+	case "migrate":
+		return len(args) > 0 && args[0] != "create"
+	default:
+		return false
 	}
 }
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	goerrors "errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/database"
+	log "ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/migrations"
+)
+
+var errMigrateUsage = goerrors.New("usage: migrate up | down N | status | create [-dir db] NAME")
+
+// runMigrate runs the migrate subcommand against the database of the configuration
+func runMigrate(cfg *config.Config, logger *log.Logger, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	if args[0] == "create" {
+		return createMigration(args[1:])
+	}
+
+	if cfg.InMemoryStorage {
+		return goerrors.New("IN_MEMORY_STORAGE is enabled, there is no database to migrate")
+	}
+
+	connection, err := database.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	migrator, err := migrations.New(connection, logger)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		fmt.Printf("Applied %d migration(s)\n", applied)
+
+		return err
+	case "down":
+		if len(args) != 2 {
+			return errMigrateUsage
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid number of migrations to revert: %s", args[1])
+		}
+		reverted, err := migrator.Down(ctx, n)
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+
+		return err
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errMigrateUsage
+	}
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrations.Migrator) error {
+	version, dirty, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Version: %d", version)
+	if dirty {
+		fmt.Print(" (dirty)")
+	}
+	fmt.Println()
+
+	for _, migration := range migrator.Migrations() {
+		state := "pending"
+		if migration.Version <= version {
+			state = "applied"
+		}
+		fmt.Printf("%06d  %-8s %s\n", migration.Version, state, migration.Name)
+	}
+
+	return nil
+}
+
+func createMigration(args []string) error {
+	flags := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := flags.String("dir", "db", "directory containing the migrations directories")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errMigrateUsage
+	}
+
+	files, err := migrations.Create(*dir, flags.Arg(0))
+	for _, file := range files {
+		fmt.Printf("Created %s\n", file)
+	}
+
+	return err
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	log "ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+// seedPassword is the password of all generated users
+const seedPassword = "auction demo password"
+
+var (
+	seedFirstNames = []string{"Olena", "Taras", "Iryna", "Dmytro", "Sofia", "Andrii", "Kateryna", "Maksym",
+		"Anna", "Bohdan", "Yulia", "Oleksii", "Mariia", "Serhii", "Daria", "Ivan"}
+	seedLastNames = []string{"Kovalenko", "Shevchenko", "Bondarenko", "Tkachenko", "Kravchenko", "Melnyk",
+		"Boiko", "Koval", "Oliinyk", "Lysenko", "Marchenko", "Savchenko"}
+	seedAdjectives = []string{"Vintage", "Antique", "Handmade", "Restored", "Rare", "Signed", "Mint condition",
+		"Soviet era", "Hand-painted", "Limited edition"}
+	seedThings = []string{"film camera", "typewriter", "vinyl record", "road bike", "oak chair", "pocket watch",
+		"radio receiver", "embroidered shirt", "ceramic vase", "chess set", "fountain pen", "wall clock",
+		"oil painting", "book collection", "guitar"}
+	seedDescriptions = []string{"Works perfectly, a few scratches on the case.", "Kept in a dry place for years.",
+		"Comes with the original box.", "Pick up in Kyiv or shipping by Nova Poshta.",
+		"Cleaned and serviced last month.", "Some signs of use, see the photos."}
+	seedComments = []string{"Is the price negotiable?", "Can you send more photos?", "Does it still work?",
+		"Would you ship to Lviv?", "Great find!", "What year is it from?", "Any defects I should know about?",
+		"I can pick it up tomorrow.", "Is it still available?"}
+)
+
+// runSeed fills the database with fake users, items, comments and sales for a local demo.
+// There are no bids in the auction yet, a sale to a buyer above the initial price stands for the winning bid.
+func runSeed(cfg *config.Config, logger *log.Logger, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	usersCount := flags.Int("users", 12, "number of users, a third of them sellers")
+	itemsCount := flags.Int("items", 40, "number of items")
+	commentsCount := flags.Int("comments", 80, "number of comments")
+	salesCount := flags.Int("sales", 10, "number of items sold to buyers")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "random seed, the same seed generates the same data")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *usersCount < 2 {
+		return fmt.Errorf("at least 2 users are needed, a seller and a buyer")
+	}
+
+	app, err := newApp(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(*seed))
+	fmt.Printf("Seed: %d\n", *seed)
+
+	sellers, buyers, err := app.seedUsers(ctx, rng, *usersCount)
+	if err != nil {
+		return err
+	}
+	items, err := app.seedItems(ctx, rng, sellers, *itemsCount)
+	if err != nil {
+		return err
+	}
+	comments, err := app.seedComments(ctx, rng, append(sellers, buyers...), items, *commentsCount)
+	if err != nil {
+		return err
+	}
+	sales, err := app.seedSales(ctx, rng, buyers, items, *salesCount)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created %d sellers, %d buyers, %d items, %d comments and %d sales\n", len(sellers), len(buyers),
+		len(items), comments, sales)
+	fmt.Printf("All users have the password %q\n", seedPassword)
+
+	return nil
+}
+
+func (a *app) seedUsers(ctx context.Context, rng *rand.Rand, count int) ([]*models.User, []*models.User, error) {
+	sellerTypeId, err := a.findUserTypeId(ctx, models.SellerTypeCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	buyerTypeId, err := a.findUserTypeId(ctx, models.BuyerTypeCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sellers, buyers []*models.User
+	for i := 0; i < count; i++ {
+		firstName := seedFirstNames[rng.Intn(len(seedFirstNames))]
+		lastName := seedLastNames[rng.Intn(len(seedLastNames))]
+		user := &models.User{
+			FirstName: firstName,
+			LastName:  lastName,
+			// the number keeps the emails unique across runs
+			Email: fmt.Sprintf("%s.%s.%d@example.com", strings.ToLower(firstName), strings.ToLower(lastName),
+				rng.Intn(1_000_000)),
+			PasswordHash: seedPassword,
+			UserTypeId:   buyerTypeId,
+		}
+		// at least one seller, whatever the count
+		isSeller := i%3 == 0
+		if isSeller {
+			user.UserTypeId = sellerTypeId
+		}
+
+		user, err = a.UsersService.CreateUser(ctx, user)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = a.UsersService.SetEmailVerified(ctx, user.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		user.EmailVerified = true
+
+		if isSeller {
+			sellers = append(sellers, user)
+		} else {
+			buyers = append(buyers, user)
+		}
+	}
+
+	return sellers, buyers, nil
+}
+
+func (a *app) seedItems(ctx context.Context, rng *rand.Rand, sellers []*models.User,
+	count int) ([]*models.Item, error) {
+	items := make([]*models.Item, 0, count)
+	for i := 0; i < count; i++ {
+		seller := sellers[rng.Intn(len(sellers))]
+		description := seedDescriptions[rng.Intn(len(seedDescriptions))]
+		item, err := a.ItemsService.CreateItem(ctx, &models.Item{
+			UserId:       seller.Id,
+			Title:        seedAdjectives[rng.Intn(len(seedAdjectives))] + " " + seedThings[rng.Intn(len(seedThings))],
+			InitialPrice: roundPrice(5 + rng.Float64()*995),
+			Description:  &description,
+		}, seller)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (a *app) seedComments(ctx context.Context, rng *rand.Rand, users []*models.User, items []*models.Item,
+	count int) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	for i := 0; i < count; i++ {
+		_, err := a.ItemsService.CreateItemComment(ctx, &models.ItemComment{
+			UserId:  users[rng.Intn(len(users))].Id,
+			ItemId:  items[rng.Intn(len(items))].Id,
+			Comment: seedComments[rng.Intn(len(seedComments))],
+		})
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return count, nil
+}
+
+// seedSales sells random items to buyers for up to half more than the initial price
+func (a *app) seedSales(ctx context.Context, rng *rand.Rand, buyers []*models.User, items []*models.Item,
+	count int) (int, error) {
+	sold := 0
+	for _, i := range rng.Perm(len(items)) {
+		if sold == count {
+			break
+		}
+
+		item := items[i]
+		buyer := buyers[rng.Intn(len(buyers))]
+		_, err := a.ItemsService.MarkItemSold(ctx, item.Id, item.UserId, buyer.Id,
+			roundPrice(item.InitialPrice*(1+rng.Float64()/2)))
+		if err != nil {
+			return sold, err
+		}
+		_, err = a.ItemsService.ConfirmItemSale(ctx, item.Id, buyer.Id)
+		if err != nil {
+			return sold, err
+		}
+		sold++
+	}
+
+	return sold, nil
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"ypeskov/go_hillel_9/internal/config"
+	log "ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/services"
+)
+
+// runToken issues tokens of a user without the password, to call the API while debugging
+func runToken(cfg *config.Config, logger *log.Logger, args []string) error {
+	if len(args) == 0 || args[0] != "issue" {
+		return fmt.Errorf("usage: token issue -email E")
+	}
+
+	flags := flag.NewFlagSet("token issue", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user")
+	err := parseFlags(flags, args[1:], "email")
+	if err != nil {
+		return err
+	}
+
+	app, err := newApp(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	ctx := context.Background()
+	user, err := app.findUser(ctx, *email)
+	if err != nil {
+		return err
+	}
+
+	// the session shows up in the sessions of the user like any other login
+	result, err := app.SessionsService.LoginUser(ctx, user, services.SessionClient{
+		Device:    "command line",
+		UserAgent: "auction token issue",
+		Ip:        "127.0.0.1",
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Tokens == nil {
+		fmt.Printf("Two-factor authentication is enabled, MFA token: %s\n", result.MfaToken)
+
+		return nil
+	}
+
+	fmt.Printf("Access token: %s\nRefresh token: %s\n", result.Tokens.AccessToken, result.Tokens.RefreshToken)
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"ypeskov/go_hillel_9/internal/config"
+	log "ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+func runUser(cfg *config.Config, logger *log.Logger, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: user create | set-role | reset-password")
+	}
+
+	app, err := newApp(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	ctx := context.Background()
+	switch args[0] {
+	case "create":
+		return app.createUser(ctx, args[1:])
+	case "set-role":
+		return app.setRole(ctx, args[1:])
+	case "reset-password":
+		return app.resetPassword(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown user command %q", args[0])
+	}
+}
+
+func (a *app) createUser(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user")
+	firstName := flags.String("first-name", "", "first name")
+	lastName := flags.String("last-name", "", "last name")
+	password := flags.String("password", "", "password, read from the standard input if not given")
+	typeCode := flags.String("type", models.BuyerTypeCode, "user type: SELLER, BUYER, ADMIN or a custom one")
+	verified := flags.Bool("verified", false, "confirm the email instead of sending a verification email")
+	err := parseFlags(flags, args, "email", "first-name", "last-name")
+	if err != nil {
+		return err
+	}
+
+	if *password == "" {
+		*password, err = readPassword()
+		if err != nil {
+			return err
+		}
+	}
+
+	userTypeId, err := a.findUserTypeId(ctx, *typeCode)
+	if err != nil {
+		return err
+	}
+
+	user := &models.User{
+		FirstName:    *firstName,
+		LastName:     *lastName,
+		Email:        *email,
+		PasswordHash: *password,
+		UserTypeId:   userTypeId,
+	}
+	err = user.Validate()
+	if err != nil {
+		return err
+	}
+
+	user, err = a.UsersService.CreateUserByOperator(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	if *verified {
+		err = a.UsersService.SetEmailVerified(ctx, user.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Created user %d %s\n", user.Id, user.Email)
+
+	return nil
+}
+
+func (a *app) setRole(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("user set-role", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user")
+	typeCode := flags.String("type", "", "new user type")
+	err := parseFlags(flags, args, "email", "type")
+	if err != nil {
+		return err
+	}
+
+	user, err := a.findUser(ctx, *email)
+	if err != nil {
+		return err
+	}
+
+	*typeCode = strings.ToUpper(*typeCode)
+	err = a.UserAdminService.ChangeUserType(ctx, nil, user.Id, *typeCode)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("User %s is now %s\n", user.Email, *typeCode)
+
+	return nil
+}
+
+// resetPassword sets a new password and logs the user out everywhere
+func (a *app) resetPassword(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user")
+	password := flags.String("password", "", "new password, read from the standard input if not given")
+	err := parseFlags(flags, args, "email")
+	if err != nil {
+		return err
+	}
+
+	user, err := a.findUser(ctx, *email)
+	if err != nil {
+		return err
+	}
+
+	if *password == "" {
+		*password, err = readPassword()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = a.UsersService.SetPassword(ctx, user.Id, *password)
+	if err != nil {
+		return err
+	}
+
+	err = a.RevocationService.RevokeAllUserTokens(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Password of %s changed, the sessions of the user are revoked\n", user.Email)
+
+	return nil
+}
@@ -1,57 +1,13 @@
 #!/usr/bin/env bash
 
-source ../.env
+# Runs the migrations embedded in the binary, the database is configured by ../.env
+# Usage: migrate.sh up | down N | status | create NAME
 
-user=${DB_USER:-postgres}
-password=${DB_PASSWORD:-auction}
-db_host=${DB_HOST:-localhost}
-db_port=${DB_PORT:-5432}
-db_name=${DB_NAME:-auction}
+cd .. || exit 1
 
-if [ -z "$user" ]; then
-  echo "No database user specified."
+if [ -z "$1" ]; then
+  echo "No action specified: up, down N, status or create NAME."
   exit 1
 fi
 
-if [ -z "$password" ]; then
-  echo "No database password specified."
-  exit 1
-fi
-
-if [ -z "$db_host" ]; then
-  echo "No database host specified."
-  exit 1
-fi
-
-if [ -z "$db_port" ]; then
-  echo "No database port specified."
-  exit 1
-fi
-
-if [ -z "$db_name" ]; then
-  echo "No database name specified."
-  exit 1
-fi
-
-action=$1
-
-if [ -z "$action" ]; then
-  echo "No action specified: up or down."
-  exit 1
-fi
-
-if [ "$action" != "up" ] && [ "$action" != "down" ]; then
-  echo "Invalid action: $action."
-  echo "Possible values: up, down."
-  exit 1
-fi
-
-migration_number=$2
-
-db_url="postgres://$user:$password@$db_host:$db_port/$db_name?sslmode=disable"
-
-if [ -z "$migration_number" ]; then
-  migrate -database "$db_url" -path migrations $action
-else
-  migrate -database "$db_url" -path migrations $action $migration_number
-fi
+go run ./cmd migrate "$@"
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN password_hash VARCHAR(255);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN user_type_id;
+
+DROP TABLE user_types;
@@ -0,0 +1,26 @@
+DROP TABLE IF EXISTS refresh_tokens;
+
+CREATE TABLE sessions
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    device       VARCHAR(255) NOT NULL DEFAULT '',
+    user_agent   TEXT         NOT NULL DEFAULT '',
+    ip           VARCHAR(45)  NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at   TIMESTAMP
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+-- every refresh of a session issues a new token, all tokens of a session form one family
+CREATE TABLE refresh_tokens
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER     NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    used_at    TIMESTAMP
+);
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    DROP COLUMN tokens_valid_after;
+
+DROP TABLE IF EXISTS revoked_tokens;
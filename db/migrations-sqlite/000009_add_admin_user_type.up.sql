@@ -0,0 +1,9 @@
+-- only self-assignable types can be chosen at sign-up, the administrator type never is
+ALTER TABLE user_types
+    ADD COLUMN self_assignable BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE user_types
+SET self_assignable = TRUE;
+
+INSERT INTO user_types (type_name, type_description, type_code)
+VALUES ('Administrator', 'Administrator of the marketplace', 'ADMIN');
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS login_lockout_events;
+
+DROP TABLE IF EXISTS login_failures;
@@ -0,0 +1,11 @@
+ALTER TABLE user_types
+    DROP COLUMN mfa_required;
+
+DROP TABLE IF EXISTS mfa_recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN mfa_last_used_step;
+ALTER TABLE users
+    DROP COLUMN mfa_secret;
+ALTER TABLE users
+    DROP COLUMN mfa_enabled;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS user_moderation_events;
+
+ALTER TABLE users
+    DROP COLUMN status_reason;
+ALTER TABLE users
+    DROP COLUMN suspended_until;
+ALTER TABLE users
+    DROP COLUMN status;
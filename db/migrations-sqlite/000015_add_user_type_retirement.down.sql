@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS user_types_type_code_idx;
+
+ALTER TABLE user_types
+    DROP COLUMN retired_at;
@@ -0,0 +1,4 @@
+ALTER TABLE user_types
+    ADD COLUMN retired_at TIMESTAMP;
+
+CREATE UNIQUE INDEX user_types_type_code_idx ON user_types (type_code);
@@ -0,0 +1,7 @@
+-- SQLite can't add a column with a non-constant default, the repositories always set created_at
+ALTER TABLE users
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+-- the real sign-up time of existing users is unknown, the first login is the closest guess we have
+UPDATE users
+SET created_at = last_login_utc;
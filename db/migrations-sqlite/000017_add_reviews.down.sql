@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS reviews;
+
+ALTER TABLE items
+    DROP COLUMN sold_at;
+ALTER TABLE items
+    DROP COLUMN buyer_id;
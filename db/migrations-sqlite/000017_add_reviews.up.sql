@@ -0,0 +1,26 @@
+ALTER TABLE items
+    ADD COLUMN buyer_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE items
+    ADD COLUMN sold_at TIMESTAMP;
+
+-- reviews outlive the item, item_id becomes NULL when the seller deletes it
+CREATE TABLE reviews
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id        INTEGER     REFERENCES items (id) ON DELETE SET NULL,
+    reviewer_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    reviewee_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    rating         SMALLINT    NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    review         TEXT        NOT NULL DEFAULT '',
+    response       TEXT,
+    responded_at   TIMESTAMP,
+    status         VARCHAR(16) NOT NULL DEFAULT 'PUBLISHED',
+    dispute_reason TEXT        NOT NULL DEFAULT '',
+    disputed_at    TIMESTAMP,
+    resolved_by    INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    resolved_at    TIMESTAMP,
+    created_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (item_id, reviewer_id)
+);
+
+CREATE INDEX reviews_reviewee_id_idx ON reviews (reviewee_id);
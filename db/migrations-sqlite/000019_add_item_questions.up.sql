@@ -0,0 +1,12 @@
+CREATE TABLE item_questions
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id     INTEGER NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    asker_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    question    TEXT    NOT NULL,
+    answer      TEXT,
+    answered_at TIMESTAMP,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX item_questions_item_id_idx ON item_questions (item_id);
@@ -0,0 +1,15 @@
+-- the request holding a key changes it only with its own lock_token, so a request whose lock timed out
+-- can't store a response for or release the key of the retry that took it over
+CREATE TABLE idempotency_keys
+(
+    user_id         INTEGER      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash    VARCHAR(64)  NOT NULL,
+    lock_token      VARCHAR(64)  NOT NULL,
+    status          INTEGER,
+    content_type    VARCHAR(255),
+    response_body   BLOB,
+    created_at      TIMESTAMP    NOT NULL,
+    expires_at      TIMESTAMP    NOT NULL,
+    PRIMARY KEY (user_id, idempotency_key)
+);
@@ -0,0 +1,11 @@
+// Package db embeds the schema migrations into the binary
+package db
+
+import (
+	"embed"
+)
+
+// Migrations holds migrations for Postgres in migrations and for SQLite in migrations-sqlite
+//
+//go:embed migrations/*.sql migrations-sqlite/*.sql
+var Migrations embed.FS
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS refresh_tokens;
+
+DROP TABLE IF EXISTS sessions;
+
+CREATE TABLE refresh_tokens
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER      NOT NULL UNIQUE,
+    token      VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,11 @@
+CREATE TABLE revoked_tokens
+(
+    jti        VARCHAR(64) PRIMARY KEY,
+    user_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- tokens issued before this moment are rejected, used to log a user out everywhere
+ALTER TABLE users
+    ADD COLUMN tokens_valid_after TIMESTAMP WITHOUT TIME ZONE;
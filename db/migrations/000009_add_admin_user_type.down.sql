@@ -0,0 +1,4 @@
+DELETE FROM user_types WHERE type_code = 'ADMIN';
+
+ALTER TABLE user_types
+    DROP COLUMN self_assignable;
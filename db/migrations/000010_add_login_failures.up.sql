@@ -0,0 +1,22 @@
+-- subject is a lower-cased email for 'account' and an address for 'ip',
+-- emails are tracked whether an account exists or not
+CREATE TABLE login_failures
+(
+    subject_type    VARCHAR(16)  NOT NULL,
+    subject         VARCHAR(255) NOT NULL,
+    failures        INTEGER      NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    locked_until    TIMESTAMP WITHOUT TIME ZONE,
+    PRIMARY KEY (subject_type, subject)
+);
+
+CREATE TABLE login_lockout_events
+(
+    id           SERIAL PRIMARY KEY,
+    subject_type VARCHAR(16)  NOT NULL,
+    subject      VARCHAR(255) NOT NULL,
+    event        VARCHAR(16)  NOT NULL,
+    ip           VARCHAR(45)  NOT NULL DEFAULT '',
+    admin_id     INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,17 @@
+ALTER TABLE users
+    ADD COLUMN mfa_enabled        BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN mfa_secret         VARCHAR(64),
+    ADD COLUMN mfa_last_used_step BIGINT;
+
+CREATE TABLE mfa_recovery_codes
+(
+    id        SERIAL PRIMARY KEY,
+    user_id   INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at   TIMESTAMP WITHOUT TIME ZONE
+);
+
+CREATE INDEX mfa_recovery_codes_user_id_idx ON mfa_recovery_codes (user_id);
+
+ALTER TABLE user_types
+    ADD COLUMN mfa_required BOOLEAN NOT NULL DEFAULT FALSE;
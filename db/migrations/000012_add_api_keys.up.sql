@@ -0,0 +1,17 @@
+CREATE TABLE api_keys
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         VARCHAR(255) NOT NULL,
+    -- the first characters of the key, so users can tell their keys apart
+    prefix       VARCHAR(16)  NOT NULL,
+    key_hash     VARCHAR(64)  NOT NULL UNIQUE,
+    -- space separated list of scopes
+    scopes       TEXT         NOT NULL DEFAULT '',
+    created_at   TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at   TIMESTAMP WITHOUT TIME ZONE,
+    last_used_at TIMESTAMP WITHOUT TIME ZONE,
+    revoked_at   TIMESTAMP WITHOUT TIME ZONE
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
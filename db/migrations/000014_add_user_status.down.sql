@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS user_moderation_events;
+
+ALTER TABLE users
+    DROP COLUMN status_reason,
+    DROP COLUMN suspended_until,
+    DROP COLUMN status;
@@ -0,0 +1,18 @@
+ALTER TABLE users
+    ADD COLUMN status          VARCHAR(16) NOT NULL DEFAULT 'ACTIVE',
+    ADD COLUMN suspended_until TIMESTAMP WITHOUT TIME ZONE,
+    ADD COLUMN status_reason   TEXT        NOT NULL DEFAULT '';
+
+-- audit log of status and role changes made by administrators
+CREATE TABLE user_moderation_events
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    admin_id   INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    action     VARCHAR(32) NOT NULL,
+    reason     TEXT        NOT NULL DEFAULT '',
+    details    TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX user_moderation_events_user_id_idx ON user_moderation_events (user_id);
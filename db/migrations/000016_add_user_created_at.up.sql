@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC');
+
+-- the real sign-up time of existing users is unknown, the first login is the closest guess we have
+UPDATE users
+SET created_at = LEAST(created_at, last_login_utc);
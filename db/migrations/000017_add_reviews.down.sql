@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS reviews;
+
+ALTER TABLE items
+    DROP COLUMN sold_at,
+    DROP COLUMN buyer_id;
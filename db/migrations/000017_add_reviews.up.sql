@@ -0,0 +1,25 @@
+ALTER TABLE items
+    ADD COLUMN buyer_id INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN sold_at  TIMESTAMP WITHOUT TIME ZONE;
+
+-- reviews outlive the item, item_id becomes NULL when the seller deletes it
+CREATE TABLE reviews
+(
+    id             SERIAL PRIMARY KEY,
+    item_id        INTEGER     REFERENCES items (id) ON DELETE SET NULL,
+    reviewer_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    reviewee_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    rating         SMALLINT    NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    review         TEXT        NOT NULL DEFAULT '',
+    response       TEXT,
+    responded_at   TIMESTAMP WITHOUT TIME ZONE,
+    status         VARCHAR(16) NOT NULL DEFAULT 'PUBLISHED',
+    dispute_reason TEXT        NOT NULL DEFAULT '',
+    disputed_at    TIMESTAMP WITHOUT TIME ZONE,
+    resolved_by    INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    resolved_at    TIMESTAMP WITHOUT TIME ZONE,
+    created_at     TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (item_id, reviewer_id)
+);
+
+CREATE INDEX reviews_reviewee_id_idx ON reviews (reviewee_id);
@@ -0,0 +1,35 @@
+-- user_a_id is always the smaller id, so a pair of users has one conversation per item,
+-- conversations about an item go away with it, NULL would clash with the general conversation in the unique index
+CREATE TABLE conversations
+(
+    id              SERIAL PRIMARY KEY,
+    user_a_id       INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_b_id       INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id         INTEGER REFERENCES items (id) ON DELETE CASCADE,
+    created_at      TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_message_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (user_a_id < user_b_id)
+);
+
+CREATE UNIQUE INDEX conversations_participants_idx ON conversations (user_a_id, user_b_id, COALESCE(item_id, 0));
+CREATE INDEX conversations_user_b_id_idx ON conversations (user_b_id);
+
+CREATE TABLE messages
+(
+    id              SERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations (id) ON DELETE CASCADE,
+    sender_id       INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    body            TEXT    NOT NULL,
+    created_at      TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    read_at         TIMESTAMP WITHOUT TIME ZONE
+);
+
+CREATE INDEX messages_conversation_id_idx ON messages (conversation_id);
+
+CREATE TABLE user_blocks
+(
+    blocker_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    blocked_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id)
+);
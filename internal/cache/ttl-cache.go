@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// expired entries are swept after this many writes
+const sweepEvery = 1000
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTLCache is a thread-safe in-memory cache whose entries expire after a fixed time
+type TTLCache[K comparable, V any] struct {
+	mu     sync.Mutex
+	items  map[K]entry[V]
+	ttl    time.Duration
+	writes int
+}
+
+func New[K comparable, V any](ttl time.Duration) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		items: make(map[K]entry[V]),
+		ttl:   ttl,
+	}
+}
+
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		var zero V
+
+		return zero, false
+	}
+
+	if time.Now().After(e.expiresAt) {
+		delete(c.items, key)
+		var zero V
+
+		return zero, false
+	}
+
+	return e.value, true
+}
+
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+func (c *TTLCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.items[key] = entry[V]{
+		value:     value,
+		expiresAt: now.Add(ttl),
+	}
+
+	c.writes++
+	if c.writes%sweepEvery == 0 {
+		for k, e := range c.items {
+			if now.After(e.expiresAt) {
+				delete(c.items, k)
+			}
+		}
+	}
+}
+
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
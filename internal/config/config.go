@@ -1,26 +1,123 @@
 package config
 
 import (
+	"fmt"
 	"github.com/caarlos0/env/v10"
 	"github.com/joho/godotenv"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	DevEnv           = "dev"
+	ProductionEnv    = "production"
+	defaultSecretKey = "secret"
+)
+
 type Config struct {
+	// dev allows insecure defaults, any other value is treated as production. It defaults to production,
+	// so a deployment without APP_ENV doesn't run with the default secret.
+	AppEnv string `env:"APP_ENV" envDefault:"production"`
+
 	Port string `env:"PORT" envDefault:":3000"`
 
 	LogLevel string `env:"LOG_LEVEL" envDefault:"INFO"`
 
+	// Language of error messages for clients whose Accept-Language names no supported language, en or uk
+	DefaultLanguage string `env:"DEFAULT_LANGUAGE" envDefault:"en"`
+
+	// Keeps all the data in memory instead of the database, for demos and end-to-end tests.
+	// The data is lost on restart.
+	InMemoryStorage bool `env:"IN_MEMORY_STORAGE" envDefault:"false"`
+
+	// postgres or sqlite, the SQLite database is the file at DbPath and the other Db settings are ignored
+	DbDriver string `env:"DB_DRIVER" envDefault:"postgres"`
+	DbPath   string `env:"DB_PATH" envDefault:"auction.db"`
+
+	// Applies the pending migrations on startup. Replicas take an advisory lock, so only one of them migrates.
+	AutoMigrate bool `env:"AUTO_MIGRATE" envDefault:"false"`
+
 	DbUser string `env:"DB_USER" envDefault:"postgres"`
 	DbPass string `env:"DB_PASSWORD" envDefault:"postgres"`
 	DbHost string `env:"DB_HOST" envDefault:"localhost"`
 	DbPort string `env:"DB_PORT" envDefault:"5432"`
 	DbName string `env:"DB_NAME" envDefault:"postgres"`
 
+	// Limit of the queries of one repository call, 0 disables it
+	DbQueryTimeoutSeconds int `env:"DB_QUERY_TIMEOUT_SECONDS" envDefault:"5"`
+
 	SecretKey string `env:"SECRET_KEY" envDefault:"secret"`
 
+	// HS256 signs tokens with SecretKey, RS256 and EdDSA with the private key from JwtSigningKey or JwtSigningKeyFile
+	JwtSigningAlg     string `env:"JWT_SIGNING_ALG" envDefault:"HS256"`
+	JwtSigningKey     string `env:"JWT_SIGNING_KEY" envDefault:""`
+	JwtSigningKeyFile string `env:"JWT_SIGNING_KEY_FILE" envDefault:""`
+	// Keys of previous signing keys, tokens signed with them stay valid after a rotation
+	JwtVerificationKeyFiles []string `env:"JWT_VERIFICATION_KEY_FILES" envSeparator:","`
+
+	// Argon2id parameters of new password hashes, hashes made with other parameters are replaced on login
+	Argon2MemoryKib   uint32 `env:"ARGON2_MEMORY_KIB" envDefault:"65536"`
+	Argon2Iterations  uint32 `env:"ARGON2_ITERATIONS" envDefault:"3"`
+	Argon2Parallelism uint8  `env:"ARGON2_PARALLELISM" envDefault:"2"`
+	Argon2SaltLength  uint32 `env:"ARGON2_SALT_LENGTH" envDefault:"16"`
+	Argon2KeyLength   uint32 `env:"ARGON2_KEY_LENGTH" envDefault:"32"`
+
+	// Passwords are checked against a built-in list of common passwords and, if set, PasswordCommonListFile
+	PasswordMinLength      int    `env:"PASSWORD_MIN_LENGTH" envDefault:"10"`
+	PasswordMaxLength      int    `env:"PASSWORD_MAX_LENGTH" envDefault:"128"`
+	PasswordCommonListFile string `env:"PASSWORD_COMMON_LIST_FILE" envDefault:""`
+
 	AccessTokenLifetimeMinutes  int `env:"ACCESS_TOKEN_LIFETIME_MINUTES" envDefault:"5"`
 	RefreshTokenLifetimeMinutes int `env:"REFRESH_TOKEN_LIFETIME_MINUTES" envDefault:"1440"`
+
+	// Issuer shown in authenticator apps
+	MfaIssuer string `env:"MFA_ISSUER" envDefault:"Auction"`
+	// Time to enter the one-time code after the password was accepted
+	MfaChallengeLifetimeMinutes int `env:"MFA_CHALLENGE_LIFETIME_MINUTES" envDefault:"5"`
+
+	// OpenID Connect login is enabled when OidcIssuerUrl is set.
+	// Users logging in for the first time get OidcDefaultUserTypeCode.
+	OidcIssuerUrl            string   `env:"OIDC_ISSUER_URL" envDefault:""`
+	OidcClientId             string   `env:"OIDC_CLIENT_ID" envDefault:""`
+	OidcClientSecret         string   `env:"OIDC_CLIENT_SECRET" envDefault:""`
+	OidcRedirectUrl          string   `env:"OIDC_REDIRECT_URL" envDefault:"http://localhost:3000/users/oidc/callback/"`
+	OidcScopes               []string `env:"OIDC_SCOPES" envDefault:"openid,email,profile" envSeparator:","`
+	OidcDefaultUserTypeCode  string   `env:"OIDC_DEFAULT_USER_TYPE_CODE" envDefault:"BUYER"`
+	OidcLoginLifetimeMinutes int      `env:"OIDC_LOGIN_LIFETIME_MINUTES" envDefault:"10"`
+
+	// Failed logins are counted per account and per IP within LoginFailureWindowMinutes.
+	// Each failure of an account doubles the wait before its next attempt, starting from LoginDelayBaseMilliseconds.
+	LoginMaxFailures           int `env:"LOGIN_MAX_FAILURES" envDefault:"5"`
+	LoginMaxFailuresPerIp      int `env:"LOGIN_MAX_FAILURES_PER_IP" envDefault:"50"`
+	LoginFailureWindowMinutes  int `env:"LOGIN_FAILURE_WINDOW_MINUTES" envDefault:"15"`
+	LoginLockoutMinutes        int `env:"LOGIN_LOCKOUT_MINUTES" envDefault:"15"`
+	LoginDelayBaseMilliseconds int `env:"LOGIN_DELAY_BASE_MILLISECONDS" envDefault:"500"`
+	LoginDelayMaxMilliseconds  int `env:"LOGIN_DELAY_MAX_MILLISECONDS" envDefault:"8000"`
+
+	// Largest number of items in one bulk import, larger files are rejected
+	ItemsImportMaxRows int `env:"ITEMS_IMPORT_MAX_ROWS" envDefault:"10000"`
+	// Largest import file in bytes, larger uploads are rejected before they are parsed
+	ItemsImportMaxBytes int64 `env:"ITEMS_IMPORT_MAX_BYTES" envDefault:"10485760"`
+
+	// How long the response to a request with an Idempotency-Key is replayed to its retries
+	IdempotencyWindowMinutes int `env:"IDEMPOTENCY_WINDOW_MINUTES" envDefault:"1440"`
+
+	// Days after a sale during which the buyer and the seller can review each other
+	ReviewWindowDays int `env:"REVIEW_WINDOW_DAYS" envDefault:"30"`
+
+	// How long other instances may keep accepting a token revoked on one instance
+	RevocationCacheTtlSeconds int `env:"REVOCATION_CACHE_TTL_SECONDS" envDefault:"30"`
+
+	EmailVerificationLifetimeMinutes int `env:"EMAIL_VERIFICATION_LIFETIME_MINUTES" envDefault:"1440"`
+
+	// Base URL of the API used to build links sent by email
+	AppBaseUrl string `env:"APP_BASE_URL" envDefault:"http://localhost:3000"`
+
+	// If SmtpHost is empty emails are written to the log instead of being sent
+	SmtpHost     string `env:"SMTP_HOST" envDefault:""`
+	SmtpPort     string `env:"SMTP_PORT" envDefault:"587"`
+	SmtpUser     string `env:"SMTP_USER" envDefault:""`
+	SmtpPassword string `env:"SMTP_PASSWORD" envDefault:""`
+	MailFrom     string `env:"MAIL_FROM" envDefault:"no-reply@auction.local"`
 }
 
 func NewConfig() (*Config, error) {
@@ -35,3 +132,20 @@ func NewConfig() (*Config, error) {
 
 	return cfg, nil
 }
+
+func (c *Config) IsDev() bool {
+	return c.AppEnv == DevEnv
+}
+
+// Validate reports settings that are only acceptable for local development
+func (c *Config) Validate() error {
+	if c.IsDev() {
+		return nil
+	}
+
+	if c.JwtSigningAlg == "HS256" && (c.SecretKey == defaultSecretKey || c.SecretKey == "") {
+		return fmt.Errorf("SECRET_KEY must be changed from its default value when APP_ENV is %q", c.AppEnv)
+	}
+
+	return nil
+}
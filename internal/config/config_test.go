@@ -0,0 +1,27 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestValidateDefaultSecretKey(t *testing.T) {
+	t.Setenv("SECRET_KEY", "")
+	t.Setenv("JWT_SIGNING_ALG", "HS256")
+
+	t.Run("Production by default", func(t *testing.T) {
+		t.Setenv("APP_ENV", "")
+		cfg, err := NewConfig()
+		require.NoError(t, err)
+		assert.Equal(t, ProductionEnv, cfg.AppEnv)
+		assert.Error(t, cfg.Validate())
+	})
+
+	t.Run("Allowed in dev", func(t *testing.T) {
+		t.Setenv("APP_ENV", DevEnv)
+		cfg, err := NewConfig()
+		require.NoError(t, err)
+		assert.NoError(t, cfg.Validate())
+	})
+}
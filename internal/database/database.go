@@ -1,20 +1,37 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+	"net/url"
+	"regexp"
+	"time"
 	"ypeskov/go_hillel_9/internal/config"
 	log "ypeskov/go_hillel_9/internal/log"
 )
 
-type Database = *sqlx.DB
+const (
+	PostgresDriver = "postgres"
+	SqliteDriver   = "sqlite"
+)
 
-func GetDB(cfg *config.Config, log *log.Logger) Database {
-	dbConnStr := fmt.Sprintf("user=%s password=%s host=%s port=%s dbname=%s sslmode=disable",
-		cfg.DbUser, cfg.DbPass, cfg.DbHost, cfg.DbPort, cfg.DbName)
+func init() {
+	sqlx.BindDriver(SqliteDriver, sqlx.QUESTION)
+}
+
+type Database = *DB
 
-	db, err := sqlx.Connect("postgres", dbConnStr)
+// DB is the connection pool with the query timeout of the configuration
+type DB struct {
+	*sqlx.DB
+	queryTimeout time.Duration
+}
+
+func GetDB(cfg *config.Config, log *log.Logger) Database {
+	db, err := Connect(cfg)
 	if err != nil {
 		log.Panicf("Error connecting to the database: %v", err)
 		panic(err)
@@ -22,3 +39,63 @@ func GetDB(cfg *config.Config, log *log.Logger) Database {
 
 	return db
 }
+
+// Connect opens the database chosen by DB_DRIVER
+func Connect(cfg *config.Config) (*DB, error) {
+	var db *sqlx.DB
+	var err error
+	switch cfg.DbDriver {
+	case PostgresDriver:
+		db, err = sqlx.Connect(PostgresDriver, fmt.Sprintf(
+			"user=%s password=%s host=%s port=%s dbname=%s sslmode=disable",
+			cfg.DbUser, cfg.DbPass, cfg.DbHost, cfg.DbPort, cfg.DbName))
+	case SqliteDriver:
+		db, err = sqlx.Connect(SqliteDriver, sqliteDsn(cfg.DbPath))
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q, use %s or %s", cfg.DbDriver, PostgresDriver,
+			SqliteDriver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &DB{
+		DB:           db,
+		queryTimeout: time.Duration(cfg.DbQueryTimeoutSeconds) * time.Second,
+	}, nil
+}
+
+// sqliteDsn enables foreign keys, which SQLite ignores by default, and lets writers wait for each other
+// instead of failing with SQLITE_BUSY. Times are written in a format that sorts like the time itself.
+func sqliteDsn(path string) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+	params.Set("_time_format", "sqlite")
+
+	return "file:" + path + "?" + params.Encode()
+}
+
+// WithTimeout limits the queries of one repository call. The context of the request still cancels them
+// earlier, e.g. when the client disconnects. A zero timeout leaves only the request context.
+func (db *DB) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, db.queryTimeout)
+}
+
+// placeholders are written the Postgres way, SQLite understands the same numbers after a question mark
+var placeholders = regexp.MustCompile(`\$(\d+)`)
+
+// rebind adapts the placeholders of a query to the driver
+func (db *DB) rebind(query string) string {
+	if db.DriverName() == SqliteDriver {
+		return placeholders.ReplaceAllString(query, "?$1")
+	}
+
+	return query
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+)
+
+func TestRebind(t *testing.T) {
+	query := "SELECT * FROM items WHERE user_id = $1 AND title ILIKE $12"
+
+	postgres := &DB{DB: sqlx.NewDb(nil, PostgresDriver)}
+	assert.Equal(t, query, postgres.rebind(query))
+
+	sqlite := &DB{DB: sqlx.NewDb(nil, SqliteDriver)}
+	assert.Equal(t, "SELECT * FROM items WHERE user_id = ?1 AND title ILIKE ?12", sqlite.rebind(query))
+}
+
+func TestConnectUnsupportedDriver(t *testing.T) {
+	_, err := Connect(&config.Config{DbDriver: "mysql"})
+	assert.Error(t, err)
+}
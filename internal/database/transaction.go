@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+)
+
+type txKey struct{}
+
+// transaction is kept in the context of the calls made inside InTransaction
+type transaction struct {
+	tx         *sqlx.Tx
+	savepoints int
+}
+
+// executor runs queries, it is the pool or the transaction of the context
+type executor interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// InTransaction runs fn in a transaction, it is committed if fn returns nil and rolled back otherwise or on panic.
+// Queries made with the context passed to fn join the transaction. A call nested in another transaction runs
+// in a savepoint, so its failure undoes only its own changes and the outer fn decides what to do with the error.
+// fn must not use the transaction from several goroutines.
+func (db *DB) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if current, ok := ctx.Value(txKey{}).(*transaction); ok {
+		return current.inSavepoint(ctx, fn)
+	}
+
+	tx, err := db.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, &transaction{tx: tx}))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	return nil
+}
+
+func (t *transaction) inSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.savepoints++
+	savepoint := fmt.Sprintf("sp_%d", t.savepoints)
+
+	_, err := t.tx.ExecContext(ctx, "SAVEPOINT "+savepoint)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	// the rollback must run even if ctx is cancelled, otherwise the outer transaction keeps the changes
+	released := false
+	defer func() {
+		if !released {
+			_, _ = t.tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+savepoint)
+		}
+	}()
+
+	err = fn(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+	if err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	released = true
+
+	return nil
+}
+
+func (db *DB) executor(ctx context.Context) executor {
+	if current, ok := ctx.Value(txKey{}).(*transaction); ok {
+		return current.tx
+	}
+
+	return db.DB
+}
+
+// The query methods below shadow the ones of sqlx.DB, so repositories join the transaction of the context
+// without knowing about it. Named queries get the placeholders of the driver from sqlx.
+
+func (db *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return db.executor(ctx).GetContext(ctx, dest, db.rebind(query), args...)
+}
+
+func (db *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return db.executor(ctx).SelectContext(ctx, dest, db.rebind(query), args...)
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.executor(ctx).ExecContext(ctx, db.rebind(query), args...)
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.executor(ctx).QueryContext(ctx, db.rebind(query), args...)
+}
+
+func (db *DB) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return db.executor(ctx).QueryxContext(ctx, db.rebind(query), args...)
+}
+
+func (db *DB) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	return db.executor(ctx).QueryRowxContext(ctx, db.rebind(query), args...)
+}
+
+func (db *DB) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return sqlx.NamedExecContext(ctx, db.executor(ctx), query, arg)
+}
+
+func (db *DB) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sqlx.Rows, error) {
+	return sqlx.NamedQueryContext(ctx, db.executor(ctx), query, arg)
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var errFailed = errors.New("failed")
+
+func newMockDB(t *testing.T) (*DB, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return &DB{DB: sqlx.NewDb(conn, "sqlmock")}, mock
+}
+
+func TestInTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Commits when fn succeeds", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO user_moderation_events").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := db.InTransaction(ctx, func(ctx context.Context) error {
+			_, err := db.ExecContext(ctx, "UPDATE users SET status = 'banned'")
+			if err != nil {
+				return err
+			}
+			_, err = db.ExecContext(ctx, "INSERT INTO user_moderation_events (action) VALUES ('ban')")
+
+			return err
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls back when fn fails", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		err := db.InTransaction(ctx, func(ctx context.Context) error {
+			_, err := db.ExecContext(ctx, "UPDATE users SET status = 'banned'")
+			if err != nil {
+				return err
+			}
+
+			return errFailed
+		})
+		assert.ErrorIs(t, err, errFailed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls back on panic", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		assert.Panics(t, func() {
+			_ = db.InTransaction(ctx, func(ctx context.Context) error {
+				panic("boom")
+			})
+		})
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Queries outside fn don't join the transaction", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+		mock.ExpectExec("DELETE FROM sessions").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := db.InTransaction(ctx, func(ctx context.Context) error {
+			return nil
+		})
+		assert.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, "DELETE FROM sessions")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNestedInTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Nested call runs in a savepoint", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO messages").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := db.InTransaction(ctx, func(ctx context.Context) error {
+			return db.InTransaction(ctx, func(ctx context.Context) error {
+				_, err := db.ExecContext(ctx, "INSERT INTO messages (body) VALUES ('Hi')")
+
+				return err
+			})
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed nested call undoes only its own changes", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO conversations").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := db.InTransaction(ctx, func(ctx context.Context) error {
+			_, err := db.ExecContext(ctx, "INSERT INTO conversations (item_id) VALUES (1)")
+			if err != nil {
+				return err
+			}
+
+			err = db.InTransaction(ctx, func(ctx context.Context) error {
+				return errFailed
+			})
+			assert.ErrorIs(t, err, errFailed)
+
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Savepoint is rolled back when the context of the nested call is cancelled", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := db.InTransaction(ctx, func(ctx context.Context) error {
+			nestedCtx, cancel := context.WithCancel(ctx)
+			err := db.InTransaction(nestedCtx, func(ctx context.Context) error {
+				cancel()
+
+				return ctx.Err()
+			})
+			assert.ErrorIs(t, err, context.Canceled)
+
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed nested call fails the outer transaction when returned", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := db.InTransaction(ctx, func(ctx context.Context) error {
+			return db.InTransaction(ctx, func(ctx context.Context) error {
+				return errFailed
+			})
+		})
+		assert.ErrorIs(t, err, errFailed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
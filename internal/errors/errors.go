@@ -1,10 +1,18 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+)
 
+// Error is an error returned to API clients. Status is the HTTP status of the response, 500 if it is not set.
 type Error struct {
 	Code    string
 	Message string
+	Status  int `json:"-"`
+
+	// set by WithMessage, the message has details a translation of the code would lose
+	detailed bool
 }
 
 func NewError(code, message string) *Error {
@@ -18,42 +26,463 @@ func (e Error) Error() string {
 	return fmt.Sprintf("Code: %s. Message: %s", e.Code, e.Message)
 }
 
+// HTTPStatus returns the status of the response for the error
+func (e Error) HTTPStatus() int {
+	if e.Status == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return e.Status
+}
+
+// WithMessage returns a copy of the error with another message, e.g. to explain which rule failed
+func (e Error) WithMessage(message string) Error {
+	e.Message = message
+	e.detailed = true
+
+	return e
+}
+
+// Detailed tells whether the message was replaced by WithMessage, such messages aren't translated
+func (e Error) Detailed() bool {
+	return e.detailed
+}
+
+// WithStatus returns a copy of the error responded with another HTTP status
+func (e Error) WithStatus(status int) Error {
+	e.Status = status
+
+	return e
+}
+
 var IncorrectReqBodyErr = Error{
 	Code:    "INCORRECT_REQUEST_BODY",
 	Message: "Failed to parse request body",
+	Status:  http.StatusBadRequest,
 }
 
 var ValidationFailedErr = Error{
 	Code:    "VALIDATION_FAILED",
 	Message: "Failed to validate parameters",
+	Status:  http.StatusBadRequest,
 }
 
 var InvalidParamterErr = Error{
 	Code:    "INVALID_PARAMETER",
 	Message: "Invalid parameter",
+	Status:  http.StatusBadRequest,
 }
 
 var NotFoundErr = Error{
 	Code:    "NOT_FOUND",
 	Message: "Not found",
+	Status:  http.StatusNotFound,
 }
 
 var UnauthorizedErr = Error{
 	Code:    "UNAUTHORIZED",
 	Message: "Unauthorized",
+	Status:  http.StatusUnauthorized,
 }
 
 var InternalServerErr = Error{
 	Code:    "INTERNAL_SERVER_ERROR",
 	Message: "Internal server error",
+	Status:  http.StatusInternalServerError,
 }
 
 var BadRequestErr = Error{
 	Code:    "BAD_REQUEST",
 	Message: "Bad request",
+	Status:  http.StatusBadRequest,
 }
 
 var TokenExpiredErr = Error{
 	Code:    "TOKEN_EXPIRED",
 	Message: "Token expired",
+	Status:  http.StatusUnauthorized,
+}
+
+var EmailNotVerifiedErr = Error{
+	Code:    "EMAIL_NOT_VERIFIED",
+	Message: "Email address is not verified",
+	Status:  http.StatusForbidden,
+}
+
+var InvalidVerificationTokenErr = Error{
+	Code:    "INVALID_VERIFICATION_TOKEN",
+	Message: "Verification token is invalid or expired",
+	Status:  http.StatusBadRequest,
+}
+
+var TokenRevokedErr = Error{
+	Code:    "TOKEN_REVOKED",
+	Message: "Token has been revoked",
+	Status:  http.StatusUnauthorized,
+}
+
+var InvalidTokenTypeErr = Error{
+	Code:    "INVALID_TOKEN_TYPE",
+	Message: "Token can't be used for this request",
+	Status:  http.StatusUnauthorized,
+}
+
+var TooManyLoginAttemptsErr = Error{
+	Code:    "TOO_MANY_LOGIN_ATTEMPTS",
+	Message: "Too many failed login attempts, try again later",
+	Status:  http.StatusTooManyRequests,
+}
+
+var UserTypeNotSelfAssignableErr = Error{
+	Code:    "USER_TYPE_NOT_SELF_ASSIGNABLE",
+	Message: "This user type can't be chosen at sign-up",
+	Status:  http.StatusBadRequest,
+}
+
+var ForbiddenErr = Error{
+	Code:    "FORBIDDEN",
+	Message: "Forbidden",
+	Status:  http.StatusForbidden,
+}
+
+var InvalidMfaCodeErr = Error{
+	Code:    "INVALID_MFA_CODE",
+	Message: "Two-factor authentication code is invalid",
+	Status:  http.StatusUnauthorized,
+}
+
+var MfaAlreadyEnabledErr = Error{
+	Code:    "MFA_ALREADY_ENABLED",
+	Message: "Two-factor authentication is already enabled",
+	Status:  http.StatusConflict,
+}
+
+var MfaNotEnabledErr = Error{
+	Code:    "MFA_NOT_ENABLED",
+	Message: "Two-factor authentication is not enabled",
+	Status:  http.StatusBadRequest,
+}
+
+var MfaEnrollmentRequiredErr = Error{
+	Code:    "MFA_ENROLLMENT_REQUIRED",
+	Message: "Two-factor authentication must be enabled for this account",
+	Status:  http.StatusForbidden,
+}
+
+var InvalidApiKeyErr = Error{
+	Code:    "INVALID_API_KEY",
+	Message: "API key is invalid, expired or revoked",
+	Status:  http.StatusUnauthorized,
+}
+
+var InsufficientScopeErr = Error{
+	Code:    "INSUFFICIENT_SCOPE",
+	Message: "API key doesn't have the scope required for this request",
+	Status:  http.StatusForbidden,
+}
+
+var InvalidScopeErr = Error{
+	Code:    "INVALID_SCOPE",
+	Message: "Unknown API key scope",
+	Status:  http.StatusBadRequest,
+}
+
+var InvalidApiKeyExpiryErr = Error{
+	Code:    "INVALID_API_KEY_EXPIRY",
+	Message: "API key expiry must be in the future",
+	Status:  http.StatusBadRequest,
+}
+
+var OidcDisabledErr = Error{
+	Code:    "OIDC_DISABLED",
+	Message: "Single sign-on is not configured",
+	Status:  http.StatusNotFound,
+}
+
+var InvalidOidcStateErr = Error{
+	Code:    "INVALID_OIDC_STATE",
+	Message: "Single sign-on login is unknown or expired, start it again",
+	Status:  http.StatusBadRequest,
+}
+
+var OidcLoginFailedErr = Error{
+	Code:    "OIDC_LOGIN_FAILED",
+	Message: "Identity provider didn't confirm the login",
+	Status:  http.StatusUnauthorized,
+}
+
+var OidcEmailNotVerifiedErr = Error{
+	Code:    "OIDC_EMAIL_NOT_VERIFIED",
+	Message: "Identity provider didn't confirm the email address",
+	Status:  http.StatusUnauthorized,
+}
+
+var OidcAccountConflictErr = Error{
+	Code:    "OIDC_ACCOUNT_CONFLICT",
+	Message: "An account with this email exists but its email is not verified. Verify it before using single sign-on",
+	Status:  http.StatusConflict,
+}
+
+var WeakPasswordErr = Error{
+	Code:    "WEAK_PASSWORD",
+	Message: "Password doesn't meet the password policy",
+	Status:  http.StatusBadRequest,
+}
+
+var AccountSuspendedErr = Error{
+	Code:    "ACCOUNT_SUSPENDED",
+	Message: "Account is suspended",
+	Status:  http.StatusForbidden,
+}
+
+var AccountBannedErr = Error{
+	Code:    "ACCOUNT_BANNED",
+	Message: "Account is banned",
+	Status:  http.StatusForbidden,
+}
+
+var CannotModerateSelfErr = Error{
+	Code:    "CANNOT_MODERATE_SELF",
+	Message: "Administrators can't change their own status or role",
+	Status:  http.StatusBadRequest,
+}
+
+var InvalidSuspensionErr = Error{
+	Code:    "INVALID_SUSPENSION",
+	Message: "Suspension must end in the future",
+	Status:  http.StatusBadRequest,
+}
+
+var UserTypeExistsErr = Error{
+	Code:    "USER_TYPE_EXISTS",
+	Message: "User type with this code already exists",
+	Status:  http.StatusConflict,
+}
+
+var InvalidUserTypeCodeErr = Error{
+	Code:    "INVALID_USER_TYPE_CODE",
+	Message: "User type code must start with a letter and contain only letters, digits and underscores",
+	Status:  http.StatusBadRequest,
+}
+
+var UserTypeBuiltInErr = Error{
+	Code:    "USER_TYPE_BUILT_IN",
+	Message: "Built-in user types can't be retired",
+	Status:  http.StatusBadRequest,
+}
+
+var UserTypeRetiredErr = Error{
+	Code:    "USER_TYPE_RETIRED",
+	Message: "User type is retired",
+	Status:  http.StatusBadRequest,
+}
+
+var InvalidReplacementUserTypeErr = Error{
+	Code:    "INVALID_REPLACEMENT_USER_TYPE",
+	Message: "Replacement user type must be another active user type",
+	Status:  http.StatusBadRequest,
+}
+
+var InvalidUserTypeErr = Error{
+	Code:    "INVALID_USER_TYPE",
+	Message: "userTypeId must refer to an existing active user type",
+	Status:  http.StatusBadRequest,
+}
+
+var AdminTypeSelfAssignableErr = Error{
+	Code:    "ADMIN_TYPE_SELF_ASSIGNABLE",
+	Message: "The administrator type can't be opened to sign-up",
+	Status:  http.StatusBadRequest,
+}
+
+var NotSalePartyErr = Error{
+	Code:    "NOT_SALE_PARTY",
+	Message: "Only the buyer and the seller of the item can review the sale",
+	Status:  http.StatusForbidden,
+}
+
+var ReviewWindowClosedErr = Error{
+	Code:    "REVIEW_WINDOW_CLOSED",
+	Message: "The time to review this sale is over",
+	Status:  http.StatusBadRequest,
+}
+
+var ReviewExistsErr = Error{
+	Code:    "REVIEW_EXISTS",
+	Message: "You have already reviewed this sale",
+	Status:  http.StatusConflict,
+}
+
+var NotRevieweeErr = Error{
+	Code:    "NOT_REVIEWEE",
+	Message: "Only the reviewed user can respond to or dispute the review",
+	Status:  http.StatusForbidden,
+}
+
+var ReviewAlreadyAnsweredErr = Error{
+	Code:    "REVIEW_ALREADY_ANSWERED",
+	Message: "The review already has a response",
+	Status:  http.StatusConflict,
+}
+
+var ReviewDisputeClosedErr = Error{
+	Code:    "REVIEW_DISPUTE_CLOSED",
+	Message: "The review has already been disputed",
+	Status:  http.StatusConflict,
+}
+
+var ReviewNotDisputedErr = Error{
+	Code:    "REVIEW_NOT_DISPUTED",
+	Message: "The review is not disputed",
+	Status:  http.StatusConflict,
+}
+
+var NoPendingSaleErr = Error{
+	Code:    "NO_PENDING_SALE",
+	Message: "The item has no sale waiting for your confirmation",
+	Status:  http.StatusNotFound,
+}
+
+var CannotMessageSelfErr = Error{
+	Code:    "CANNOT_MESSAGE_SELF",
+	Message: "You can't start a conversation with yourself",
+	Status:  http.StatusBadRequest,
+}
+
+var InvalidRecipientErr = Error{
+	Code:    "INVALID_RECIPIENT",
+	Message: "Recipient doesn't exist",
+	Status:  http.StatusBadRequest,
+}
+
+var InvalidConversationItemErr = Error{
+	Code:    "INVALID_CONVERSATION_ITEM",
+	Message: "Conversation can only be about an item of one of its participants",
+	Status:  http.StatusBadRequest,
+}
+
+var BlockedByUserErr = Error{
+	Code:    "BLOCKED_BY_USER",
+	Message: "This user doesn't accept your messages",
+	Status:  http.StatusForbidden,
+}
+
+var CannotBlockSelfErr = Error{
+	Code:    "CANNOT_BLOCK_SELF",
+	Message: "You can't block yourself",
+	Status:  http.StatusBadRequest,
+}
+
+var CannotAskOwnItemErr = Error{
+	Code:    "CANNOT_ASK_OWN_ITEM",
+	Message: "You can't ask questions about your own item",
+	Status:  http.StatusBadRequest,
+}
+
+var NotItemOwnerErr = Error{
+	Code:    "NOT_ITEM_OWNER",
+	Message: "Only the seller of the item can answer questions",
+	Status:  http.StatusForbidden,
+}
+
+var QuestionAlreadyAnsweredErr = Error{
+	Code:    "QUESTION_ALREADY_ANSWERED",
+	Message: "The question is already answered",
+	Status:  http.StatusConflict,
+}
+
+var InvalidIdErr = Error{
+	Code:    "INVALID_ID",
+	Message: "Invalid ID",
+	Status:  http.StatusBadRequest,
+}
+
+var UserNotFoundErr = Error{
+	Code:    "USER_NOT_FOUND",
+	Message: "User not found",
+	Status:  http.StatusNotFound,
+}
+
+var UserTypeNotFoundErr = Error{
+	Code:    "USER_TYPE_NOT_FOUND",
+	Message: "User type not found",
+	Status:  http.StatusNotFound,
+}
+
+var ItemNotFoundErr = Error{
+	Code:    "ITEM_NOT_FOUND",
+	Message: "Item not found",
+	Status:  http.StatusNotFound,
+}
+
+var SessionNotFoundErr = Error{
+	Code:    "SESSION_NOT_FOUND",
+	Message: "Session not found",
+	Status:  http.StatusNotFound,
+}
+
+var ApiKeyNotFoundErr = Error{
+	Code:    "API_KEY_NOT_FOUND",
+	Message: "API key not found",
+	Status:  http.StatusNotFound,
+}
+
+var IncorrectUserRoleErr = Error{
+	Code:    "INCORRECT_USER_ROLE",
+	Message: "User is not a seller",
+	Status:  http.StatusForbidden,
+}
+
+var ItemAlreadySoldErr = Error{
+	Code:    "ITEM_ALREADY_SOLD",
+	Message: "Item is already sold",
+	Status:  http.StatusConflict,
+}
+
+var InvalidBuyerErr = Error{
+	Code:    "INVALID_BUYER",
+	Message: "Buyer must be another existing user",
+	Status:  http.StatusBadRequest,
+}
+
+var UnsupportedFormatErr = Error{
+	Code:    "UNSUPPORTED_FORMAT",
+	Message: "Unsupported format, use csv or ndjson",
+	Status:  http.StatusUnsupportedMediaType,
+}
+
+var InvalidImportFileErr = Error{
+	Code:    "INVALID_IMPORT_FILE",
+	Message: "The file can't be imported",
+	Status:  http.StatusBadRequest,
+}
+
+var InvalidImportRowErr = Error{
+	Code:    "INVALID_IMPORT_ROW",
+	Message: "The row can't be parsed",
+	Status:  http.StatusBadRequest,
+}
+
+var ImportTooLargeErr = Error{
+	Code:    "IMPORT_TOO_LARGE",
+	Message: "The file has too many rows",
+	Status:  http.StatusRequestEntityTooLarge,
+}
+
+var InvalidIdempotencyKeyErr = Error{
+	Code:    "INVALID_IDEMPOTENCY_KEY",
+	Message: "Idempotency-Key must be at most 255 characters",
+	Status:  http.StatusBadRequest,
+}
+
+var IdempotencyKeyInProgressErr = Error{
+	Code:    "IDEMPOTENCY_KEY_IN_PROGRESS",
+	Message: "A request with this Idempotency-Key is still in progress",
+	Status:  http.StatusConflict,
+}
+
+var IdempotencyKeyReusedErr = Error{
+	Code:    "IDEMPOTENCY_KEY_REUSED",
+	Message: "The Idempotency-Key was already used for a different request",
+	Status:  http.StatusUnprocessableEntity,
 }
@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FieldError describes a field of the request that failed validation. Field is the JSON name of the field,
+// Rule is the failed validation tag (required, email, min...) and Param is its parameter, e.g. 8 for min=8.
+// Message is filled in the language of the client when the error is responded.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+// ValidationError is returned when the request is parsed but some of its fields are invalid.
+// It matches ValidationFailedErr with errors.Is.
+type ValidationError struct {
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+func NewValidationError(fields []FieldError) *ValidationError {
+	return &ValidationError{
+		Code:    ValidationFailedErr.Code,
+		Message: ValidationFailedErr.Message,
+		Fields:  fields,
+	}
+}
+
+func (e *ValidationError) Error() string {
+	failed := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		failed = append(failed, fmt.Sprintf("%s (%s)", field.Field, field.Rule))
+	}
+
+	return fmt.Sprintf("Code: %s. Message: %s: %s", e.Code, e.Message, strings.Join(failed, ", "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == error(ValidationFailedErr)
+}
+
+func (e *ValidationError) HTTPStatus() int {
+	return http.StatusBadRequest
+}
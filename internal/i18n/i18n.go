@@ -0,0 +1,141 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"ypeskov/go_hillel_9/internal/config"
+)
+
+// SourceLanguage is the language of the messages in the errors package, they are used
+// when the catalogue of the requested language has no translation
+const SourceLanguage = "en"
+
+const defaultRule = "_default"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+type locale struct {
+	// error code -> message
+	Errors map[string]string `json:"errors"`
+	// validation rule -> message with {field} and {param} placeholders
+	Validation map[string]string `json:"validation"`
+}
+
+// Catalogue holds the messages of every supported language, one locales/<language>.json file per language
+type Catalogue struct {
+	locales  map[string]locale
+	fallback string
+}
+
+func New(cfg *config.Config) (*Catalogue, error) {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalogue{locales: make(map[string]locale, len(files))}
+	for _, file := range files {
+		data, err := locales.ReadFile("locales/" + file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		var l locale
+		if err := json.Unmarshal(data, &l); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+		}
+		c.locales[strings.TrimSuffix(file.Name(), ".json")] = l
+	}
+
+	fallback := strings.ToLower(cfg.DefaultLanguage)
+	if _, ok := c.locales[fallback]; !ok {
+		return nil, fmt.Errorf("unsupported DEFAULT_LANGUAGE %q, supported languages: %s",
+			cfg.DefaultLanguage, strings.Join(c.Languages(), ", "))
+	}
+	c.fallback = fallback
+
+	return c, nil
+}
+
+// Languages returns the supported languages in alphabetical order
+func (c *Catalogue) Languages() []string {
+	languages := make([]string, 0, len(c.locales))
+	for language := range c.locales {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+// Negotiate picks the supported language the client prefers most by the Accept-Language header,
+// e.g. "uk-UA,uk;q=0.9,en;q=0.8". Regions are ignored. Without a match it returns the fallback language.
+func (c *Catalogue) Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		language string
+		quality  float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if tag == "" || quality <= 0 {
+			continue
+		}
+
+		language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		candidates = append(candidates, candidate{language: language, quality: quality})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, candidate := range candidates {
+		if _, ok := c.locales[candidate.language]; ok {
+			return candidate.language
+		}
+		if candidate.language == "*" {
+			break
+		}
+	}
+
+	return c.fallback
+}
+
+// ErrorMessage returns the message of the error code in the language, or message if there is no translation
+func (c *Catalogue) ErrorMessage(language, code, message string) string {
+	if translated, ok := c.locales[language].Errors[code]; ok {
+		return translated
+	}
+
+	return message
+}
+
+// FieldMessage describes a failed validation rule of a field in the language
+func (c *Catalogue) FieldMessage(language, field, rule, param string) string {
+	validation := c.locales[language].Validation
+	if validation == nil {
+		validation = c.locales[SourceLanguage].Validation
+	}
+
+	template, ok := validation[rule]
+	if !ok {
+		template = validation[defaultRule]
+	}
+
+	return strings.NewReplacer("{field}", field, "{param}", param).Replace(template)
+}
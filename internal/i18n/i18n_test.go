@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+)
+
+func newCatalogue(t *testing.T, defaultLanguage string) *Catalogue {
+	catalogue, err := New(&config.Config{DefaultLanguage: defaultLanguage})
+	require.NoError(t, err)
+
+	return catalogue
+}
+
+func TestNew(t *testing.T) {
+	catalogue := newCatalogue(t, "UK")
+	assert.Equal(t, []string{"en", "uk"}, catalogue.Languages())
+	assert.Equal(t, "uk", catalogue.Negotiate(""))
+
+	_, err := New(&config.Config{DefaultLanguage: "de"})
+	assert.Error(t, err)
+}
+
+func TestNegotiate(t *testing.T) {
+	catalogue := newCatalogue(t, "en")
+
+	tests := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"", "en"},
+		{"uk", "uk"},
+		{"uk-UA,uk;q=0.9,en-US;q=0.8,en;q=0.7", "uk"},
+		{"en-US,en;q=0.9,uk;q=0.8", "en"},
+		{"de-DE,de;q=0.9,uk;q=0.5", "uk"},
+		{"en;q=0.3, uk;q=0.7", "uk"},
+		{"uk;q=0,en", "en"},
+		{"de,fr", "en"},
+		{"*", "en"},
+		{"uk;q=abc", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tt.expected, catalogue.Negotiate(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	catalogue := newCatalogue(t, "en")
+
+	assert.Equal(t, "Товар не знайдено", catalogue.ErrorMessage("uk", "ITEM_NOT_FOUND", "Item not found"))
+	assert.Equal(t, "Item not found", catalogue.ErrorMessage("en", "ITEM_NOT_FOUND", "Item not found"))
+	assert.Equal(t, "Unknown", catalogue.ErrorMessage("uk", "UNKNOWN_CODE", "Unknown"))
+}
+
+func TestFieldMessage(t *testing.T) {
+	catalogue := newCatalogue(t, "en")
+
+	assert.Equal(t, "password must be at least 8", catalogue.FieldMessage("en", "password", "min", "8"))
+	assert.Equal(t, "Поле email обов'язкове", catalogue.FieldMessage("uk", "email", "required", ""))
+	assert.Equal(t, "code is invalid", catalogue.FieldMessage("en", "code", "hexcolor", ""))
+}
+
+// every language has to describe the same validation rules
+func TestValidationRulesTranslated(t *testing.T) {
+	catalogue := newCatalogue(t, "en")
+
+	source := catalogue.locales[SourceLanguage].Validation
+	for _, language := range catalogue.Languages() {
+		rules := catalogue.locales[language].Validation
+		assert.Len(t, rules, len(source), language)
+		for rule := range source {
+			assert.Contains(t, rules, rule, language)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"ypeskov/go_hillel_9/internal/config"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+type verificationKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// KeySet signs tokens with one key and verifies them with any of the active keys.
+// Every asymmetric key is identified by a kid derived from its public key.
+type KeySet struct {
+	alg              string
+	signingKid       string
+	signingKey       any
+	verificationKeys map[string]verificationKey
+	// kids in the order they were loaded, the signing key comes first
+	kids []string
+}
+
+func Load(cfg *config.Config) (*KeySet, error) {
+	switch cfg.JwtSigningAlg {
+	case AlgHS256:
+		return &KeySet{
+			alg:        AlgHS256,
+			signingKey: []byte(cfg.SecretKey),
+		}, nil
+	case AlgRS256, AlgEdDSA:
+		return loadAsymmetric(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported JWT_SIGNING_ALG %q", cfg.JwtSigningAlg)
+	}
+}
+
+func loadAsymmetric(cfg *config.Config) (*KeySet, error) {
+	pemData := []byte(cfg.JwtSigningKey)
+	if cfg.JwtSigningKeyFile != "" {
+		var err error
+		pemData, err = os.ReadFile(cfg.JwtSigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+	}
+	if len(pemData) == 0 {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY or JWT_SIGNING_KEY_FILE is required for %s", cfg.JwtSigningAlg)
+	}
+
+	signingKey, publicKey, err := parsePrivateKey(cfg.JwtSigningAlg, pemData)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{
+		alg:              cfg.JwtSigningAlg,
+		signingKey:       signingKey,
+		verificationKeys: make(map[string]verificationKey),
+	}
+	ks.signingKid, err = ks.addVerificationKey(cfg.JwtSigningAlg, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range cfg.JwtVerificationKeyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read verification key %s: %w", file, err)
+		}
+
+		alg, key, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse verification key %s: %w", file, err)
+		}
+
+		if _, err := ks.addVerificationKey(alg, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return ks, nil
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(ks.alg), claims)
+	if ks.signingKid != "" {
+		token.Header["kid"] = ks.signingKid
+	}
+
+	return token.SignedString(ks.signingKey)
+}
+
+// Keyfunc picks the verification key by the kid header of the token
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	if ks.alg == AlgHS256 {
+		return ks.signingKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.verificationKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+
+	if token.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("kid %q is not a %s key", kid, token.Method.Alg())
+	}
+
+	return key.key, nil
+}
+
+func (ks *KeySet) ValidMethods() []string {
+	if ks.alg == AlgHS256 {
+		return []string{AlgHS256}
+	}
+
+	return []string{AlgRS256, AlgEdDSA}
+}
+
+func (ks *KeySet) ParseWithClaims(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, ks.Keyfunc, jwt.WithValidMethods(ks.ValidMethods()))
+}
+
+func (ks *KeySet) addVerificationKey(alg string, key crypto.PublicKey) (string, error) {
+	kid, err := keyId(key)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := ks.verificationKeys[kid]; !ok {
+		ks.verificationKeys[kid] = verificationKey{alg: alg, key: key}
+		ks.kids = append(ks.kids, kid)
+	}
+
+	return kid, nil
+}
+
+func parsePrivateKey(alg string, data []byte) (any, crypto.PublicKey, error) {
+	switch alg {
+	case AlgRS256:
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse RSA signing key: %w", err)
+		}
+
+		return key, &key.PublicKey, nil
+	case AlgEdDSA:
+		key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse Ed25519 signing key: %w", err)
+		}
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("signing key is not an Ed25519 key")
+		}
+
+		return edKey, edKey.Public(), nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
+
+// parsePublicKey accepts public keys as well as private keys of previous signing keys
+func parsePublicKey(data []byte) (string, crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return AlgRS256, key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return AlgEdDSA, key, nil
+	}
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return AlgRS256, &key.PublicKey, nil
+	}
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		if edKey, ok := key.(ed25519.PrivateKey); ok {
+			return AlgEdDSA, edKey.Public(), nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("key is neither an RSA nor an Ed25519 PEM key")
+}
+
+// keyId is a stable identifier of a public key so the same key always gets the same kid
+func keyId(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])[:16], nil
+}
@@ -0,0 +1,108 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+)
+
+func writeRSAKey(t *testing.T, dir string, name string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func writeEdKey(t *testing.T, dir string, name string) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func testClaims() jwt.Claims {
+	return jwt.RegisteredClaims{
+		Subject:   "1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := writeRSAKey(t, dir, "old.pem")
+	newKey := writeEdKey(t, dir, "new.pem")
+
+	oldSet, err := Load(&config.Config{JwtSigningAlg: AlgRS256, JwtSigningKeyFile: oldKey})
+	require.NoError(t, err)
+	oldToken, err := oldSet.Sign(testClaims())
+	require.NoError(t, err)
+
+	rotatedSet, err := Load(&config.Config{
+		JwtSigningAlg:           AlgEdDSA,
+		JwtSigningKeyFile:       newKey,
+		JwtVerificationKeyFiles: []string{oldKey},
+	})
+	require.NoError(t, err)
+	newToken, err := rotatedSet.Sign(testClaims())
+	require.NoError(t, err)
+
+	_, err = rotatedSet.ParseWithClaims(oldToken, &jwt.RegisteredClaims{})
+	assert.NoError(t, err, "tokens signed before the rotation must stay valid")
+
+	_, err = rotatedSet.ParseWithClaims(newToken, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+
+	_, err = oldSet.ParseWithClaims(newToken, &jwt.RegisteredClaims{})
+	assert.Error(t, err, "the old key set doesn't know the new kid")
+
+	jwks := rotatedSet.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+}
+
+func TestRejectsSymmetricTokensWhenAsymmetric(t *testing.T) {
+	keyFile := writeRSAKey(t, t.TempDir(), "key.pem")
+
+	ks, err := Load(&config.Config{JwtSigningAlg: AlgRS256, JwtSigningKeyFile: keyFile})
+	require.NoError(t, err)
+
+	hsToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = ks.ParseWithClaims(hsToken, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+}
+
+func TestHS256HasNoPublicKeys(t *testing.T) {
+	ks, err := Load(&config.Config{JwtSigningAlg: AlgHS256, SecretKey: "secret"})
+	require.NoError(t, err)
+
+	token, err := ks.Sign(testClaims())
+	require.NoError(t, err)
+
+	_, err = ks.ParseWithClaims(token, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+	assert.Empty(t, ks.JWKS().Keys)
+}
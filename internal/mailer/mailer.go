@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/log"
+)
+
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+type SmtpMailer struct {
+	cfg *config.Config
+	log *log.Logger
+}
+
+// LogMailer writes emails to the log. It is used when no SMTP server is configured.
+type LogMailer struct {
+	log *log.Logger
+}
+
+func New(cfg *config.Config, log *log.Logger) Mailer {
+	if cfg.SmtpHost == "" {
+		return &LogMailer{log: log}
+	}
+
+	return &SmtpMailer{
+		cfg: cfg,
+		log: log,
+	}
+}
+
+func (m *SmtpMailer) Send(to string, subject string, body string) error {
+	addr := net.JoinHostPort(m.cfg.SmtpHost, m.cfg.SmtpPort)
+
+	var auth smtp.Auth
+	if m.cfg.SmtpUser != "" {
+		auth = smtp.PlainAuth("", m.cfg.SmtpUser, m.cfg.SmtpPassword, m.cfg.SmtpHost)
+	}
+
+	msg := buildMessage(m.cfg.MailFrom, to, subject, body)
+	err := smtp.SendMail(addr, auth, m.cfg.MailFrom, []string{to}, []byte(msg))
+	if err != nil {
+		m.log.Errorln("failed to send email", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (m *LogMailer) Send(to string, subject string, body string) error {
+	m.log.Infof("Email to: %s, subject: %s\n%s", to, subject, body)
+
+	return nil
+}
+
+func buildMessage(from, to, subject, body string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	sb.WriteString(fmt.Sprintf("To: %s\r\n", to))
+	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(body)
+
+	return sb.String()
+}
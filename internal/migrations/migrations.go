@@ -0,0 +1,318 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"ypeskov/go_hillel_9/db"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/log"
+)
+
+// lockId is the Postgres advisory lock held while migrating, so replicas starting together migrate one by one
+const lockId = 7201900046
+
+// Dirs maps a database driver to its migrations directory
+var Dirs = map[string]string{
+	database.PostgresDriver: "migrations",
+	database.SqliteDriver:   "migrations-sqlite",
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var migrationName = regexp.MustCompile(`^\w+$`)
+
+var ErrDirty = goerrors.New("database is dirty, a migration failed halfway and must be fixed manually")
+
+type Migration struct {
+	Version uint64
+	Name    string
+	up      string
+	down    string
+}
+
+// Migrator applies the embedded migrations of the database driver. The version is kept in schema_migrations
+// the way the migrate CLI keeps it, so databases migrated with db/migrate.sh continue from their version.
+// Each step runs in a transaction together with its version update, so the Migrator never sets dirty to true,
+// it only refuses to run on a database the migrate CLI left dirty.
+type Migrator struct {
+	db         *database.DB
+	log        *log.Logger
+	migrations []Migration
+}
+
+func New(connection database.Database, log *log.Logger) (*Migrator, error) {
+	dir, ok := Dirs[connection.DriverName()]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for driver %s", connection.DriverName())
+	}
+
+	migrations, err := load(db.Migrations, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: connection, log: log, migrations: migrations}, nil
+}
+
+func load(files fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if match[3] == "up" {
+			migration.up = path.Join(dir, entry.Name())
+		} else {
+			migration.down = path.Join(dir, entry.Name())
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrations returns the embedded migrations ordered by version
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up applies all pending migrations and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.steps(ctx, true, -1)
+}
+
+// Down reverts the last n applied migrations and returns how many were reverted
+func (m *Migrator) Down(ctx context.Context, n int) (int, error) {
+	return m.steps(ctx, false, n)
+}
+
+// Version returns the version of the last applied migration, 0 if none was applied
+func (m *Migrator) Version(ctx context.Context) (uint64, bool, error) {
+	err := m.createVersionTable(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return m.version(ctx)
+}
+
+// steps applies or reverts up to n migrations, all of them if n is negative. Each one runs in its own
+// transaction together with the version update, which reads the version again inside the transaction.
+func (m *Migrator) steps(ctx context.Context, up bool, n int) (int, error) {
+	done := 0
+	err := m.withLock(ctx, func() error {
+		err := m.createVersionTable(ctx)
+		if err != nil {
+			return err
+		}
+
+		for n < 0 || done < n {
+			applied, err := m.step(ctx, up)
+			if err != nil {
+				return err
+			}
+			if !applied {
+				return nil
+			}
+			done++
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+func (m *Migrator) step(ctx context.Context, up bool) (bool, error) {
+	applied := false
+	err := m.db.InTransaction(ctx, func(ctx context.Context) error {
+		version, dirty, err := m.version(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w (version %d)", ErrDirty, version)
+		}
+
+		migration, file, target, err := m.next(version, up)
+		if err != nil || migration == nil {
+			return err
+		}
+
+		query, err := fs.ReadFile(db.Migrations, file)
+		if err != nil {
+			return err
+		}
+		_, err = m.db.ExecContext(ctx, string(query))
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", path.Base(file), err)
+		}
+
+		err = m.setVersion(ctx, target)
+		if err != nil {
+			return err
+		}
+
+		m.log.Infof("Migrated %s", path.Base(file))
+		applied = true
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// next finds the migration to run from version and the version it leaves the database at
+func (m *Migrator) next(version uint64, up bool) (*Migration, string, uint64, error) {
+	if up {
+		for i := range m.migrations {
+			if m.migrations[i].Version > version {
+				return &m.migrations[i], m.migrations[i].up, m.migrations[i].Version, nil
+			}
+		}
+
+		return nil, "", 0, nil
+	}
+
+	if version == 0 {
+		return nil, "", 0, nil
+	}
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			previous := uint64(0)
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+
+			return &m.migrations[i], m.migrations[i].down, previous, nil
+		}
+	}
+
+	return nil, "", 0, fmt.Errorf("database is at version %d, which has no migration in this binary", version)
+}
+
+// withLock runs fn holding the advisory lock. SQLite has a single writer and its transactions take the write
+// lock when they begin, so there the version check inside each transaction is enough.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if m.db.DriverName() != database.PostgresDriver {
+		return fn()
+	}
+
+	conn, err := m.db.DB.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockId)
+	if err != nil {
+		return fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+	defer func() {
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockId)
+		if err != nil {
+			m.log.Errorf("Failed to release the migration lock: %v", err)
+		}
+	}()
+
+	return fn()
+}
+
+func (m *Migrator) createVersionTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+
+	return err
+}
+
+func (m *Migrator) version(ctx context.Context) (uint64, bool, error) {
+	var row struct {
+		Version uint64 `db:"version"`
+		Dirty   bool   `db:"dirty"`
+	}
+	err := m.db.GetContext(ctx, &row, `SELECT version, dirty FROM schema_migrations LIMIT 1`)
+	if goerrors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	return row.Version, row.Dirty, err
+}
+
+func (m *Migrator) setVersion(ctx context.Context, version uint64) error {
+	_, err := m.db.ExecContext(ctx, `DELETE FROM schema_migrations`)
+	if err != nil || version == 0 {
+		return err
+	}
+	_, err = m.db.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, version)
+
+	return err
+}
+
+// Create writes empty up and down files of a new migration for every driver into the migrations
+// directories under dir and returns their paths. The binary has to be rebuilt to embed them.
+func Create(dir string, name string) ([]string, error) {
+	if !migrationName.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q, use letters, digits and underscores", name)
+	}
+
+	version := uint64(0)
+	for _, migrationsDir := range Dirs {
+		migrations, err := load(os.DirFS(dir), migrationsDir)
+		if err != nil {
+			return nil, err
+		}
+		if len(migrations) > 0 && migrations[len(migrations)-1].Version > version {
+			version = migrations[len(migrations)-1].Version
+		}
+	}
+
+	var created []string
+	for _, driver := range []string{database.PostgresDriver, database.SqliteDriver} {
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, Dirs[driver], fmt.Sprintf("%06d_%s.%s.sql", version+1, name, direction))
+			f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+			if err != nil {
+				return created, err
+			}
+			_ = f.Close()
+			created = append(created, file)
+		}
+	}
+
+	return created, nil
+}
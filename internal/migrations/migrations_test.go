@@ -0,0 +1,97 @@
+package migrations
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/log"
+)
+
+func newSqliteMigrator(t *testing.T) (*Migrator, database.Database) {
+	cfg := &config.Config{DbDriver: database.SqliteDriver, DbPath: filepath.Join(t.TempDir(), "auction.db")}
+	db, err := database.Connect(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	migrator, err := New(db, log.New(cfg))
+	require.NoError(t, err)
+
+	return migrator, db
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	migrator, db := newSqliteMigrator(t)
+	all := migrator.Migrations()
+	require.NotEmpty(t, all)
+	last := all[len(all)-1].Version
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(all), applied)
+	version, dirty, err := migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, last, version)
+	assert.False(t, dirty)
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, applied)
+
+	reverted, err := migrator.Down(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, reverted)
+	version, _, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, all[len(all)-3].Version, version)
+
+	reverted, err = migrator.Down(ctx, len(all)+1)
+	require.NoError(t, err)
+	assert.Equal(t, len(all)-2, reverted)
+	version, _, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), version)
+
+	var tables int
+	require.NoError(t, db.Get(&tables,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')`))
+	assert.Equal(t, 0, tables)
+}
+
+func TestMigratorDirty(t *testing.T) {
+	ctx := context.Background()
+	migrator, db := newSqliteMigrator(t)
+	require.NoError(t, migrator.createVersionTable(ctx))
+	_, err := db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (3, true)`)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	assert.ErrorIs(t, err, ErrDirty)
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "migrations"), 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "migrations-sqlite"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "migrations", "000007_add_bids.up.sql"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "migrations", "000007_add_bids.down.sql"), nil, 0o644))
+
+	files, err := Create(dir, "add_watchlist")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "migrations", "000008_add_watchlist.up.sql"),
+		filepath.Join(dir, "migrations", "000008_add_watchlist.down.sql"),
+		filepath.Join(dir, "migrations-sqlite", "000008_add_watchlist.up.sql"),
+		filepath.Join(dir, "migrations-sqlite", "000008_add_watchlist.down.sql"),
+	}, files)
+
+	_, err = Create(dir, "drop table")
+	assert.Error(t, err)
+}
@@ -0,0 +1,276 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"ypeskov/go_hillel_9/internal/jwtkeys"
+)
+
+const requestTimeout = 10 * time.Second
+
+// Config describes a client registered at an OpenID Connect provider
+type Config struct {
+	IssuerUrl    string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	Scopes       []string
+}
+
+// IdTokenClaims are the claims of a verified ID token the login needs
+type IdTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IdToken string `json:"id_token"`
+	Error   string `json:"error"`
+}
+
+// Client runs the authorization code flow with PKCE (RFC 7636).
+// The provider is discovered on first use, so the API starts even if the provider is down.
+type Client struct {
+	cfg  Config
+	http *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     map[string]crypto.PublicKey
+}
+
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// NewPkce returns a random code verifier and its S256 code challenge
+func NewPkce() (string, string, error) {
+	verifier, err := RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// RandomString returns n random bytes encoded as URL safe base64, for states and nonces
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL is where the user is redirected to log in at the provider
+func (c *Client) AuthCodeURL(state string, nonce string, codeChallenge string) (string, error) {
+	m, err := c.discover()
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientId},
+		"redirect_uri":          {c.cfg.RedirectUrl},
+		"scope":                 {strings.Join(c.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(m.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return m.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code and returns the claims of the verified ID token
+func (c *Client) Exchange(code string, codeVerifier string, nonce string) (*IdTokenClaims, error) {
+	m, err := c.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectUrl},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.cfg.ClientId), url.QueryEscape(c.cfg.ClientSecret))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokens tokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, tokens.Error)
+	}
+	if tokens.IdToken == "" {
+		return nil, fmt.Errorf("token response has no id_token")
+	}
+
+	return c.verify(m, tokens.IdToken, nonce)
+}
+
+func (c *Client) verify(m *metadata, idToken string, nonce string) (*IdTokenClaims, error) {
+	claims := &IdTokenClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, c.keyfunc,
+		jwt.WithValidMethods([]string{jwtkeys.AlgRS256, jwtkeys.AlgEdDSA}),
+		jwt.WithIssuer(m.Issuer),
+		jwt.WithAudience(c.cfg.ClientId),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("nonce of id token doesn't match")
+	}
+
+	return claims, nil
+}
+
+// keyfunc refetches the provider keys once when it sees an unknown kid, the provider may have rotated them
+func (c *Client) keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if key, ok := c.key(kid); ok {
+		return key, nil
+	}
+
+	err := c.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if key, ok := c.key(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown kid %q", kid)
+}
+
+func (c *Client) key(kid string) (crypto.PublicKey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, ok := c.keys[kid]
+
+	return key, ok
+}
+
+func (c *Client) fetchKeys() error {
+	m, err := c.discover()
+	if err != nil {
+		return err
+	}
+
+	var jwks jwtkeys.JWKS
+	err = c.getJson(m.JwksUri, &jwks)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			// keys of unsupported types are skipped, the provider may publish other keys too
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *Client) discover() (*metadata, error) {
+	c.mu.Lock()
+	m := c.metadata
+	c.mu.Unlock()
+	if m != nil {
+		return m, nil
+	}
+
+	issuer := strings.TrimSuffix(c.cfg.IssuerUrl, "/")
+	m = &metadata{}
+	err := c.getJson(issuer+"/.well-known/openid-configuration", m)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(m.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("provider reports issuer %q instead of %q", m.Issuer, c.cfg.IssuerUrl)
+	}
+
+	c.mu.Lock()
+	c.metadata = m
+	c.mu.Unlock()
+
+	return m, nil
+}
+
+func (c *Client) getJson(url string, target any) error {
+	resp, err := c.http.Get(url)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request to %s failed with status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
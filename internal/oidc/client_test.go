@@ -0,0 +1,75 @@
+package oidc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"ypeskov/go_hillel_9/internal/oidc/oidctest"
+)
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	provider, err := oidctest.NewProvider("auction", "client-secret")
+	require.NoError(t, err)
+	defer provider.Close()
+
+	provider.SetUser(oidctest.User{Subject: "42", Email: "sso@example.com", EmailVerified: true})
+
+	client := NewClient(Config{
+		IssuerUrl:    provider.Issuer(),
+		ClientId:     "auction",
+		ClientSecret: "client-secret",
+		RedirectUrl:  "http://localhost:3000/users/oidc/callback/",
+		Scopes:       []string{"openid", "email"},
+	})
+
+	login := func(t *testing.T) (string, string, string) {
+		verifier, challenge, err := NewPkce()
+		require.NoError(t, err)
+
+		authUrl, err := client.AuthCodeURL("state-1", "nonce-1", challenge)
+		require.NoError(t, err)
+
+		code, state, err := provider.Authorize(authUrl)
+		require.NoError(t, err)
+		assert.Equal(t, "state-1", state)
+
+		return code, verifier, state
+	}
+
+	t.Run("Returns verified claims", func(t *testing.T) {
+		code, verifier, _ := login(t)
+
+		claims, err := client.Exchange(code, verifier, "nonce-1")
+		require.NoError(t, err)
+		assert.Equal(t, "42", claims.Subject)
+		assert.Equal(t, "sso@example.com", claims.Email)
+		assert.True(t, claims.EmailVerified)
+
+		_, err = client.Exchange(code, verifier, "nonce-1")
+		assert.Error(t, err, "a code can be redeemed only once")
+	})
+
+	t.Run("Wrong code verifier", func(t *testing.T) {
+		code, _, _ := login(t)
+		otherVerifier, _, _ := NewPkce()
+
+		_, err := client.Exchange(code, otherVerifier, "nonce-1")
+		assert.Error(t, err)
+	})
+
+	t.Run("Wrong nonce", func(t *testing.T) {
+		code, verifier, _ := login(t)
+
+		_, err := client.Exchange(code, verifier, "nonce-2")
+		assert.Error(t, err)
+	})
+
+	t.Run("Wrong client secret", func(t *testing.T) {
+		code, verifier, _ := login(t)
+		other := NewClient(Config{IssuerUrl: provider.Issuer(), ClientId: "auction", ClientSecret: "wrong",
+			RedirectUrl: "http://localhost:3000/users/oidc/callback/"})
+
+		_, err := other.Exchange(code, verifier, "nonce-1")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,118 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"ypeskov/go_hillel_9/internal/config"
+)
+
+const argon2idPrefix = "$argon2id$"
+
+type params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  uint32
+	keyLength   uint32
+}
+
+// Hasher hashes passwords with Argon2id in the PHC string format:
+// $argon2id$v=19$m=<memory KiB>,t=<iterations>,p=<parallelism>$<salt>$<hash>.
+// It still verifies bcrypt hashes of older accounts and reports them as outdated.
+type Hasher struct {
+	params params
+}
+
+func NewHasher(cfg *config.Config) (*Hasher, error) {
+	if cfg.Argon2MemoryKib < 8*uint32(cfg.Argon2Parallelism) || cfg.Argon2Iterations < 1 ||
+		cfg.Argon2Parallelism < 1 || cfg.Argon2SaltLength < 8 || cfg.Argon2KeyLength < 16 {
+		return nil, fmt.Errorf("invalid Argon2 parameters: memory %d KiB, iterations %d, parallelism %d, "+
+			"salt length %d, key length %d", cfg.Argon2MemoryKib, cfg.Argon2Iterations, cfg.Argon2Parallelism,
+			cfg.Argon2SaltLength, cfg.Argon2KeyLength)
+	}
+
+	return &Hasher{
+		params: params{
+			memory:      cfg.Argon2MemoryKib,
+			iterations:  cfg.Argon2Iterations,
+			parallelism: cfg.Argon2Parallelism,
+			saltLength:  cfg.Argon2SaltLength,
+			keyLength:   cfg.Argon2KeyLength,
+		},
+	}, nil
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.iterations, h.params.memory, h.params.parallelism,
+		h.params.keyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		h.params.memory, h.params.iterations, h.params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether the password matches the hash and whether the hash should be replaced,
+// because it is a bcrypt hash or was made with other Argon2 parameters
+func (h *Hasher) Verify(password string, hash string) (bool, bool) {
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+
+		return err == nil, true
+	}
+
+	p, salt, key, err := decode(hash)
+	if err != nil {
+		return false, false
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, p.keyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false
+	}
+
+	p.saltLength = uint32(len(salt))
+
+	return true, p != h.params
+}
+
+func decode(hash string) (params, []byte, []byte, error) {
+	var p params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism)
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id hash: %w", err)
+	}
+	p.keyLength = uint32(len(key))
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,100 @@
+package password
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Argon2MemoryKib:   1024,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+		Argon2SaltLength:  16,
+		Argon2KeyLength:   32,
+		PasswordMinLength: 10,
+		PasswordMaxLength: 64,
+	}
+}
+
+func TestHasher(t *testing.T) {
+	hasher, err := NewHasher(testConfig())
+	require.NoError(t, err)
+
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	other, _ := hasher.Hash("correct horse")
+	assert.NotEqual(t, hash, other, "every hash has its own salt")
+
+	match, needsRehash := hasher.Verify("correct horse", hash)
+	assert.True(t, match)
+	assert.False(t, needsRehash)
+
+	match, _ = hasher.Verify("wrong horse", hash)
+	assert.False(t, match)
+
+	t.Run("Bcrypt hashes need a rehash", func(t *testing.T) {
+		bcryptHash, _ := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+
+		match, needsRehash := hasher.Verify("correct horse", string(bcryptHash))
+		assert.True(t, match)
+		assert.True(t, needsRehash)
+	})
+
+	t.Run("Hashes with old parameters need a rehash", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.Argon2Iterations = 2
+		stronger, err := NewHasher(cfg)
+		require.NoError(t, err)
+
+		match, needsRehash := stronger.Verify("correct horse", hash)
+		assert.True(t, match)
+		assert.True(t, needsRehash)
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.Argon2Parallelism = 0
+		_, err := NewHasher(cfg)
+		assert.Error(t, err)
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	listFile := filepath.Join(t.TempDir(), "common.txt")
+	require.NoError(t, os.WriteFile(listFile, []byte("# company names\nAuctionHouse2024\n"), 0o600))
+
+	cfg := testConfig()
+	cfg.PasswordCommonListFile = listFile
+	policy, err := NewPolicy(cfg)
+	require.NoError(t, err)
+
+	assert.NoError(t, policy.Validate("correct horse battery"))
+
+	tests := []struct {
+		password string
+		reason   string
+	}{
+		{"short", "at least 10 characters"},
+		{strings.Repeat("x", 65), "at most 64 characters"},
+		{"Password123", "too common"},
+		{"auctionhouse2024", "too common"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			assert.ErrorIs(t, err, errors.WeakPasswordErr)
+			assert.Contains(t, err.Error(), tt.reason)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+)
+
+//go:embed common-passwords.txt
+var commonPasswords string
+
+// Policy rejects passwords that are too short, too long or commonly used
+type Policy struct {
+	minLength int
+	maxLength int
+	common    map[string]struct{}
+}
+
+// PolicyError lists every rule a password breaks
+type PolicyError struct {
+	Reasons []string
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("%s: %s", errors.WeakPasswordErr.Message, strings.Join(e.Reasons, "; "))
+}
+
+func (e *PolicyError) Unwrap() error {
+	return errors.WeakPasswordErr
+}
+
+// NewPolicy loads the built-in list of common passwords and the one from PasswordCommonListFile if it is set
+func NewPolicy(cfg *config.Config) (*Policy, error) {
+	if cfg.PasswordMinLength < 1 || cfg.PasswordMaxLength < cfg.PasswordMinLength {
+		return nil, fmt.Errorf("invalid password length limits %d..%d", cfg.PasswordMinLength, cfg.PasswordMaxLength)
+	}
+
+	p := &Policy{
+		minLength: cfg.PasswordMinLength,
+		maxLength: cfg.PasswordMaxLength,
+		common:    make(map[string]struct{}),
+	}
+
+	err := p.addCommon(strings.NewReader(commonPasswords))
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.PasswordCommonListFile != "" {
+		file, err := os.Open(cfg.PasswordCommonListFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open common password list: %w", err)
+		}
+		defer file.Close()
+
+		err = p.addCommon(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read common password list: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+func (p *Policy) Validate(password string) error {
+	var reasons []string
+
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		reasons = append(reasons, fmt.Sprintf("must be at least %d characters long", p.minLength))
+	}
+	if length > p.maxLength {
+		reasons = append(reasons, fmt.Sprintf("must be at most %d characters long", p.maxLength))
+	}
+	if _, ok := p.common[strings.ToLower(password)]; ok {
+		reasons = append(reasons, "is too common")
+	}
+
+	if len(reasons) > 0 {
+		return &PolicyError{Reasons: reasons}
+	}
+
+	return nil
+}
+
+// addCommon reads one password per line, empty lines and lines starting with # are skipped
+func (p *Policy) addCommon(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.common[strings.ToLower(line)] = struct{}{}
+	}
+
+	return scanner.Err()
+}
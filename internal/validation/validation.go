@@ -0,0 +1,61 @@
+package validation
+
+import (
+	goerrors "errors"
+	"github.com/go-playground/validator"
+	"reflect"
+	"strings"
+	"ypeskov/go_hillel_9/internal/errors"
+)
+
+// validate is shared by all requests, it caches the parsed validation tags of every struct
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return field.Name
+		}
+
+		return name
+	})
+
+	return v
+}
+
+// Struct validates the fields of s by their validate tags. It returns *errors.ValidationError
+// listing every failed field by its JSON name.
+func Struct(s interface{}) error {
+	err := validate.Struct(s)
+
+	var fieldErrs validator.ValidationErrors
+	if !goerrors.As(err, &fieldErrs) {
+		return err
+	}
+
+	fields := make([]errors.FieldError, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		fields = append(fields, errors.FieldError{
+			Field: fieldPath(fieldErr),
+			Rule:  fieldErr.Tag(),
+			Param: fieldErr.Param(),
+		})
+	}
+
+	return errors.NewValidationError(fields)
+}
+
+// fieldPath returns the JSON path of the field without the name of the validated struct, e.g. address.city
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return fieldErr.Field()
+}
@@ -0,0 +1,42 @@
+package validation
+
+import (
+	goerrors "errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"ypeskov/go_hillel_9/internal/errors"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type signUp struct {
+	Email    string  `json:"email" validate:"required,email"`
+	Password string  `json:"password,omitempty" validate:"min=8"`
+	Age      int     `validate:"gte=18"`
+	Address  address `json:"address"`
+}
+
+func TestStruct(t *testing.T) {
+	err := Struct(&signUp{Email: "not-an-email", Password: "short", Age: 17})
+
+	var validationErr *errors.ValidationError
+	require.True(t, goerrors.As(err, &validationErr))
+	assert.ErrorIs(t, err, errors.ValidationFailedErr)
+	assert.Equal(t, errors.ValidationFailedErr.Code, validationErr.Code)
+	assert.Equal(t, []errors.FieldError{
+		{Field: "email", Rule: "email"},
+		{Field: "password", Rule: "min", Param: "8"},
+		{Field: "Age", Rule: "gte", Param: "18"},
+		{Field: "address.city", Rule: "required"},
+	}, validationErr.Fields)
+}
+
+func TestStructValid(t *testing.T) {
+	err := Struct(&signUp{Email: "user@example.com", Password: "long enough", Age: 18,
+		Address: address{City: "Kyiv"}})
+
+	assert.NoError(t, err)
+}
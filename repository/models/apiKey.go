@@ -0,0 +1,31 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ScopeItemsRead  = "items:read"
+	ScopeItemsWrite = "items:write"
+)
+
+var ApiKeyScopes = []string{ScopeItemsRead, ScopeItemsWrite}
+
+type ApiKey struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"userId" db:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     string     `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	ExpiresAt  *time.Time `json:"expiresAt" db:"expires_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+func (k *ApiKey) HasScope(scope string) bool {
+	return slices.Contains(strings.Fields(k.Scopes), scope)
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// IdempotencyKey is a request a user made with an Idempotency-Key header. Status is nil while the first
+// request is in progress, after that its response is kept until ExpiresAt to be replayed to retries.
+// LockToken identifies the request holding the key while it is in progress.
+type IdempotencyKey struct {
+	UserId       int       `db:"user_id"`
+	Key          string    `db:"idempotency_key"`
+	RequestHash  string    `db:"request_hash"`
+	LockToken    string    `db:"lock_token"`
+	Status       *int      `db:"status"`
+	ContentType  *string   `db:"content_type"`
+	ResponseBody []byte    `db:"response_body"`
+	CreatedAt    time.Time `db:"created_at"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
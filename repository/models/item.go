@@ -1,7 +1,8 @@
 package models
 
 import (
-	"github.com/go-playground/validator"
+	"time"
+	"ypeskov/go_hillel_9/internal/validation"
 )
 
 type Item struct {
@@ -11,10 +12,23 @@ type Item struct {
 	InitialPrice float64  `json:"initialPrice" validate:"min=0" db:"initial_price"`
 	SoldPrice    *float64 `json:"soldPrice" db:"sold_price"`
 	Description  *string  `json:"description"`
+
+	// BuyerId is set together with SoldPrice when the seller marks the item as sold,
+	// SoldAt when the buyer confirms the sale. Until then the sale is pending.
+	BuyerId *int       `json:"buyerId" db:"buyer_id"`
+	SoldAt  *time.Time `json:"soldAt" db:"sold_at"`
 }
 
-func (i *Item) Validate() error {
-	validate := validator.New()
+// ItemsFilter selects the items of one user, Limit 0 means all of them.
+// ActiveOnly keeps the unsold items and SoldOnly the sold ones whose sale the buyer confirmed.
+type ItemsFilter struct {
+	UserId     int
+	ActiveOnly bool
+	SoldOnly   bool
+	Limit      int
+	Offset     int
+}
 
-	return validate.Struct(i)
+func (i *Item) Validate() error {
+	return validation.Struct(i)
 }
@@ -1,8 +1,8 @@
 package models
 
 import (
-	"github.com/go-playground/validator"
 	"time"
+	"ypeskov/go_hillel_9/internal/validation"
 )
 
 type ItemComment struct {
@@ -14,7 +14,5 @@ type ItemComment struct {
 }
 
 func (ic *ItemComment) Validate() error {
-	validate := validator.New()
-
-	return validate.Struct(ic)
+	return validation.Struct(ic)
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ItemQuestion is public once the seller has answered it, until then only the asker and the seller see it
+type ItemQuestion struct {
+	Id         int        `json:"id"`
+	ItemId     int        `json:"itemId" db:"item_id"`
+	AskerId    int        `json:"askerId" db:"asker_id"`
+	Question   string     `json:"question"`
+	Answer     *string    `json:"answer"`
+	AnsweredAt *time.Time `json:"answeredAt" db:"answered_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	LoginSubjectAccount = "account"
+	LoginSubjectIp      = "ip"
+
+	LoginEventLocked   = "LOCKED"
+	LoginEventUnlocked = "UNLOCKED"
+)
+
+type LoginFailure struct {
+	SubjectType   string     `json:"subjectType" db:"subject_type"`
+	Subject       string     `json:"subject"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"lastFailureAt" db:"last_failure_at"`
+	LockedUntil   *time.Time `json:"lockedUntil" db:"locked_until"`
+}
+
+type LoginLockoutEvent struct {
+	Id          int       `json:"id"`
+	SubjectType string    `json:"subjectType" db:"subject_type"`
+	Subject     string    `json:"subject"`
+	Event       string    `json:"event"`
+	Ip          string    `json:"ip"`
+	AdminId     *int      `json:"adminId" db:"admin_id"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+type Conversation struct {
+	Id            int       `json:"id"`
+	UserAId       int       `json:"userAId" db:"user_a_id"`
+	UserBId       int       `json:"userBId" db:"user_b_id"`
+	ItemId        *int      `json:"itemId" db:"item_id"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+	LastMessageAt time.Time `json:"lastMessageAt" db:"last_message_at"`
+}
+
+func (c *Conversation) HasParticipant(userId int) bool {
+	return c.UserAId == userId || c.UserBId == userId
+}
+
+// OtherParticipant returns the id of the participant who is not userId
+func (c *Conversation) OtherParticipant(userId int) int {
+	if c.UserAId == userId {
+		return c.UserBId
+	}
+
+	return c.UserAId
+}
+
+// ConversationSummary is a conversation as one of its participants sees it in the list
+type ConversationSummary struct {
+	Conversation
+	OtherUserId int `json:"otherUserId" db:"-"`
+	UnreadCount int `json:"unreadCount" db:"unread_count"`
+}
+
+type Message struct {
+	Id             int        `json:"id"`
+	ConversationId int        `json:"conversationId" db:"conversation_id"`
+	SenderId       int        `json:"senderId" db:"sender_id"`
+	Body           string     `json:"body"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	ReadAt         *time.Time `json:"readAt" db:"read_at"`
+}
+
+type UserBlock struct {
+	BlockerId int       `json:"-" db:"blocker_id"`
+	BlockedId int       `json:"blockedId" db:"blocked_id"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// UserProfile is the public part of a user, it must never contain contact details or credentials
+type UserProfile struct {
+	Id             int       `json:"id"`
+	DisplayName    string    `json:"displayName"`
+	MemberSince    time.Time `json:"memberSince"`
+	ItemsSold      int       `json:"itemsSold"`
+	ActiveListings int       `json:"activeListings"`
+	// nil until the user gets the first rating
+	AverageRating *float64 `json:"averageRating"`
+	RatingsCount  int      `json:"ratingsCount"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// A review starts PUBLISHED, the reviewee may dispute it and an administrator then either
+// publishes it again or removes it. Removed reviews are hidden and don't count in ratings.
+const (
+	ReviewStatusPublished = "PUBLISHED"
+	ReviewStatusDisputed  = "DISPUTED"
+	ReviewStatusRemoved   = "REMOVED"
+)
+
+type Review struct {
+	Id            int        `json:"id"`
+	ItemId        *int       `json:"itemId" db:"item_id"`
+	ReviewerId    int        `json:"reviewerId" db:"reviewer_id"`
+	RevieweeId    int        `json:"revieweeId" db:"reviewee_id"`
+	Rating        int        `json:"rating"`
+	Review        string     `json:"review"`
+	Response      *string    `json:"response" db:"response"`
+	RespondedAt   *time.Time `json:"respondedAt" db:"responded_at"`
+	Status        string     `json:"status"`
+	DisputeReason string     `json:"disputeReason,omitempty" db:"dispute_reason"`
+	DisputedAt    *time.Time `json:"disputedAt,omitempty" db:"disputed_at"`
+	ResolvedBy    *int       `json:"-" db:"resolved_by"`
+	ResolvedAt    *time.Time `json:"resolvedAt,omitempty" db:"resolved_at"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// RatingSummary aggregates the ratings a user has received
+type RatingSummary struct {
+	AverageRating *float64 `json:"averageRating" db:"average_rating"`
+	RatingsCount  int      `json:"ratingsCount" db:"ratings_count"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type Session struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"userId" db:"user_id"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"userAgent" db:"user_agent"`
+	Ip         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	LastUsedAt time.Time  `json:"lastUsedAt" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revokedAt" db:"revoked_at"`
+	Current    bool       `json:"current" db:"-"`
+}
+
+type RefreshToken struct {
+	Id        int        `json:"id"`
+	SessionId int        `db:"session_id"`
+	TokenHash string     `db:"token_hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
@@ -1,25 +1,48 @@
 package models
 
 import (
-	"github.com/go-playground/validator"
 	"time"
+	"ypeskov/go_hillel_9/internal/validation"
 )
 
 type User struct {
-	Id           int       `json:"id"`
-	FirstName    string    `json:"firstName" validate:"required,min=1" db:"first_name"`
-	LastName     string    `json:"lastName" validate:"required,min=1" db:"last_name"`
-	Email        string    `json:"email" validate:"required,email,min=1"`
-	PasswordHash string    `json:"password" db:"password_hash"`
-	LastLoginUtc time.Time `db:"last_login_utc"`
-	UserTypeId   int32     `json:"userTypeId" validate:"required" db:"user_type_id"`
+	Id            int       `json:"id"`
+	FirstName     string    `json:"firstName" validate:"required,min=1" db:"first_name"`
+	LastName      string    `json:"lastName" validate:"required,min=1" db:"last_name"`
+	Email         string    `json:"email" validate:"required,email,min=1"`
+	PasswordHash  string    `json:"password" db:"password_hash"`
+	LastLoginUtc  time.Time `db:"last_login_utc"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+	UserTypeId    int32     `json:"userTypeId" validate:"required" db:"user_type_id"`
+	EmailVerified bool      `json:"emailVerified" db:"email_verified"`
+
+	TokensValidAfter *time.Time `json:"-" db:"tokens_valid_after"`
+
+	MfaEnabled      bool    `json:"mfaEnabled" db:"mfa_enabled"`
+	MfaSecret       *string `json:"-" db:"mfa_secret"`
+	MfaLastUsedStep *int64  `json:"-" db:"mfa_last_used_step"`
+
+	Status         string     `json:"status" db:"status"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty" db:"suspended_until"`
+	StatusReason   string     `json:"statusReason,omitempty" db:"status_reason"`
+}
+
+const (
+	UserStatusActive    = "ACTIVE"
+	UserStatusSuspended = "SUSPENDED"
+	UserStatusBanned    = "BANNED"
+)
+
+// IsBanned and IsSuspended tell whether the user may not use the API at the moment,
+// a suspension ends by itself when SuspendedUntil has passed
+func (u *User) IsBanned() bool {
+	return u.Status == UserStatusBanned
+}
+
+func (u *User) IsSuspended(now time.Time) bool {
+	return u.Status == UserStatusSuspended && (u.SuspendedUntil == nil || u.SuspendedUntil.After(now))
 }
 
 func (u *User) Validate() error {
-	validate := validator.New()
-	err := validate.Struct(u)
-	if err != nil {
-		return err
-	}
-	return nil
+	return validation.Struct(u)
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	ModerationActionSuspend    = "SUSPEND"
+	ModerationActionBan        = "BAN"
+	ModerationActionReactivate = "REACTIVATE"
+	ModerationActionChangeRole = "CHANGE_ROLE"
+)
+
+type UserModerationEvent struct {
+	Id        int       `json:"id"`
+	UserId    int       `json:"userId" db:"user_id"`
+	AdminId   *int      `json:"adminId" db:"admin_id"`
+	Action    string    `json:"action"`
+	Reason    string    `json:"reason"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// UserActivity counts what a user has done on the marketplace
+type UserActivity struct {
+	ItemsCount          int `json:"itemsCount" db:"items_count"`
+	CommentsCount       int `json:"commentsCount" db:"comments_count"`
+	ActiveSessionsCount int `json:"activeSessionsCount" db:"active_sessions_count"`
+	ApiKeysCount        int `json:"apiKeysCount" db:"api_keys_count"`
+}
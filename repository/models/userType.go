@@ -1,8 +1,33 @@
 package models
 
+import "time"
+
+const (
+	SellerTypeCode = "SELLER"
+	BuyerTypeCode  = "BUYER"
+	AdminTypeCode  = "ADMIN"
+)
+
 type UserType struct {
 	Id              int    `json:"id"`
 	TypeName        string `json:"typeName" db:"type_name"`
 	TypeDescription string `json:"typeDescription" db:"type_description"`
 	TypeCode        string `json:"typeCode" db:"type_code"`
+	MfaRequired     bool   `json:"mfaRequired" db:"mfa_required"`
+
+	// self-assignable types can be chosen at sign-up, the administrator type never is
+	SelfAssignable bool `json:"selfAssignable" db:"self_assignable"`
+
+	// retired types stay for the history, new users can't get them
+	RetiredAt *time.Time `json:"retiredAt,omitempty" db:"retired_at"`
+}
+
+// IsAdmin tells whether users of the type pass AdminMiddleware
+func (ut *UserType) IsAdmin() bool {
+	return ut.TypeCode == AdminTypeCode
+}
+
+// IsBuiltIn tells whether the code relies on the type, such types can't be retired
+func (ut *UserType) IsBuiltIn() bool {
+	return ut.TypeCode == SellerTypeCode || ut.TypeCode == BuyerTypeCode || ut.TypeCode == AdminTypeCode
 }
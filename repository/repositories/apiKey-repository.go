@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"fmt"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type ApiKeyRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type ApiKeyRepositoryInterface interface {
+	CreateApiKey(ctx context.Context, apiKey *models.ApiKey) (*models.ApiKey, error)
+	GetApiKeysList(ctx context.Context, userId int) ([]*models.ApiKey, error)
+	GetApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error)
+	TouchApiKey(ctx context.Context, id int) error
+	RevokeApiKey(ctx context.Context, id int, userId int) error
+}
+
+func GetApiKeyRepository(log *log.Logger, connection database.Database) ApiKeyRepositoryInterface {
+	return &ApiKeyRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+func (r *ApiKeyRepository) CreateApiKey(ctx context.Context, apiKey *models.ApiKey) (*models.ApiKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	apiKey.CreatedAt = time.Now().UTC()
+
+	insertQuery := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, created_at, expires_at)
+					VALUES (:user_id, :name, :prefix, :key_hash, :scopes, :created_at, :expires_at)
+					RETURNING *`
+
+	rows, err := r.db.NamedQueryContext(ctx, insertQuery, apiKey)
+	if err != nil {
+		r.log.Errorln("failed to insert api key into db", err)
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var newApiKey models.ApiKey
+	if rows.Next() {
+		err := rows.StructScan(&newApiKey)
+		if err != nil {
+			r.log.Errorf("Failed to scan api key: %v", err)
+
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf("failed to scan new api key")
+	}
+
+	return &newApiKey, nil
+}
+
+func (r *ApiKeyRepository) GetApiKeysList(ctx context.Context, userId int) ([]*models.ApiKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var apiKeys []*models.ApiKey
+
+	err := r.db.SelectContext(ctx, &apiKeys, `SELECT * FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL
+								  ORDER BY created_at DESC`, userId)
+	if err != nil {
+		r.log.Errorln("failed to get api keys from db", err)
+
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (r *ApiKeyRepository) GetApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var apiKey models.ApiKey
+
+	err := r.db.GetContext(ctx, &apiKey, "SELECT * FROM api_keys WHERE key_hash = $1", keyHash)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get api key from db", err)
+
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *ApiKeyRepository) TouchApiKey(ctx context.Context, id int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = $1 WHERE id = $2", time.Now().UTC(), id)
+	if err != nil {
+		r.log.Errorln("failed to update api key", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *ApiKeyRepository) RevokeApiKey(ctx context.Context, id int, userId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL",
+		time.Now().UTC(), id, userId)
+	if err != nil {
+		r.log.Errorln("failed to revoke api key", err)
+
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log.Errorln("error checking rows affected", err)
+
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.NotFoundErr
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type IdempotencyRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type IdempotencyRepositoryInterface interface {
+	ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error)
+	GetIdempotencyKey(ctx context.Context, userId int, key string) (*models.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error
+	ExtendIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error
+	DeleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error
+}
+
+func GetIdempotencyRepository(log *log.Logger, connection database.Database) IdempotencyRepositoryInterface {
+	return &IdempotencyRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+// ReserveIdempotencyKey stores the key unless the user has it already and returns whether it was stored.
+// The expired keys of the user are deleted first, so an expired key can be used again
+// and a request whose lock timed out can be taken over.
+func (r *IdempotencyRepository) ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE user_id = $1 AND expires_at < $2",
+		key.UserId, key.CreatedAt)
+	if err != nil {
+		r.log.Errorln("failed to delete expired idempotency keys", err)
+
+		return false, err
+	}
+
+	result, err := r.db.ExecContext(ctx, `INSERT INTO idempotency_keys
+			(user_id, idempotency_key, request_hash, lock_token, created_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (user_id, idempotency_key) DO NOTHING`,
+		key.UserId, key.Key, key.RequestHash, key.LockToken, key.CreatedAt, key.ExpiresAt)
+	if err != nil {
+		r.log.Errorln("failed to insert idempotency key", err)
+
+		return false, err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return inserted == 1, nil
+}
+
+func (r *IdempotencyRepository) GetIdempotencyKey(ctx context.Context, userId int,
+	key string) (*models.IdempotencyKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var idempotencyKey models.IdempotencyKey
+	err := r.db.GetContext(ctx, &idempotencyKey,
+		"SELECT * FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2", userId, key)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get idempotency key", err)
+
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request and when it expires. It returns NotFoundErr
+// if the key isn't in progress with the lock token of the request anymore.
+func (r *IdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE idempotency_keys
+			SET status = $1, content_type = $2, response_body = $3, expires_at = $4
+			WHERE user_id = $5 AND idempotency_key = $6 AND lock_token = $7 AND status IS NULL`,
+		key.Status, key.ContentType, key.ResponseBody, key.ExpiresAt, key.UserId, key.Key, key.LockToken)
+	if err != nil {
+		r.log.Errorln("failed to store idempotent response", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+// ExtendIdempotencyKey moves the lock timeout of a key in progress, it returns NotFoundErr like
+// CompleteIdempotencyKey
+func (r *IdempotencyRepository) ExtendIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE idempotency_keys SET expires_at = $1
+			WHERE user_id = $2 AND idempotency_key = $3 AND lock_token = $4 AND status IS NULL`,
+		key.ExpiresAt, key.UserId, key.Key, key.LockToken)
+	if err != nil {
+		r.log.Errorln("failed to extend idempotency key", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+// DeleteIdempotencyKey deletes the key only while it is in progress with the lock token of the request
+func (r *IdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys
+			WHERE user_id = $1 AND idempotency_key = $2 AND lock_token = $3 AND status IS NULL`,
+		key.UserId, key.Key, key.LockToken)
+	if err != nil {
+		r.log.Errorln("failed to delete idempotency key", err)
+
+		return err
+	}
+
+	return nil
+}
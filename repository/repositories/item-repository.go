@@ -1,7 +1,11 @@
 package repositories
 
 import (
+	"context"
+	"database/sql"
+	goerrors "errors"
 	"fmt"
+	"strings"
 	"time"
 	"ypeskov/go_hillel_9/internal/database"
 	"ypeskov/go_hillel_9/internal/errors"
@@ -15,13 +19,20 @@ type ItemRepository struct {
 }
 
 type ItemRepositoryInterface interface {
-	GetItemsList(userId int) ([]*models.Item, error)
-	CreateItem(srcItem *models.Item) (*models.Item, error)
-	GetItemById(id int, userId int) (*models.Item, error)
-	UpdateItem(id int, srcItem *models.Item, userId int) (*models.Item, error)
-	DeleteItem(id int, userId int) error
-	GetAllItems() ([]*models.Item, error)
-	CreateItemComment(comment *models.ItemComment) (*models.ItemComment, error)
+	GetItemsList(ctx context.Context, filter models.ItemsFilter) ([]*models.Item, error)
+	CreateItem(ctx context.Context, srcItem *models.Item) (*models.Item, error)
+	CreateItems(ctx context.Context, items []*models.Item) ([]*models.Item, error)
+	StreamItems(ctx context.Context, filter models.ItemsFilter, fn func(item *models.Item) error) error
+	GetItemById(ctx context.Context, id int, userId int) (*models.Item, error)
+	UpdateItem(ctx context.Context, id int, srcItem *models.Item, userId int) (*models.Item, error)
+	DeleteItem(ctx context.Context, id int, userId int) error
+	GetAllItems(ctx context.Context) ([]*models.Item, error)
+	CreateItemComment(ctx context.Context, comment *models.ItemComment) (*models.ItemComment, error)
+	MarkItemSold(ctx context.Context, id int, userId int, buyerId int, soldPrice float64) (*models.Item, error)
+	ConfirmItemSale(ctx context.Context, id int, buyerId int) (*models.Item, error)
+	DeclineItemSale(ctx context.Context, id int, buyerId int) error
+	GetSoldItem(ctx context.Context, id int) (*models.Item, error)
+	GetItem(ctx context.Context, id int) (*models.Item, error)
 }
 
 func GetItemRepository(log *log.Logger, connection database.Database) ItemRepositoryInterface {
@@ -31,10 +42,14 @@ func GetItemRepository(log *log.Logger, connection database.Database) ItemReposi
 	}
 }
 
-func (r *ItemRepository) GetItemsList(userId int) ([]*models.Item, error) {
+func (r *ItemRepository) GetItemsList(ctx context.Context, filter models.ItemsFilter) ([]*models.Item, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	var items []*models.Item
 
-	err := r.db.Select(&items, "SELECT * FROM items WHERE user_id = $1", userId)
+	query, args := itemsQuery(filter)
+	err := r.db.SelectContext(ctx, &items, query, args...)
 	if err != nil {
 		r.log.Error("failed to get items from db", err)
 
@@ -44,20 +59,103 @@ func (r *ItemRepository) GetItemsList(userId int) ([]*models.Item, error) {
 	return items, nil
 }
 
-func (r *ItemRepository) CreateItem(srcItem *models.Item) (*models.Item, error) {
+func itemsQuery(filter models.ItemsFilter) (string, []any) {
+	query := "SELECT * FROM items WHERE user_id = $1"
+	args := []any{filter.UserId}
+	if filter.ActiveOnly {
+		query += " AND sold_price IS NULL"
+	}
+	if filter.SoldOnly {
+		query += " AND sold_at IS NOT NULL"
+	}
+	query += " ORDER BY id"
+	if filter.Limit > 0 {
+		query += " LIMIT $2 OFFSET $3"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	return query, args
+}
+
+// StreamItems calls fn for every item of the filter without loading them all into memory.
+// The query runs as long as fn takes, only ctx limits it, so a slow client of an export isn't cut off.
+func (r *ItemRepository) StreamItems(ctx context.Context, filter models.ItemsFilter,
+	fn func(item *models.Item) error) error {
+	query, args := itemsQuery(filter)
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		r.log.Errorln("failed to query items", err)
+
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.Item
+		err = rows.StructScan(&item)
+		if err != nil {
+			r.log.Errorln("failed to scan item", err)
+
+			return err
+		}
+
+		err = fn(&item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// CreateItems inserts the items with one statement, callers keep the batches small enough for the
+// limit of query parameters
+func (r *ItemRepository) CreateItems(ctx context.Context, items []*models.Item) ([]*models.Item, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	values := make([]string, 0, len(items))
+	args := make([]any, 0, len(items)*4)
+	for i, item := range items {
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d)", i*4+1, i*4+2, i*4+3, i*4+4))
+		args = append(args, item.UserId, item.Title, item.InitialPrice, item.Description)
+	}
+
+	var created []*models.Item
+	err := r.db.SelectContext(ctx, &created, "INSERT INTO items (user_id, title, initial_price, description) "+
+		"VALUES "+strings.Join(values, ", ")+" RETURNING *", args...)
+	if err != nil {
+		r.log.Errorln("failed to insert items", err)
+
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *ItemRepository) CreateItem(ctx context.Context, srcItem *models.Item) (*models.Item, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	insertQuery := `INSERT INTO items (user_id, title, initial_price, description) 
 					VALUES ($1, $2, $3, $4) RETURNING *`
-	row, err := r.db.Query(insertQuery, srcItem.UserId, srcItem.Title, srcItem.InitialPrice, srcItem.Description)
+	row, err := r.db.QueryContext(ctx, insertQuery, srcItem.UserId, srcItem.Title, srcItem.InitialPrice,
+		srcItem.Description)
 	if err != nil {
 		r.log.Error("failed to insert srcItem into db", err)
 
 		return nil, err
 	}
+	defer row.Close()
 
 	var newItem models.Item
 	if row.Next() {
 		err = row.Scan(&newItem.Id, &newItem.UserId, &newItem.Title, &newItem.InitialPrice,
-			&newItem.SoldPrice, &newItem.Description)
+			&newItem.SoldPrice, &newItem.Description, &newItem.BuyerId, &newItem.SoldAt)
 		if err != nil {
 			r.log.Errorf("Failed to scan id: %v", err)
 
@@ -77,10 +175,13 @@ func (r *ItemRepository) CreateItem(srcItem *models.Item) (*models.Item, error)
 	return &newItem, nil
 }
 
-func (r *ItemRepository) GetItemById(id int, userId int) (*models.Item, error) {
+func (r *ItemRepository) GetItemById(ctx context.Context, id int, userId int) (*models.Item, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	var item models.Item
 
-	err := r.db.Get(&item, "SELECT * FROM items WHERE id = $1 AND user_id = $2", id, userId)
+	err := r.db.GetContext(ctx, &item, "SELECT * FROM items WHERE id = $1 AND user_id = $2", id, userId)
 	if err != nil {
 		r.log.Errorln("failed to get item by id", err)
 
@@ -90,22 +191,27 @@ func (r *ItemRepository) GetItemById(id int, userId int) (*models.Item, error) {
 	return &item, nil
 }
 
-func (r *ItemRepository) UpdateItem(id int, srcItem *models.Item, userId int) (*models.Item, error) {
+func (r *ItemRepository) UpdateItem(ctx context.Context, id int, srcItem *models.Item,
+	userId int) (*models.Item, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	updateQuery :=
 		"UPDATE items SET user_id = $1, title = $2, initial_price = $3, description = $4 " +
 			"WHERE id = $5 AND user_id = $6 RETURNING *"
-	row, err := r.db.Query(updateQuery, userId, srcItem.Title, srcItem.InitialPrice,
+	row, err := r.db.QueryContext(ctx, updateQuery, userId, srcItem.Title, srcItem.InitialPrice,
 		srcItem.Description, id, userId)
 	if err != nil {
 		r.log.Error("failed to update item in db", err)
 
 		return nil, err
 	}
+	defer row.Close()
 
 	var updatedItem models.Item
 	if row.Next() {
 		err = row.Scan(&updatedItem.Id, &updatedItem.UserId, &updatedItem.Title, &updatedItem.InitialPrice,
-			&updatedItem.SoldPrice, &updatedItem.Description)
+			&updatedItem.SoldPrice, &updatedItem.Description, &updatedItem.BuyerId, &updatedItem.SoldAt)
 		if err != nil {
 			r.log.Errorf("Failed to scan id: %v", err)
 
@@ -120,8 +226,11 @@ func (r *ItemRepository) UpdateItem(id int, srcItem *models.Item, userId int) (*
 	return &updatedItem, nil
 }
 
-func (r *ItemRepository) DeleteItem(id int, userId int) error {
-	result, err := r.db.Exec("DELETE FROM items WHERE id = $1 AND user_id = $2", id, userId)
+func (r *ItemRepository) DeleteItem(ctx context.Context, id int, userId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM items WHERE id = $1 AND user_id = $2", id, userId)
 	if err != nil {
 		r.log.Error("failed to delete item from db", err)
 
@@ -146,10 +255,114 @@ func (r *ItemRepository) DeleteItem(id int, userId int) error {
 	return nil
 }
 
-func (r *ItemRepository) GetAllItems() ([]*models.Item, error) {
+// MarkItemSold records a pending sale of an unsold item of the user, it returns NotFoundErr otherwise
+func (r *ItemRepository) MarkItemSold(ctx context.Context, id int, userId int, buyerId int,
+	soldPrice float64) (*models.Item, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var item models.Item
+
+	err := r.db.GetContext(ctx, &item, `UPDATE items SET sold_price = $1, buyer_id = $2
+							WHERE id = $3 AND user_id = $4 AND sold_price IS NULL RETURNING *`,
+		soldPrice, buyerId, id, userId)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to mark item as sold", err)
+
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// ConfirmItemSale completes the pending sale of the item to the buyer, it returns NotFoundErr otherwise
+func (r *ItemRepository) ConfirmItemSale(ctx context.Context, id int, buyerId int) (*models.Item, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var item models.Item
+
+	err := r.db.GetContext(ctx, &item, `UPDATE items SET sold_at = $1
+							WHERE id = $2 AND buyer_id = $3 AND sold_at IS NULL RETURNING *`,
+		time.Now().UTC(), id, buyerId)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to confirm item sale", err)
+
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// DeclineItemSale drops the pending sale of the item to the buyer, the item is for sale again
+func (r *ItemRepository) DeclineItemSale(ctx context.Context, id int, buyerId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE items SET sold_price = NULL, buyer_id = NULL
+							WHERE id = $1 AND buyer_id = $2 AND sold_at IS NULL`, id, buyerId)
+	if err != nil {
+		r.log.Errorln("failed to decline item sale", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+// GetItem returns an item of any user, GetItemById is limited to the items of one user
+func (r *ItemRepository) GetItem(ctx context.Context, id int) (*models.Item, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var item models.Item
+
+	err := r.db.GetContext(ctx, &item, "SELECT * FROM items WHERE id = $1", id)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get item", err)
+
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// GetSoldItem returns an item of any user if its sale was confirmed by the buyer
+func (r *ItemRepository) GetSoldItem(ctx context.Context, id int) (*models.Item, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var item models.Item
+
+	err := r.db.GetContext(ctx, &item, "SELECT * FROM items WHERE id = $1 AND sold_at IS NOT NULL", id)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get sold item", err)
+
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *ItemRepository) GetAllItems(ctx context.Context) ([]*models.Item, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	var items []*models.Item
 
-	err := r.db.Select(&items, "SELECT * FROM items")
+	err := r.db.SelectContext(ctx, &items, "SELECT * FROM items")
 	if err != nil {
 		r.log.Error("failed to get items from db", err)
 
@@ -159,20 +372,25 @@ func (r *ItemRepository) GetAllItems() ([]*models.Item, error) {
 	return items, nil
 }
 
-func (r *ItemRepository) CreateItemComment(comment *models.ItemComment) (*models.ItemComment, error) {
+func (r *ItemRepository) CreateItemComment(ctx context.Context,
+	comment *models.ItemComment) (*models.ItemComment, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	now := time.Now()
 	comment.CreatedAt = now
 
 	insertQuery := `INSERT INTO item_comments (user_id, item_id, comment, created_at) 
 					VALUES (:user_id, :item_id, :comment, :created_at) RETURNING *`
 
-	rows, err := r.db.NamedQuery(insertQuery, comment)
+	rows, err := r.db.NamedQueryContext(ctx, insertQuery, comment)
 	if err != nil {
 		r.log.Error("failed to insert comment into db", err)
 		r.log.Errorf("comment: %+v\n", comment)
 
 		return nil, err
 	}
+	defer rows.Close()
 
 	var newComment models.ItemComment
 	if rows.Next() {
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type LoginAttemptRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type LoginAttemptRepositoryInterface interface {
+	GetLoginFailure(ctx context.Context, subjectType string, subject string) (*models.LoginFailure, error)
+	AddLoginFailure(ctx context.Context, subjectType string, subject string,
+		windowStart time.Time) (*models.LoginFailure, error)
+	LockLogin(ctx context.Context, subjectType string, subject string, until time.Time) error
+	ResetLoginFailures(ctx context.Context, subjectType string, subject string) error
+	AddLockoutEvent(ctx context.Context, event *models.LoginLockoutEvent) error
+}
+
+func GetLoginAttemptRepository(log *log.Logger, connection database.Database) LoginAttemptRepositoryInterface {
+	return &LoginAttemptRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+func (r *LoginAttemptRepository) GetLoginFailure(ctx context.Context, subjectType string,
+	subject string) (*models.LoginFailure, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var failure models.LoginFailure
+
+	err := r.db.GetContext(ctx, &failure, "SELECT * FROM login_failures WHERE subject_type = $1 AND subject = $2",
+		subjectType, subject)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get login failure from db", err)
+
+		return nil, err
+	}
+
+	return &failure, nil
+}
+
+// AddLoginFailure counts a failed login. Failures before windowStart are forgotten.
+func (r *LoginAttemptRepository) AddLoginFailure(ctx context.Context, subjectType string, subject string,
+	windowStart time.Time) (*models.LoginFailure, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO login_failures (subject_type, subject, failures, last_failure_at)
+			  VALUES ($1, $2, 1, $3)
+			  ON CONFLICT (subject_type, subject) DO UPDATE
+			  SET failures = CASE WHEN login_failures.last_failure_at < $4 THEN 1
+			                      ELSE login_failures.failures + 1 END,
+			      last_failure_at = EXCLUDED.last_failure_at
+			  RETURNING *`
+
+	var failure models.LoginFailure
+	err := r.db.GetContext(ctx, &failure, query, subjectType, subject, time.Now().UTC(), windowStart)
+	if err != nil {
+		r.log.Errorln("failed to add login failure to db", err)
+
+		return nil, err
+	}
+
+	return &failure, nil
+}
+
+func (r *LoginAttemptRepository) LockLogin(ctx context.Context, subjectType string, subject string,
+	until time.Time) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE login_failures SET locked_until = $1 WHERE subject_type = $2 AND subject = $3",
+		until, subjectType, subject)
+	if err != nil {
+		r.log.Errorln("failed to lock login", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptRepository) ResetLoginFailures(ctx context.Context, subjectType string, subject string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM login_failures WHERE subject_type = $1 AND subject = $2",
+		subjectType, subject)
+	if err != nil {
+		r.log.Errorln("failed to reset login failures", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptRepository) AddLockoutEvent(ctx context.Context, event *models.LoginLockoutEvent) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	event.CreatedAt = time.Now().UTC()
+
+	insertQuery := `INSERT INTO login_lockout_events (subject_type, subject, event, ip, admin_id, created_at)
+					VALUES (:subject_type, :subject, :event, :ip, :admin_id, :created_at)`
+	_, err := r.db.NamedExecContext(ctx, insertQuery, event)
+	if err != nil {
+		r.log.Errorln("failed to insert lockout event into db", err)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type ApiKeyRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetApiKeyRepository(log *log.Logger, store *Store) repositories.ApiKeyRepositoryInterface {
+	return &ApiKeyRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *ApiKeyRepository) CreateApiKey(ctx context.Context, apiKey *models.ApiKey) (*models.ApiKey, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	apiKey.CreatedAt = time.Now().UTC()
+
+	newApiKey := *apiKey
+	newApiKey.Id = r.store.nextId("api_keys")
+	stored := newApiKey
+	r.store.apiKeys[newApiKey.Id] = &stored
+
+	return &newApiKey, nil
+}
+
+// GetApiKeysList returns the keys of the user that aren't revoked, the newest first
+func (r *ApiKeyRepository) GetApiKeysList(ctx context.Context, userId int) ([]*models.ApiKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	apiKeys := sortedById(r.store.apiKeys, func(apiKey *models.ApiKey) bool {
+		return apiKey.UserId == userId && apiKey.RevokedAt == nil
+	})
+	slices.Reverse(apiKeys)
+
+	return apiKeys, nil
+}
+
+func (r *ApiKeyRepository) GetApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, apiKey := range r.store.apiKeys {
+		if apiKey.KeyHash == keyHash {
+			found := *apiKey
+
+			return &found, nil
+		}
+	}
+
+	return nil, errors.NotFoundErr
+}
+
+func (r *ApiKeyRepository) TouchApiKey(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if apiKey, ok := r.store.apiKeys[id]; ok {
+		now := time.Now().UTC()
+		apiKey.LastUsedAt = &now
+	}
+
+	return nil
+}
+
+func (r *ApiKeyRepository) RevokeApiKey(ctx context.Context, id int, userId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	apiKey, ok := r.store.apiKeys[id]
+	if !ok || apiKey.UserId != userId || apiKey.RevokedAt != nil {
+		return errors.NotFoundErr
+	}
+	now := time.Now().UTC()
+	apiKey.RevokedAt = &now
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type IdempotencyRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetIdempotencyRepository(log *log.Logger, store *Store) repositories.IdempotencyRepositoryInterface {
+	return &IdempotencyRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *IdempotencyRepository) ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, stored := range r.store.idempotencyKeys {
+		if id.userId == key.UserId && stored.ExpiresAt.Before(key.CreatedAt) {
+			delete(r.store.idempotencyKeys, id)
+		}
+	}
+
+	id := idempotencyKeyId{userId: key.UserId, key: key.Key}
+	if _, ok := r.store.idempotencyKeys[id]; ok {
+		return false, nil
+	}
+	stored := *key
+	r.store.idempotencyKeys[id] = &stored
+
+	return true, nil
+}
+
+func (r *IdempotencyRepository) GetIdempotencyKey(ctx context.Context, userId int,
+	key string) (*models.IdempotencyKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stored, ok := r.store.idempotencyKeys[idempotencyKeyId{userId: userId, key: key}]
+	if !ok {
+		return nil, errors.NotFoundErr
+	}
+	found := *stored
+
+	return &found, nil
+}
+
+func (r *IdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.heldKey(key)
+	if !ok {
+		return errors.NotFoundErr
+	}
+	stored.Status = key.Status
+	stored.ContentType = key.ContentType
+	stored.ResponseBody = key.ResponseBody
+	stored.ExpiresAt = key.ExpiresAt
+
+	return nil
+}
+
+func (r *IdempotencyRepository) ExtendIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.heldKey(key)
+	if !ok {
+		return errors.NotFoundErr
+	}
+	stored.ExpiresAt = key.ExpiresAt
+
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.heldKey(key); ok {
+		delete(r.store.idempotencyKeys, idempotencyKeyId{userId: key.UserId, key: key.Key})
+	}
+
+	return nil
+}
+
+// heldKey returns the stored key if it is in progress with the lock token of key, the caller must hold the lock
+func (r *IdempotencyRepository) heldKey(key *models.IdempotencyKey) (*models.IdempotencyKey, bool) {
+	stored, ok := r.store.idempotencyKeys[idempotencyKeyId{userId: key.UserId, key: key.Key}]
+	if !ok || stored.LockToken != key.LockToken || stored.Status != nil {
+		return nil, false
+	}
+
+	return stored, true
+}
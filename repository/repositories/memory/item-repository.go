@@ -0,0 +1,299 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type ItemRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetItemRepository(log *log.Logger, store *Store) repositories.ItemRepositoryInterface {
+	return &ItemRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *ItemRepository) GetItemsList(ctx context.Context, filter models.ItemsFilter) ([]*models.Item, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.filterItems(filter), nil
+}
+
+func (r *ItemRepository) filterItems(filter models.ItemsFilter) []*models.Item {
+	items := sortedById(r.store.items, func(item *models.Item) bool {
+		return item.UserId == filter.UserId && (!filter.ActiveOnly || item.SoldPrice == nil) &&
+			(!filter.SoldOnly || item.SoldAt != nil)
+	})
+	if filter.Limit > 0 {
+		items = page(items, filter.Limit, filter.Offset)
+	}
+
+	return items
+}
+
+// StreamItems calls fn for copies of the items taken before the first call, fn runs without the lock
+func (r *ItemRepository) StreamItems(ctx context.Context, filter models.ItemsFilter,
+	fn func(item *models.Item) error) error {
+	r.store.mu.RLock()
+	items := r.filterItems(filter)
+	r.store.mu.RUnlock()
+
+	for _, item := range items {
+		err := fn(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateItems inserts all the items or none of them
+func (r *ItemRepository) CreateItems(ctx context.Context, items []*models.Item) ([]*models.Item, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, item := range items {
+		if _, ok := r.store.users[item.UserId]; !ok {
+			return nil, fmt.Errorf("user %d does not exist", item.UserId)
+		}
+	}
+
+	created := make([]*models.Item, 0, len(items))
+	for _, item := range items {
+		newItem := models.Item{
+			Id:           r.store.nextId("items"),
+			UserId:       item.UserId,
+			Title:        item.Title,
+			InitialPrice: item.InitialPrice,
+			Description:  item.Description,
+		}
+		stored := newItem
+		r.store.items[newItem.Id] = &stored
+		created = append(created, &newItem)
+	}
+
+	return created, nil
+}
+
+func (r *ItemRepository) CreateItem(ctx context.Context, srcItem *models.Item) (*models.Item, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[srcItem.UserId]; !ok {
+		r.log.Error("failed to insert srcItem, no user ", srcItem.UserId)
+
+		return nil, fmt.Errorf("user %d does not exist", srcItem.UserId)
+	}
+
+	newItem := models.Item{
+		Id:           r.store.nextId("items"),
+		UserId:       srcItem.UserId,
+		Title:        srcItem.Title,
+		InitialPrice: srcItem.InitialPrice,
+		Description:  srcItem.Description,
+	}
+	stored := newItem
+	r.store.items[newItem.Id] = &stored
+
+	// the same as ItemRepository of the database returns for a new item
+	var zero float64 = 0.0
+	newItem.SoldPrice = &zero
+
+	return &newItem, nil
+}
+
+func (r *ItemRepository) GetItemById(ctx context.Context, id int, userId int) (*models.Item, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	item, ok := r.store.items[id]
+	if !ok || item.UserId != userId {
+		return nil, sql.ErrNoRows
+	}
+	found := *item
+
+	return &found, nil
+}
+
+func (r *ItemRepository) UpdateItem(ctx context.Context, id int, srcItem *models.Item,
+	userId int) (*models.Item, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	item, ok := r.store.items[id]
+	if !ok || item.UserId != userId {
+		return nil, sql.ErrNoRows
+	}
+	item.Title = srcItem.Title
+	item.InitialPrice = srcItem.InitialPrice
+	item.Description = srcItem.Description
+
+	updatedItem := *item
+	if updatedItem.SoldPrice == nil {
+		var zero float64 = 0.0
+		updatedItem.SoldPrice = &zero
+	}
+
+	return &updatedItem, nil
+}
+
+func (r *ItemRepository) DeleteItem(ctx context.Context, id int, userId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	item, ok := r.store.items[id]
+	if !ok || item.UserId != userId {
+		r.log.Errorln("no item was deleted")
+
+		return errors.NotFoundErr
+	}
+	delete(r.store.items, id)
+	for commentId, comment := range r.store.itemComments {
+		if comment.ItemId == id {
+			delete(r.store.itemComments, commentId)
+		}
+	}
+	for questionId, question := range r.store.questions {
+		if question.ItemId == id {
+			delete(r.store.questions, questionId)
+		}
+	}
+	// reviews outlive the item like with ON DELETE SET NULL, conversations about it go away with their messages
+	for _, review := range r.store.reviews {
+		if review.ItemId != nil && *review.ItemId == id {
+			review.ItemId = nil
+		}
+	}
+	for conversationId, conversation := range r.store.conversations {
+		if conversation.ItemId == nil || *conversation.ItemId != id {
+			continue
+		}
+		delete(r.store.conversations, conversationId)
+		for messageId, message := range r.store.messages {
+			if message.ConversationId == conversationId {
+				delete(r.store.messages, messageId)
+			}
+		}
+	}
+	r.log.Infof("Item with id %d deleted", id)
+
+	return nil
+}
+
+// MarkItemSold records a pending sale of an unsold item of the user, it returns NotFoundErr otherwise
+func (r *ItemRepository) MarkItemSold(ctx context.Context, id int, userId int, buyerId int,
+	soldPrice float64) (*models.Item, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	item, ok := r.store.items[id]
+	if !ok || item.UserId != userId || item.SoldPrice != nil {
+		return nil, errors.NotFoundErr
+	}
+	item.SoldPrice = &soldPrice
+	item.BuyerId = &buyerId
+
+	soldItem := *item
+
+	return &soldItem, nil
+}
+
+// ConfirmItemSale completes the pending sale of the item to the buyer, it returns NotFoundErr otherwise
+func (r *ItemRepository) ConfirmItemSale(ctx context.Context, id int, buyerId int) (*models.Item, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	item, ok := r.store.items[id]
+	if !ok || item.BuyerId == nil || *item.BuyerId != buyerId || item.SoldAt != nil {
+		return nil, errors.NotFoundErr
+	}
+	now := time.Now().UTC()
+	item.SoldAt = &now
+
+	soldItem := *item
+
+	return &soldItem, nil
+}
+
+// DeclineItemSale drops the pending sale of the item to the buyer, the item is for sale again
+func (r *ItemRepository) DeclineItemSale(ctx context.Context, id int, buyerId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	item, ok := r.store.items[id]
+	if !ok || item.BuyerId == nil || *item.BuyerId != buyerId || item.SoldAt != nil {
+		return errors.NotFoundErr
+	}
+	item.SoldPrice = nil
+	item.BuyerId = nil
+
+	return nil
+}
+
+// GetItem returns an item of any user, GetItemById is limited to the items of one user
+func (r *ItemRepository) GetItem(ctx context.Context, id int) (*models.Item, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	item, ok := r.store.items[id]
+	if !ok {
+		return nil, errors.NotFoundErr
+	}
+	found := *item
+
+	return &found, nil
+}
+
+// GetSoldItem returns an item of any user if its sale was confirmed by the buyer
+func (r *ItemRepository) GetSoldItem(ctx context.Context, id int) (*models.Item, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	item, ok := r.store.items[id]
+	if !ok || item.SoldAt == nil {
+		return nil, errors.NotFoundErr
+	}
+	found := *item
+
+	return &found, nil
+}
+
+func (r *ItemRepository) GetAllItems(ctx context.Context) ([]*models.Item, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return sortedById(r.store.items, nil), nil
+}
+
+func (r *ItemRepository) CreateItemComment(ctx context.Context,
+	comment *models.ItemComment) (*models.ItemComment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.items[comment.ItemId]; !ok {
+		r.log.Errorf("comment: %+v\n", comment)
+
+		return nil, fmt.Errorf("failed to add a new comment")
+	}
+
+	comment.CreatedAt = time.Now()
+
+	newComment := *comment
+	newComment.Id = r.store.nextId("item_comments")
+	stored := newComment
+	r.store.itemComments[newComment.Id] = &stored
+
+	return &newComment, nil
+}
@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+func newTestStore(t *testing.T) (*Store, *log.Logger) {
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	return NewStore(), log.New(cfg)
+}
+
+func TestItemRepository(t *testing.T) {
+	ctx := context.Background()
+	store, logger := newTestStore(t)
+	users := GetUserRepository(logger, store)
+	repo := GetItemRepository(logger, store)
+
+	seller, err := users.CreateUser(ctx, &models.User{Email: "seller@example.com", UserTypeId: 1})
+	require.NoError(t, err)
+
+	_, err = repo.CreateItem(ctx, &models.Item{UserId: 99, Title: "Orphan"})
+	assert.Error(t, err)
+
+	for _, title := range []string{"Bike", "Lamp", "Desk"} {
+		_, err = repo.CreateItem(ctx, &models.Item{UserId: seller.Id, Title: title, InitialPrice: 10})
+		require.NoError(t, err)
+	}
+
+	t.Run("Sold item can't be sold again", func(t *testing.T) {
+		sold, err := repo.MarkItemSold(ctx, 2, seller.Id, 7, 15)
+		require.NoError(t, err)
+		assert.Equal(t, 15.0, *sold.SoldPrice)
+		assert.Nil(t, sold.SoldAt)
+
+		_, err = repo.MarkItemSold(ctx, 2, seller.Id, 8, 20)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+
+		_, err = repo.ConfirmItemSale(ctx, 2, 8)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+		sold, err = repo.ConfirmItemSale(ctx, 2, 7)
+		require.NoError(t, err)
+		assert.NotNil(t, sold.SoldAt)
+		assert.ErrorIs(t, repo.DeclineItemSale(ctx, 2, 7), apperrors.NotFoundErr)
+	})
+
+	t.Run("Declined sale puts the item back on sale", func(t *testing.T) {
+		_, err := repo.MarkItemSold(ctx, 3, seller.Id, 7, 15)
+		require.NoError(t, err)
+		require.NoError(t, repo.DeclineItemSale(ctx, 3, 7))
+
+		item, err := repo.GetItem(ctx, 3)
+		require.NoError(t, err)
+		assert.Nil(t, item.SoldPrice)
+		assert.Nil(t, item.BuyerId)
+	})
+
+	t.Run("Filter and paging", func(t *testing.T) {
+		items, err := repo.GetItemsList(ctx, models.ItemsFilter{UserId: seller.Id, ActiveOnly: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bike", "Desk"}, []string{items[0].Title, items[1].Title})
+
+		items, err = repo.GetItemsList(ctx, models.ItemsFilter{UserId: seller.Id, Limit: 2, Offset: 2})
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, "Desk", items[0].Title)
+
+		items, err = repo.GetItemsList(ctx, models.ItemsFilter{UserId: seller.Id, Limit: 2, Offset: 5})
+		require.NoError(t, err)
+		assert.Empty(t, items)
+	})
+
+	t.Run("Items of other users are hidden", func(t *testing.T) {
+		_, err := repo.GetItemById(ctx, 1, seller.Id+1)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.ErrorIs(t, repo.DeleteItem(ctx, 1, seller.Id+1), apperrors.NotFoundErr)
+	})
+
+	t.Run("Returned items are copies", func(t *testing.T) {
+		item, err := repo.GetItem(ctx, 1)
+		require.NoError(t, err)
+		item.Title = "Changed"
+
+		item, err = repo.GetItem(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Bike", item.Title)
+	})
+}
+
+func TestItemRepositoryDeleteItemWithConversation(t *testing.T) {
+	ctx := context.Background()
+	store, logger := newTestStore(t)
+	users := GetUserRepository(logger, store)
+	seller, err := users.CreateUser(ctx, &models.User{Email: "seller@example.com"})
+	require.NoError(t, err)
+	buyer, err := users.CreateUser(ctx, &models.User{Email: "buyer@example.com"})
+	require.NoError(t, err)
+	repo := GetItemRepository(logger, store)
+	item, err := repo.CreateItem(ctx, &models.Item{UserId: seller.Id, Title: "Bike"})
+	require.NoError(t, err)
+
+	messages := GetMessageRepository(logger, store)
+	aboutItem, err := messages.CreateConversation(ctx,
+		&models.Conversation{UserAId: seller.Id, UserBId: buyer.Id, ItemId: &item.Id})
+	require.NoError(t, err)
+	_, err = messages.CreateMessage(ctx, &models.Message{ConversationId: aboutItem.Id, SenderId: buyer.Id, Body: "Hi"})
+	require.NoError(t, err)
+	general, err := messages.CreateConversation(ctx, &models.Conversation{UserAId: seller.Id, UserBId: buyer.Id})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteItem(ctx, item.Id, seller.Id))
+
+	_, err = messages.GetConversation(ctx, aboutItem.Id)
+	assert.ErrorIs(t, err, apperrors.NotFoundErr)
+	left, err := messages.GetMessages(ctx, aboutItem.Id, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, left)
+	_, err = messages.GetConversation(ctx, general.Id)
+	assert.NoError(t, err)
+}
+
+func TestItemRepositoryConcurrentSale(t *testing.T) {
+	ctx := context.Background()
+	store, logger := newTestStore(t)
+	seller, err := GetUserRepository(logger, store).CreateUser(ctx, &models.User{Email: "seller@example.com"})
+	require.NoError(t, err)
+	repo := GetItemRepository(logger, store)
+	item, err := repo.CreateItem(ctx, &models.Item{UserId: seller.Id, Title: "Bike"})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sales := 0
+	for buyerId := 100; buyerId < 120; buyerId++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.MarkItemSold(ctx, item.Id, seller.Id, buyerId, 10)
+			if err == nil {
+				mu.Lock()
+				sales++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, sales)
+}
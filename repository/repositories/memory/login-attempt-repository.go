@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type LoginAttemptRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetLoginAttemptRepository(log *log.Logger, store *Store) repositories.LoginAttemptRepositoryInterface {
+	return &LoginAttemptRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *LoginAttemptRepository) GetLoginFailure(ctx context.Context, subjectType string,
+	subject string) (*models.LoginFailure, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	failure, ok := r.store.loginFailures[loginSubject{subjectType: subjectType, subject: subject}]
+	if !ok {
+		return nil, errors.NotFoundErr
+	}
+	found := *failure
+
+	return &found, nil
+}
+
+// AddLoginFailure counts a failed login. Failures before windowStart are forgotten.
+func (r *LoginAttemptRepository) AddLoginFailure(ctx context.Context, subjectType string, subject string,
+	windowStart time.Time) (*models.LoginFailure, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := loginSubject{subjectType: subjectType, subject: subject}
+	failure, ok := r.store.loginFailures[key]
+	if !ok {
+		failure = &models.LoginFailure{SubjectType: subjectType, Subject: subject}
+		r.store.loginFailures[key] = failure
+	}
+	if failure.LastFailureAt.Before(windowStart) {
+		failure.Failures = 0
+	}
+	failure.Failures++
+	failure.LastFailureAt = time.Now().UTC()
+
+	found := *failure
+
+	return &found, nil
+}
+
+func (r *LoginAttemptRepository) LockLogin(ctx context.Context, subjectType string, subject string,
+	until time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if failure, ok := r.store.loginFailures[loginSubject{subjectType: subjectType, subject: subject}]; ok {
+		failure.LockedUntil = &until
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptRepository) ResetLoginFailures(ctx context.Context, subjectType string, subject string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.loginFailures, loginSubject{subjectType: subjectType, subject: subject})
+
+	return nil
+}
+
+func (r *LoginAttemptRepository) AddLockoutEvent(ctx context.Context, event *models.LoginLockoutEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event.CreatedAt = time.Now().UTC()
+
+	newEvent := *event
+	newEvent.Id = r.store.nextId("login_lockout_events")
+	r.store.lockoutEvents = append(r.store.lockoutEvents, &newEvent)
+
+	return nil
+}
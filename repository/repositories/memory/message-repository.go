@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type MessageRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetMessageRepository(log *log.Logger, store *Store) repositories.MessageRepositoryInterface {
+	return &MessageRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *MessageRepository) GetConversation(ctx context.Context, id int) (*models.Conversation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	conversation, ok := r.store.conversations[id]
+	if !ok {
+		return nil, errors.NotFoundErr
+	}
+	found := *conversation
+
+	return &found, nil
+}
+
+// FindConversation expects userAId to be smaller than userBId, a nil itemId finds the conversation without an item
+func (r *MessageRepository) FindConversation(ctx context.Context, userAId int, userBId int,
+	itemId *int) (*models.Conversation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, conversation := range r.store.conversations {
+		if conversation.UserAId == userAId && conversation.UserBId == userBId &&
+			itemKey(conversation.ItemId) == itemKey(itemId) {
+			found := *conversation
+
+			return &found, nil
+		}
+	}
+
+	return nil, errors.NotFoundErr
+}
+
+// itemKey works like COALESCE(item_id, 0) in the unique index of the table
+func itemKey(itemId *int) int {
+	if itemId == nil {
+		return 0
+	}
+
+	return *itemId
+}
+
+func (r *MessageRepository) CreateConversation(ctx context.Context,
+	conversation *models.Conversation) (*models.Conversation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	newConversation := models.Conversation{
+		Id:            r.store.nextId("conversations"),
+		UserAId:       conversation.UserAId,
+		UserBId:       conversation.UserBId,
+		ItemId:        conversation.ItemId,
+		CreatedAt:     now,
+		LastMessageAt: now,
+	}
+	stored := newConversation
+	r.store.conversations[newConversation.Id] = &stored
+
+	return &newConversation, nil
+}
+
+// GetUserConversations returns the conversations of the user with the latest activity first
+func (r *MessageRepository) GetUserConversations(ctx context.Context,
+	userId int) ([]*models.ConversationSummary, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	conversations := sortedById(r.store.conversations, func(conversation *models.Conversation) bool {
+		return conversation.HasParticipant(userId)
+	})
+	slices.Reverse(conversations)
+	slices.SortStableFunc(conversations, func(a, b *models.Conversation) int {
+		return b.LastMessageAt.Compare(a.LastMessageAt)
+	})
+
+	summaries := make([]*models.ConversationSummary, 0, len(conversations))
+	for _, conversation := range conversations {
+		summary := &models.ConversationSummary{Conversation: *conversation}
+		for _, message := range r.store.messages {
+			if message.ConversationId == conversation.Id && message.SenderId != userId && message.ReadAt == nil {
+				summary.UnreadCount++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+func (r *MessageRepository) CreateMessage(ctx context.Context, message *models.Message) (*models.Message, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	conversation, ok := r.store.conversations[message.ConversationId]
+	if !ok {
+		return nil, errors.NotFoundErr
+	}
+
+	now := time.Now().UTC()
+	newMessage := models.Message{
+		Id:             r.store.nextId("messages"),
+		ConversationId: message.ConversationId,
+		SenderId:       message.SenderId,
+		Body:           message.Body,
+		CreatedAt:      now,
+	}
+	stored := newMessage
+	r.store.messages[newMessage.Id] = &stored
+	conversation.LastMessageAt = now
+
+	return &newMessage, nil
+}
+
+// GetMessages returns up to limit messages older than beforeId, the newest first. beforeId 0 starts from the latest.
+func (r *MessageRepository) GetMessages(ctx context.Context, conversationId int, beforeId int,
+	limit int) ([]*models.Message, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	messages := sortedById(r.store.messages, func(message *models.Message) bool {
+		return message.ConversationId == conversationId && (beforeId <= 0 || message.Id < beforeId)
+	})
+	slices.Reverse(messages)
+
+	return page(messages, limit, 0), nil
+}
+
+// MarkRead marks the messages the reader has received in the conversation as read and returns their number
+func (r *MessageRepository) MarkRead(ctx context.Context, conversationId int, readerId int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	marked := 0
+	for _, message := range r.store.messages {
+		if message.ConversationId == conversationId && message.SenderId != readerId && message.ReadAt == nil {
+			message.ReadAt = &now
+			marked++
+		}
+	}
+
+	return marked, nil
+}
+
+func (r *MessageRepository) GetUnreadCount(ctx context.Context, userId int) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, message := range r.store.messages {
+		conversation, ok := r.store.conversations[message.ConversationId]
+		if ok && conversation.HasParticipant(userId) && message.SenderId != userId && message.ReadAt == nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *MessageRepository) BlockUser(ctx context.Context, blockerId int, blockedId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := userBlockKey{blockerId: blockerId, blockedId: blockedId}
+	if _, ok := r.store.blocks[key]; !ok {
+		r.store.blocks[key] = &models.UserBlock{BlockerId: blockerId, BlockedId: blockedId, CreatedAt: time.Now().UTC()}
+	}
+
+	return nil
+}
+
+func (r *MessageRepository) UnblockUser(ctx context.Context, blockerId int, blockedId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := userBlockKey{blockerId: blockerId, blockedId: blockedId}
+	if _, ok := r.store.blocks[key]; !ok {
+		return errors.NotFoundErr
+	}
+	delete(r.store.blocks, key)
+
+	return nil
+}
+
+func (r *MessageRepository) IsBlocked(ctx context.Context, blockerId int, blockedId int) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.blocks[userBlockKey{blockerId: blockerId, blockedId: blockedId}]
+
+	return ok, nil
+}
+
+func (r *MessageRepository) GetBlockedUsers(ctx context.Context, blockerId int) ([]*models.UserBlock, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	blocks := make([]*models.UserBlock, 0)
+	for _, block := range r.store.blocks {
+		if block.BlockerId == blockerId {
+			found := *block
+			blocks = append(blocks, &found)
+		}
+	}
+	slices.SortFunc(blocks, func(a, b *models.UserBlock) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+
+		return a.BlockedId - b.BlockedId
+	})
+
+	return blocks, nil
+}
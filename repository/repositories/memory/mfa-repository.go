@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type MfaRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetMfaRepository(log *log.Logger, store *Store) repositories.MfaRepositoryInterface {
+	return &MfaRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+// SetMfaSecret stores a pending secret, it is used only after EnableMfa
+func (r *MfaRepository) SetMfaSecret(ctx context.Context, userId int, secret string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user, ok := r.store.users[userId]; ok && !user.MfaEnabled {
+		user.MfaSecret = &secret
+	}
+
+	return nil
+}
+
+func (r *MfaRepository) EnableMfa(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user, ok := r.store.users[userId]; ok {
+		user.MfaEnabled = true
+		user.MfaLastUsedStep = &step
+	}
+
+	codes := make(map[string]bool, len(recoveryCodeHashes))
+	for _, codeHash := range recoveryCodeHashes {
+		codes[codeHash] = false
+	}
+	r.store.recoveryCodes[userId] = codes
+
+	return nil
+}
+
+func (r *MfaRepository) DisableMfa(ctx context.Context, userId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user, ok := r.store.users[userId]; ok {
+		user.MfaEnabled = false
+		user.MfaSecret = nil
+		user.MfaLastUsedStep = nil
+	}
+	delete(r.store.recoveryCodes, userId)
+
+	return nil
+}
+
+// UseMfaStep returns false if a code of this or a later time step was already accepted
+func (r *MfaRepository) UseMfaStep(ctx context.Context, userId int, step int64) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userId]
+	if !ok || (user.MfaLastUsedStep != nil && *user.MfaLastUsedStep >= step) {
+		return false, nil
+	}
+	user.MfaLastUsedStep = &step
+
+	return true, nil
+}
+
+func (r *MfaRepository) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	used, ok := r.store.recoveryCodes[userId][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.store.recoveryCodes[userId][codeHash] = true
+
+	return true, nil
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type ProfileRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetProfileRepository(log *log.Logger, store *Store) repositories.ProfileRepositoryInterface {
+	return &ProfileRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+// GetUserProfile returns NotFoundErr for banned users as well, their profiles are hidden
+func (r *ProfileRepository) GetUserProfile(ctx context.Context, userId int) (*models.UserProfile, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[userId]
+	if !ok || user.IsBanned() {
+		return nil, errors.NotFoundErr
+	}
+
+	profile := &models.UserProfile{
+		Id:          user.Id,
+		DisplayName: repositories.DisplayName(user.FirstName, user.LastName),
+		MemberSince: user.CreatedAt,
+	}
+	for _, item := range r.store.items {
+		if item.UserId != userId {
+			continue
+		}
+		// a pending sale is neither sold nor an active listing
+		switch {
+		case item.SoldAt != nil:
+			profile.ItemsSold++
+		case item.SoldPrice == nil:
+			profile.ActiveListings++
+		}
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type QuestionRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetQuestionRepository(log *log.Logger, store *Store) repositories.QuestionRepositoryInterface {
+	return &QuestionRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *QuestionRepository) CreateQuestion(ctx context.Context,
+	question *models.ItemQuestion) (*models.ItemQuestion, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.items[question.ItemId]; !ok {
+		return nil, errors.NotFoundErr
+	}
+
+	newQuestion := models.ItemQuestion{
+		Id:        r.store.nextId("item_questions"),
+		ItemId:    question.ItemId,
+		AskerId:   question.AskerId,
+		Question:  question.Question,
+		CreatedAt: time.Now().UTC(),
+	}
+	stored := newQuestion
+	r.store.questions[newQuestion.Id] = &stored
+
+	return &newQuestion, nil
+}
+
+func (r *QuestionRepository) GetQuestionById(ctx context.Context, id int) (*models.ItemQuestion, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	question, ok := r.store.questions[id]
+	if !ok {
+		return nil, errors.NotFoundErr
+	}
+	found := *question
+
+	return &found, nil
+}
+
+// GetItemQuestions returns answered questions and the unanswered ones of the viewer,
+// includeUnanswered adds the unanswered questions of everybody
+func (r *QuestionRepository) GetItemQuestions(ctx context.Context, itemId int, viewerId int,
+	includeUnanswered bool) ([]*models.ItemQuestion, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	questions := sortedById(r.store.questions, func(question *models.ItemQuestion) bool {
+		return question.ItemId == itemId &&
+			(question.Answer != nil || question.AskerId == viewerId || includeUnanswered)
+	})
+	slices.SortStableFunc(questions, func(a, b *models.ItemQuestion) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return questions, nil
+}
+
+// AnswerQuestion returns NotFoundErr if the question is already answered
+func (r *QuestionRepository) AnswerQuestion(ctx context.Context, id int, answer string) (*models.ItemQuestion, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	question, ok := r.store.questions[id]
+	if !ok || question.Answer != nil {
+		return nil, errors.NotFoundErr
+	}
+	now := time.Now().UTC()
+	question.Answer = &answer
+	question.AnsweredAt = &now
+	answered := *question
+
+	return &answered, nil
+}
@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type ReviewRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetReviewRepository(log *log.Logger, store *Store) repositories.ReviewRepositoryInterface {
+	return &ReviewRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+// CreateReview returns ReviewExistsErr if the reviewer has already reviewed the sale
+func (r *ReviewRepository) CreateReview(ctx context.Context, review *models.Review) (*models.Review, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if review.ItemId != nil && r.hasReview(*review.ItemId, review.ReviewerId) {
+		return nil, errors.ReviewExistsErr
+	}
+
+	newReview := models.Review{
+		Id:         r.store.nextId("reviews"),
+		ItemId:     review.ItemId,
+		ReviewerId: review.ReviewerId,
+		RevieweeId: review.RevieweeId,
+		Rating:     review.Rating,
+		Review:     review.Review,
+		Status:     models.ReviewStatusPublished,
+		CreatedAt:  time.Now().UTC(),
+	}
+	stored := newReview
+	r.store.reviews[newReview.Id] = &stored
+
+	return &newReview, nil
+}
+
+func (r *ReviewRepository) GetReviewById(ctx context.Context, id int) (*models.Review, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	review, ok := r.store.reviews[id]
+	if !ok {
+		return nil, errors.NotFoundErr
+	}
+	found := *review
+
+	return &found, nil
+}
+
+func (r *ReviewRepository) HasReview(ctx context.Context, itemId int, reviewerId int) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.hasReview(itemId, reviewerId), nil
+}
+
+// hasReview works like the unique key of the table, the caller must hold the lock
+func (r *ReviewRepository) hasReview(itemId int, reviewerId int) bool {
+	for _, review := range r.store.reviews {
+		if review.ItemId != nil && *review.ItemId == itemId && review.ReviewerId == reviewerId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetUserReviews returns the visible reviews of the user, the newest first
+func (r *ReviewRepository) GetUserReviews(ctx context.Context, revieweeId int, limit int,
+	offset int) ([]*models.Review, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	reviews := r.visibleReviews(revieweeId)
+	slices.Reverse(reviews)
+
+	return page(reviews, limit, offset), nil
+}
+
+func (r *ReviewRepository) GetRatingSummary(ctx context.Context, revieweeId int) (*models.RatingSummary, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	reviews := r.visibleReviews(revieweeId)
+
+	summary := &models.RatingSummary{RatingsCount: len(reviews)}
+	if len(reviews) > 0 {
+		total := 0
+		for _, review := range reviews {
+			total += review.Rating
+		}
+		average := math.Round(float64(total)/float64(len(reviews))*100) / 100
+		summary.AverageRating = &average
+	}
+
+	return summary, nil
+}
+
+// visibleReviews returns the reviews of the user that aren't removed, the caller must hold the lock
+func (r *ReviewRepository) visibleReviews(revieweeId int) []*models.Review {
+	return sortedById(r.store.reviews, func(review *models.Review) bool {
+		return review.RevieweeId == revieweeId && review.Status != models.ReviewStatusRemoved
+	})
+}
+
+func (r *ReviewRepository) AddResponse(ctx context.Context, id int, response string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	review, ok := r.store.reviews[id]
+	if !ok || review.Response != nil {
+		return errors.NotFoundErr
+	}
+	now := time.Now().UTC()
+	review.Response = &response
+	review.RespondedAt = &now
+
+	return nil
+}
+
+func (r *ReviewRepository) DisputeReview(ctx context.Context, id int, reason string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	review, ok := r.store.reviews[id]
+	if !ok || review.Status != models.ReviewStatusPublished {
+		return errors.NotFoundErr
+	}
+	now := time.Now().UTC()
+	review.Status = models.ReviewStatusDisputed
+	review.DisputeReason = reason
+	review.DisputedAt = &now
+
+	return nil
+}
+
+func (r *ReviewRepository) ResolveDispute(ctx context.Context, id int, adminId int, status string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	review, ok := r.store.reviews[id]
+	if !ok || review.Status != models.ReviewStatusDisputed {
+		return errors.NotFoundErr
+	}
+	now := time.Now().UTC()
+	review.Status = status
+	review.ResolvedBy = &adminId
+	review.ResolvedAt = &now
+
+	return nil
+}
+
+// GetDisputedReviews returns the reviews waiting for an administrator, the longest waiting first
+func (r *ReviewRepository) GetDisputedReviews(ctx context.Context) ([]*models.Review, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	reviews := sortedById(r.store.reviews, func(review *models.Review) bool {
+		return review.Status == models.ReviewStatusDisputed
+	})
+	slices.SortStableFunc(reviews, func(a, b *models.Review) int {
+		return a.DisputedAt.Compare(*b.DisputedAt)
+	})
+
+	return reviews, nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+func TestReviewRepository(t *testing.T) {
+	ctx := context.Background()
+	store, logger := newTestStore(t)
+	users := GetUserRepository(logger, store)
+	items := GetItemRepository(logger, store)
+	repo := GetReviewRepository(logger, store)
+	questions := GetQuestionRepository(logger, store)
+
+	seller, err := users.CreateUser(ctx, &models.User{Email: "seller@example.com", UserTypeId: 1})
+	require.NoError(t, err)
+	item, err := items.CreateItem(ctx, &models.Item{UserId: seller.Id, Title: "Bike", InitialPrice: 10})
+	require.NoError(t, err)
+
+	for _, rating := range []int{4, 5} {
+		_, err = repo.CreateReview(ctx, &models.Review{ItemId: &item.Id, ReviewerId: rating, RevieweeId: seller.Id,
+			Rating: rating})
+		require.NoError(t, err)
+	}
+	_, err = repo.CreateReview(ctx, &models.Review{ItemId: &item.Id, ReviewerId: 4, RevieweeId: seller.Id, Rating: 1})
+	assert.ErrorIs(t, err, apperrors.ReviewExistsErr)
+
+	summary, err := repo.GetRatingSummary(ctx, seller.Id)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.RatingsCount)
+	assert.Equal(t, 4.5, *summary.AverageRating)
+
+	require.NoError(t, repo.DisputeReview(ctx, 1, "not a buyer"))
+	assert.ErrorIs(t, repo.DisputeReview(ctx, 1, "again"), apperrors.NotFoundErr)
+	require.NoError(t, repo.ResolveDispute(ctx, 1, 99, models.ReviewStatusRemoved))
+
+	reviews, err := repo.GetUserReviews(ctx, seller.Id, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, 2, reviews[0].Id)
+
+	t.Run("Deleted item keeps the reviews and drops the questions", func(t *testing.T) {
+		_, err := questions.CreateQuestion(ctx, &models.ItemQuestion{ItemId: item.Id, AskerId: 4, Question: "Red?"})
+		require.NoError(t, err)
+
+		require.NoError(t, items.DeleteItem(ctx, item.Id, seller.Id))
+
+		review, err := repo.GetReviewById(ctx, 2)
+		require.NoError(t, err)
+		assert.Nil(t, review.ItemId)
+		_, err = questions.GetQuestionById(ctx, 1)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+	})
+}
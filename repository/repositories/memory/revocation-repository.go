@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"time"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type RevocationRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetRevocationRepository(log *log.Logger, store *Store) repositories.RevocationRepositoryInterface {
+	return &RevocationRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *RevocationRepository) AddRevokedToken(ctx context.Context, jti string, userId int, expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.revokedTokens[jti]; !ok {
+		r.store.revokedTokens[jti] = revokedToken{userId: userId, expiresAt: expiresAt}
+	}
+
+	return nil
+}
+
+func (r *RevocationRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.revokedTokens[jti]
+
+	return ok, nil
+}
+
+func (r *RevocationRepository) DeleteExpiredRevokedTokens(ctx context.Context) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	for jti, token := range r.store.revokedTokens {
+		if token.expiresAt.Before(now) {
+			delete(r.store.revokedTokens, jti)
+		}
+	}
+
+	return nil
+}
+
+func (r *RevocationRepository) SetTokensValidAfter(ctx context.Context, userId int, validAfter time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user, ok := r.store.users[userId]; ok {
+		user.TokensValidAfter = &validAfter
+	}
+
+	return nil
+}
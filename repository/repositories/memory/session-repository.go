@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type SessionRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetSessionRepository(log *log.Logger, store *Store) repositories.SessionRepositoryInterface {
+	return &SessionRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *models.Session) (*models.Session, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	session.CreatedAt = now
+	session.LastUsedAt = now
+
+	newSession := models.Session{
+		Id:         r.store.nextId("sessions"),
+		UserId:     session.UserId,
+		Device:     session.Device,
+		UserAgent:  session.UserAgent,
+		Ip:         session.Ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+	stored := newSession
+	r.store.sessions[newSession.Id] = &stored
+
+	return &newSession, nil
+}
+
+func (r *SessionRepository) GetSessionById(ctx context.Context, id int) (*models.Session, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	session, ok := r.store.sessions[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	found := *session
+
+	return &found, nil
+}
+
+func (r *SessionRepository) GetActiveSessionsList(ctx context.Context, userId int) ([]*models.Session, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	sessions := sortedById(r.store.sessions, func(session *models.Session) bool {
+		return session.UserId == userId && session.RevokedAt == nil
+	})
+	slices.SortStableFunc(sessions, func(a, b *models.Session) int {
+		return b.LastUsedAt.Compare(a.LastUsedAt)
+	})
+
+	return sessions, nil
+}
+
+func (r *SessionRepository) TouchSession(ctx context.Context, id int, ip string, userAgent string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if session, ok := r.store.sessions[id]; ok {
+		session.LastUsedAt = time.Now().UTC()
+		session.Ip = ip
+		session.UserAgent = userAgent
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, id int, userId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	session, ok := r.store.sessions[id]
+	if !ok || session.UserId != userId || session.RevokedAt != nil {
+		return errors.NotFoundErr
+	}
+	now := time.Now().UTC()
+	session.RevokedAt = &now
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeOtherSessions(ctx context.Context, userId int, keepSessionId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.revokeSessions(func(session *models.Session) bool {
+		return session.UserId == userId && session.Id != keepSessionId
+	})
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllSessions(ctx context.Context, userId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.revokeSessions(func(session *models.Session) bool {
+		return session.UserId == userId
+	})
+
+	return nil
+}
+
+// revokeSessions revokes the active sessions matching the condition, the caller must hold the write lock
+func (r *SessionRepository) revokeSessions(matches func(session *models.Session) bool) {
+	now := time.Now().UTC()
+	for _, session := range r.store.sessions {
+		if session.RevokedAt == nil && matches(session) {
+			session.RevokedAt = &now
+		}
+	}
+}
+
+func (r *SessionRepository) AddRefreshToken(ctx context.Context, sessionId int, tokenHash string,
+	expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, token := range r.store.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return fmt.Errorf("refresh token already exists")
+		}
+	}
+
+	id := r.store.nextId("refresh_tokens")
+	r.store.refreshTokens[id] = &models.RefreshToken{
+		Id:        id,
+		SessionId: sessionId,
+		TokenHash: tokenHash,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt,
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, token := range r.store.refreshTokens {
+		if token.TokenHash == tokenHash {
+			found := *token
+
+			return &found, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// MarkRefreshTokenUsed returns false if the token has already been used,
+// so two concurrent refreshes with the same token can't both succeed.
+func (r *SessionRepository) MarkRefreshTokenUsed(ctx context.Context, id int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.refreshTokens[id]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now().UTC()
+	token.UsedAt = &now
+
+	return true, nil
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+// Store keeps the tables of the in-memory repositories. Repositories created from the same store
+// see each other's changes, e.g. a retired user type moves the users of UserRepository.
+// Everything is lost when the process stops.
+type Store struct {
+	mu sync.RWMutex
+
+	lastId map[string]int
+
+	userTypes               map[int]*models.UserType
+	users                   map[int]*models.User
+	userIdentities          map[userIdentity]int
+	emailVerificationTokens map[string]emailVerificationToken
+	items                   map[int]*models.Item
+	itemComments            map[int]*models.ItemComment
+	sessions                map[int]*models.Session
+	refreshTokens           map[int]*models.RefreshToken
+	revokedTokens           map[string]revokedToken
+	loginFailures           map[loginSubject]*models.LoginFailure
+	lockoutEvents           []*models.LoginLockoutEvent
+	idempotencyKeys         map[idempotencyKeyId]*models.IdempotencyKey
+	recoveryCodes           map[int]map[string]bool
+	apiKeys                 map[int]*models.ApiKey
+	moderationEvents        map[int]*models.UserModerationEvent
+	reviews                 map[int]*models.Review
+	conversations           map[int]*models.Conversation
+	messages                map[int]*models.Message
+	blocks                  map[userBlockKey]*models.UserBlock
+	questions               map[int]*models.ItemQuestion
+}
+
+type userIdentity struct {
+	issuer  string
+	subject string
+}
+
+type emailVerificationToken struct {
+	userId    int
+	expiresAt time.Time
+}
+
+type revokedToken struct {
+	userId    int
+	expiresAt time.Time
+}
+
+type idempotencyKeyId struct {
+	userId int
+	key    string
+}
+
+type userBlockKey struct {
+	blockerId int
+	blockedId int
+}
+
+type loginSubject struct {
+	subjectType string
+	subject     string
+}
+
+// NewStore returns a store with the user types created by the migrations
+func NewStore() *Store {
+	s := &Store{
+		lastId:                  map[string]int{},
+		userTypes:               map[int]*models.UserType{},
+		users:                   map[int]*models.User{},
+		userIdentities:          map[userIdentity]int{},
+		emailVerificationTokens: map[string]emailVerificationToken{},
+		items:                   map[int]*models.Item{},
+		itemComments:            map[int]*models.ItemComment{},
+		sessions:                map[int]*models.Session{},
+		refreshTokens:           map[int]*models.RefreshToken{},
+		revokedTokens:           map[string]revokedToken{},
+		loginFailures:           map[loginSubject]*models.LoginFailure{},
+		idempotencyKeys:         map[idempotencyKeyId]*models.IdempotencyKey{},
+		recoveryCodes:           map[int]map[string]bool{},
+		apiKeys:                 map[int]*models.ApiKey{},
+		moderationEvents:        map[int]*models.UserModerationEvent{},
+		reviews:                 map[int]*models.Review{},
+		conversations:           map[int]*models.Conversation{},
+		messages:                map[int]*models.Message{},
+		blocks:                  map[userBlockKey]*models.UserBlock{},
+		questions:               map[int]*models.ItemQuestion{},
+	}
+
+	for _, userType := range []models.UserType{
+		{TypeName: "Seller", TypeDescription: "Seller of items", TypeCode: models.SellerTypeCode,
+			SelfAssignable: true},
+		{TypeName: "Buyer", TypeDescription: "Buyer of items", TypeCode: models.BuyerTypeCode,
+			SelfAssignable: true},
+		{TypeName: "Administrator", TypeDescription: "Administrator of the marketplace",
+			TypeCode: models.AdminTypeCode},
+	} {
+		userType.Id = s.nextId("user_types")
+		s.userTypes[userType.Id] = &userType
+	}
+
+	return s
+}
+
+// nextId works like a SERIAL column, the caller must hold the write lock
+func (s *Store) nextId(table string) int {
+	s.lastId[table]++
+
+	return s.lastId[table]
+}
+
+// sortedById returns copies of the rows, so callers can't change the store without a lock
+func sortedById[T any](rows map[int]*T, keep func(row *T) bool) []*T {
+	ids := make([]int, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	result := []*T{}
+	for _, id := range ids {
+		if keep == nil || keep(rows[id]) {
+			row := *rows[id]
+			result = append(result, &row)
+		}
+	}
+
+	return result
+}
+
+// page works like LIMIT and OFFSET
+func page[T any](rows []*T, limit int, offset int) []*T {
+	start := min(offset, len(rows))
+
+	return rows[start:min(start+limit, len(rows))]
+}
@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"context"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// UnitOfWork runs fn right away. Every repository call is atomic on its own,
+// but the changes made before fn failed are not rolled back.
+type UnitOfWork struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetUnitOfWork(log *log.Logger, store *Store) repositories.UnitOfWorkInterface {
+	return &UnitOfWork{
+		log:   log,
+		store: store,
+	}
+}
+
+func (u *UnitOfWork) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
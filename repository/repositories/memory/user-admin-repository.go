@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// UserAdminRepository holds the queries of the admin user management
+type UserAdminRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetUserAdminRepository(log *log.Logger, store *Store) repositories.UserAdminRepositoryInterface {
+	return &UserAdminRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+// SearchUsers matches the query against the email and the full name and returns one page and the total count
+func (r *UserAdminRepository) SearchUsers(ctx context.Context, query string, limit int, offset int) ([]*models.User,
+	int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	users := sortedById(r.store.users, func(user *models.User) bool {
+		return strings.Contains(strings.ToLower(user.Email), query) ||
+			strings.Contains(strings.ToLower(user.FirstName+" "+user.LastName), query)
+	})
+
+	return page(users, limit, offset), len(users), nil
+}
+
+func (r *UserAdminRepository) GetUserById(ctx context.Context, id int) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, errors.NotFoundErr
+	}
+	found := *user
+
+	return &found, nil
+}
+
+func (r *UserAdminRepository) GetUserActivity(ctx context.Context, userId int) (*models.UserActivity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	activity := &models.UserActivity{}
+	for _, item := range r.store.items {
+		if item.UserId == userId {
+			activity.ItemsCount++
+		}
+	}
+	for _, comment := range r.store.itemComments {
+		if comment.UserId == userId {
+			activity.CommentsCount++
+		}
+	}
+	for _, session := range r.store.sessions {
+		if session.UserId == userId && session.RevokedAt == nil {
+			activity.ActiveSessionsCount++
+		}
+	}
+	for _, apiKey := range r.store.apiKeys {
+		if apiKey.UserId == userId && apiKey.RevokedAt == nil {
+			activity.ApiKeysCount++
+		}
+	}
+
+	return activity, nil
+}
+
+func (r *UserAdminRepository) SetUserStatus(ctx context.Context, userId int, status string, suspendedUntil *time.Time,
+	reason string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userId]
+	if !ok {
+		return errors.NotFoundErr
+	}
+	user.Status = status
+	user.SuspendedUntil = suspendedUntil
+	user.StatusReason = reason
+
+	return nil
+}
+
+func (r *UserAdminRepository) SetUserType(ctx context.Context, userId int, userTypeId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userId]
+	if !ok {
+		return errors.NotFoundErr
+	}
+	user.UserTypeId = int32(userTypeId)
+
+	return nil
+}
+
+func (r *UserAdminRepository) AddModerationEvent(ctx context.Context, event *models.UserModerationEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event.CreatedAt = time.Now().UTC()
+
+	stored := *event
+	stored.Id = r.store.nextId("user_moderation_events")
+	r.store.moderationEvents[stored.Id] = &stored
+
+	return nil
+}
+
+// GetModerationEvents returns the events of the user, the newest first
+func (r *UserAdminRepository) GetModerationEvents(ctx context.Context,
+	userId int) ([]*models.UserModerationEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	events := sortedById(r.store.moderationEvents, func(event *models.UserModerationEvent) bool {
+		return event.UserId == userId
+	})
+	slices.Reverse(events)
+
+	return events, nil
+}
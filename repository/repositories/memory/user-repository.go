@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type UserRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetUserRepository(log *log.Logger, store *Store) repositories.UserRepositoryInterface {
+	return &UserRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *UserRepository) GetUsersList(ctx context.Context) ([]*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return sortedById(r.store.users, nil), nil
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, srcUser *models.User) (*models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, user := range r.store.users {
+		if user.Email == srcUser.Email {
+			r.log.Errorln("failed to insert srcUser", srcUser.Email, "is taken")
+
+			return nil, fmt.Errorf("user with email %s already exists", srcUser.Email)
+		}
+	}
+
+	now := time.Now().UTC()
+	srcUser.LastLoginUtc = now
+
+	newUser := models.User{
+		Id:           r.store.nextId("users"),
+		FirstName:    srcUser.FirstName,
+		LastName:     srcUser.LastName,
+		Email:        srcUser.Email,
+		PasswordHash: srcUser.PasswordHash,
+		LastLoginUtc: now,
+		CreatedAt:    now,
+		UserTypeId:   srcUser.UserTypeId,
+		Status:       models.UserStatusActive,
+	}
+	stored := newUser
+	r.store.users[newUser.Id] = &stored
+
+	return &newUser, nil
+}
+
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) *models.User {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			found := *user
+
+			return &found
+		}
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetUserById(ctx context.Context, id int) *models.User {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil
+	}
+	found := *user
+
+	return &found
+}
+
+// GetUserByIdentity finds the user linked to an account at an external identity provider
+func (r *UserRepository) GetUserByIdentity(ctx context.Context, issuer string, subject string) *models.User {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	userId, ok := r.store.userIdentities[userIdentity{issuer: issuer, subject: subject}]
+	if !ok {
+		return nil
+	}
+	user, ok := r.store.users[userId]
+	if !ok {
+		return nil
+	}
+	found := *user
+
+	return &found
+}
+
+func (r *UserRepository) AddUserIdentity(ctx context.Context, userId int, issuer string, subject string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	identity := userIdentity{issuer: issuer, subject: subject}
+	if _, ok := r.store.userIdentities[identity]; ok {
+		return fmt.Errorf("identity %s of %s is already linked", subject, issuer)
+	}
+	r.store.userIdentities[identity] = userId
+
+	return nil
+}
+
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user, ok := r.store.users[userId]; ok {
+		user.PasswordHash = passwordHash
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetUserType(ctx context.Context, user *models.User) (*models.UserType, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	userType, ok := r.store.userTypes[int(user.UserTypeId)]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	found := *userType
+
+	return &found, nil
+}
+
+func (r *UserRepository) AddEmailVerificationToken(ctx context.Context, userId int, tokenHash string,
+	expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.emailVerificationTokens[tokenHash] = emailVerificationToken{userId: userId, expiresAt: expiresAt}
+
+	return nil
+}
+
+func (r *UserRepository) GetUserByEmailVerificationToken(ctx context.Context, tokenHash string) *models.User {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	token, ok := r.store.emailVerificationTokens[tokenHash]
+	if !ok || !token.expiresAt.After(time.Now().UTC()) {
+		return nil
+	}
+	user, ok := r.store.users[token.userId]
+	if !ok {
+		return nil
+	}
+	found := *user
+
+	return &found
+}
+
+func (r *UserRepository) SetEmailVerified(ctx context.Context, userId int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user, ok := r.store.users[userId]; ok {
+		user.EmailVerified = true
+	}
+
+	for tokenHash, token := range r.store.emailVerificationTokens {
+		if token.userId == userId {
+			delete(r.store.emailVerificationTokens, tokenHash)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type UserTypeRepository struct {
+	log   *log.Logger
+	store *Store
+}
+
+func GetUserTypeRepository(log *log.Logger, store *Store) repositories.UserTypeRepositoryInterface {
+	return &UserTypeRepository{
+		log:   log,
+		store: store,
+	}
+}
+
+func (r *UserTypeRepository) GetUserTypesList(ctx context.Context) ([]*models.UserType, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return sortedById(r.store.userTypes, nil), nil
+}
+
+func (r *UserTypeRepository) GetUserTypeById(ctx context.Context, id int) (*models.UserType, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	userType, ok := r.store.userTypes[id]
+	if !ok {
+		return nil, errors.NotFoundErr
+	}
+	found := *userType
+
+	return &found, nil
+}
+
+func (r *UserTypeRepository) GetUserTypeByCode(ctx context.Context, typeCode string) (*models.UserType, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, userType := range r.store.userTypes {
+		if userType.TypeCode == typeCode {
+			found := *userType
+
+			return &found, nil
+		}
+	}
+
+	return nil, errors.NotFoundErr
+}
+
+func (r *UserTypeRepository) CreateUserType(ctx context.Context, userType *models.UserType) (*models.UserType, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	newUserType := models.UserType{
+		Id:              r.store.nextId("user_types"),
+		TypeName:        userType.TypeName,
+		TypeDescription: userType.TypeDescription,
+		TypeCode:        userType.TypeCode,
+		MfaRequired:     userType.MfaRequired,
+		SelfAssignable:  userType.SelfAssignable,
+	}
+	stored := newUserType
+	r.store.userTypes[newUserType.Id] = &stored
+
+	return &newUserType, nil
+}
+
+func (r *UserTypeRepository) UpdateUserTypeDescription(ctx context.Context, id int, description string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	userType, ok := r.store.userTypes[id]
+	if !ok {
+		return errors.NotFoundErr
+	}
+	userType.TypeDescription = description
+
+	return nil
+}
+
+// RetireUserType moves the users of the type to the replacement and marks the type as retired,
+// it returns the number of moved users
+func (r *UserTypeRepository) RetireUserType(ctx context.Context, id int, replacementId int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	userType, ok := r.store.userTypes[id]
+	if !ok || userType.RetiredAt != nil {
+		return 0, errors.NotFoundErr
+	}
+	now := time.Now().UTC()
+	userType.RetiredAt = &now
+
+	moved := 0
+	for _, user := range r.store.users {
+		if int(user.UserTypeId) == id {
+			user.UserTypeId = int32(replacementId)
+			moved++
+		}
+	}
+
+	return moved, nil
+}
+
+func (r *UserTypeRepository) SetMfaRequired(ctx context.Context, id int, required bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	userType, ok := r.store.userTypes[id]
+	if !ok {
+		return errors.NotFoundErr
+	}
+	userType.MfaRequired = required
+
+	return nil
+}
+
+func (r *UserTypeRepository) SetSelfAssignable(ctx context.Context, id int, assignable bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	userType, ok := r.store.userTypes[id]
+	if !ok {
+		return errors.NotFoundErr
+	}
+	userType.SelfAssignable = assignable
+
+	return nil
+}
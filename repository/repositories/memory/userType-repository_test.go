@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+func TestUserTypeRepository(t *testing.T) {
+	ctx := context.Background()
+	store, logger := newTestStore(t)
+	users := GetUserRepository(logger, store)
+	repo := GetUserTypeRepository(logger, store)
+
+	userTypes, err := repo.GetUserTypesList(ctx)
+	require.NoError(t, err)
+	require.Len(t, userTypes, 3)
+	assert.Equal(t, models.SellerTypeCode, userTypes[0].TypeCode)
+
+	partner, err := repo.CreateUserType(ctx, &models.UserType{TypeName: "Partner", TypeCode: "PARTNER"})
+	require.NoError(t, err)
+	found, err := repo.GetUserTypeByCode(ctx, "PARTNER")
+	require.NoError(t, err)
+	assert.Equal(t, partner.Id, found.Id)
+
+	user, err := users.CreateUser(ctx, &models.User{Email: "partner@example.com", UserTypeId: int32(partner.Id)})
+	require.NoError(t, err)
+
+	moved, err := repo.RetireUserType(ctx, partner.Id, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	assert.Equal(t, int32(1), users.GetUserById(ctx, user.Id).UserTypeId)
+
+	retired, err := repo.GetUserTypeById(ctx, partner.Id)
+	require.NoError(t, err)
+	assert.NotNil(t, retired.RetiredAt)
+
+	_, err = repo.RetireUserType(ctx, partner.Id, 1)
+	assert.ErrorIs(t, err, apperrors.NotFoundErr)
+	_, err = repo.GetUserTypeById(ctx, 99)
+	assert.ErrorIs(t, err, apperrors.NotFoundErr)
+	assert.ErrorIs(t, repo.SetMfaRequired(ctx, 99, true), apperrors.NotFoundErr)
+}
@@ -0,0 +1,299 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"fmt"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type MessageRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type MessageRepositoryInterface interface {
+	GetConversation(ctx context.Context, id int) (*models.Conversation, error)
+	FindConversation(ctx context.Context, userAId int, userBId int, itemId *int) (*models.Conversation, error)
+	CreateConversation(ctx context.Context, conversation *models.Conversation) (*models.Conversation, error)
+	GetUserConversations(ctx context.Context, userId int) ([]*models.ConversationSummary, error)
+	CreateMessage(ctx context.Context, message *models.Message) (*models.Message, error)
+	GetMessages(ctx context.Context, conversationId int, beforeId int, limit int) ([]*models.Message, error)
+	MarkRead(ctx context.Context, conversationId int, readerId int) (int, error)
+	GetUnreadCount(ctx context.Context, userId int) (int, error)
+	BlockUser(ctx context.Context, blockerId int, blockedId int) error
+	UnblockUser(ctx context.Context, blockerId int, blockedId int) error
+	IsBlocked(ctx context.Context, blockerId int, blockedId int) (bool, error)
+	GetBlockedUsers(ctx context.Context, blockerId int) ([]*models.UserBlock, error)
+}
+
+func GetMessageRepository(log *log.Logger, connection database.Database) MessageRepositoryInterface {
+	return &MessageRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+func (r *MessageRepository) GetConversation(ctx context.Context, id int) (*models.Conversation, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var conversation models.Conversation
+
+	err := r.db.GetContext(ctx, &conversation, "SELECT * FROM conversations WHERE id = $1", id)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get conversation", err)
+
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+// FindConversation expects userAId to be smaller than userBId, a nil itemId finds the conversation without an item
+func (r *MessageRepository) FindConversation(ctx context.Context, userAId int, userBId int,
+	itemId *int) (*models.Conversation, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var conversation models.Conversation
+
+	itemKey := 0
+	if itemId != nil {
+		itemKey = *itemId
+	}
+
+	err := r.db.GetContext(ctx, &conversation, `SELECT * FROM conversations
+									WHERE user_a_id = $1 AND user_b_id = $2 AND COALESCE(item_id, 0) = $3`,
+		userAId, userBId, itemKey)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to find conversation", err)
+
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+func (r *MessageRepository) CreateConversation(ctx context.Context,
+	conversation *models.Conversation) (*models.Conversation, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var newConversation models.Conversation
+
+	err := r.db.GetContext(ctx, &newConversation, `INSERT INTO conversations (user_a_id, user_b_id, item_id)
+									   VALUES ($1, $2, $3) RETURNING *`,
+		conversation.UserAId, conversation.UserBId, conversation.ItemId)
+	if err != nil {
+		r.log.Errorln("failed to insert conversation", err)
+
+		return nil, err
+	}
+
+	return &newConversation, nil
+}
+
+// GetUserConversations returns the conversations of the user with the latest activity first
+func (r *MessageRepository) GetUserConversations(ctx context.Context,
+	userId int) ([]*models.ConversationSummary, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var conversations []*models.ConversationSummary
+
+	err := r.db.SelectContext(ctx, &conversations, `SELECT c.*,
+										  (SELECT COUNT(*) FROM messages m
+										   WHERE m.conversation_id = c.id AND m.sender_id <> $1 AND m.read_at IS NULL)
+										   AS unread_count
+										FROM conversations c
+										WHERE c.user_a_id = $1 OR c.user_b_id = $1
+										ORDER BY c.last_message_at DESC, c.id DESC`, userId)
+	if err != nil {
+		r.log.Errorln("failed to get conversations", err)
+
+		return nil, err
+	}
+
+	return conversations, nil
+}
+
+func (r *MessageRepository) CreateMessage(ctx context.Context, message *models.Message) (*models.Message, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+
+	var newMessage models.Message
+	err := r.db.InTransaction(ctx, func(ctx context.Context) error {
+		err := r.db.GetContext(ctx, &newMessage, `INSERT INTO messages (conversation_id, sender_id, body, created_at)
+								   VALUES ($1, $2, $3, $4) RETURNING *`,
+			message.ConversationId, message.SenderId, message.Body, now)
+		if err != nil {
+			r.log.Errorln("failed to insert message", err)
+
+			return err
+		}
+
+		_, err = r.db.ExecContext(ctx, "UPDATE conversations SET last_message_at = $1 WHERE id = $2", now,
+			message.ConversationId)
+		if err != nil {
+			r.log.Errorln("failed to update conversation", err)
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newMessage, nil
+}
+
+// GetMessages returns up to limit messages older than beforeId, the newest first. beforeId 0 starts from the latest.
+func (r *MessageRepository) GetMessages(ctx context.Context, conversationId int, beforeId int,
+	limit int) ([]*models.Message, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var messages []*models.Message
+
+	query := "SELECT * FROM messages WHERE conversation_id = $1"
+	args := []any{conversationId}
+	if beforeId > 0 {
+		query += " AND id < $2"
+		args = append(args, beforeId)
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	err := r.db.SelectContext(ctx, &messages, query, args...)
+	if err != nil {
+		r.log.Errorln("failed to get messages", err)
+
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// MarkRead marks the messages the reader has received in the conversation as read and returns their number
+func (r *MessageRepository) MarkRead(ctx context.Context, conversationId int, readerId int) (int, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE messages SET read_at = $1
+							  WHERE conversation_id = $2 AND sender_id <> $3 AND read_at IS NULL`,
+		time.Now().UTC(), conversationId, readerId)
+	if err != nil {
+		r.log.Errorln("failed to mark messages as read", err)
+
+		return 0, err
+	}
+
+	marked, err := result.RowsAffected()
+	if err != nil {
+		r.log.Errorln("error checking rows affected", err)
+
+		return 0, err
+	}
+
+	return int(marked), nil
+}
+
+func (r *MessageRepository) GetUnreadCount(ctx context.Context, userId int) (int, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var count int
+
+	err := r.db.GetContext(ctx, &count,
+		`SELECT COUNT(*) FROM messages m JOIN conversations c ON c.id = m.conversation_id
+							 WHERE (c.user_a_id = $1 OR c.user_b_id = $1) AND m.sender_id <> $1 AND m.read_at IS NULL`,
+		userId)
+	if err != nil {
+		r.log.Errorln("failed to count unread messages", err)
+
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *MessageRepository) BlockUser(ctx context.Context, blockerId int, blockedId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2)
+						 ON CONFLICT DO NOTHING`, blockerId, blockedId)
+	if err != nil {
+		r.log.Errorln("failed to block user", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MessageRepository) UnblockUser(ctx context.Context, blockerId int, blockedId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2", blockerId,
+		blockedId)
+	if err != nil {
+		r.log.Errorln("failed to unblock user", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+func (r *MessageRepository) IsBlocked(ctx context.Context, blockerId int, blockedId int) (bool, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var blocked bool
+
+	err := r.db.GetContext(ctx, &blocked,
+		"SELECT EXISTS (SELECT 1 FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2)",
+		blockerId, blockedId)
+	if err != nil {
+		r.log.Errorln("failed to check user block", err)
+
+		return false, err
+	}
+
+	return blocked, nil
+}
+
+func (r *MessageRepository) GetBlockedUsers(ctx context.Context, blockerId int) ([]*models.UserBlock, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var blocks []*models.UserBlock
+
+	err := r.db.SelectContext(ctx, &blocks, "SELECT * FROM user_blocks WHERE blocker_id = $1 ORDER BY created_at DESC",
+		blockerId)
+	if err != nil {
+		r.log.Errorln("failed to get blocked users", err)
+
+		return nil, err
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/log"
+)
+
+type MfaRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type MfaRepositoryInterface interface {
+	SetMfaSecret(ctx context.Context, userId int, secret string) error
+	EnableMfa(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error
+	DisableMfa(ctx context.Context, userId int) error
+	UseMfaStep(ctx context.Context, userId int, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error)
+}
+
+func GetMfaRepository(log *log.Logger, connection database.Database) MfaRepositoryInterface {
+	return &MfaRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+// SetMfaSecret stores a pending secret, it is used only after EnableMfa
+func (r *MfaRepository) SetMfaSecret(ctx context.Context, userId int, secret string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET mfa_secret = $1 WHERE id = $2 AND mfa_enabled = FALSE", secret,
+		userId)
+	if err != nil {
+		r.log.Errorln("failed to set mfa secret", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MfaRepository) EnableMfa(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	return r.db.InTransaction(ctx, func(ctx context.Context) error {
+		_, err := r.db.ExecContext(ctx, "UPDATE users SET mfa_enabled = TRUE, mfa_last_used_step = $1 WHERE id = $2",
+			step, userId)
+		if err != nil {
+			r.log.Errorln("failed to enable mfa", err)
+
+			return err
+		}
+
+		_, err = r.db.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userId)
+		if err != nil {
+			r.log.Errorln("failed to delete recovery codes", err)
+
+			return err
+		}
+
+		for _, codeHash := range recoveryCodeHashes {
+			_, err = r.db.ExecContext(ctx, "INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)",
+				userId, codeHash)
+			if err != nil {
+				r.log.Errorln("failed to insert recovery code", err)
+
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *MfaRepository) DisableMfa(ctx context.Context, userId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET mfa_enabled = FALSE, mfa_secret = NULL, mfa_last_used_step = NULL
+						 WHERE id = $1`, userId)
+	if err != nil {
+		r.log.Errorln("failed to disable mfa", err)
+
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userId)
+	if err != nil {
+		r.log.Errorln("failed to delete recovery codes", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// UseMfaStep returns false if a code of this or a later time step was already accepted
+func (r *MfaRepository) UseMfaStep(ctx context.Context, userId int, step int64) (bool, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET mfa_last_used_step = $1
+							  WHERE id = $2 AND (mfa_last_used_step IS NULL OR mfa_last_used_step < $1)`,
+		step, userId)
+	if err != nil {
+		r.log.Errorln("failed to update mfa step", err)
+
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log.Errorln("error checking rows affected", err)
+
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *MfaRepository) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE mfa_recovery_codes SET used_at = $1
+							  WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL`,
+		time.Now().UTC(), userId, codeHash)
+	if err != nil {
+		r.log.Errorln("failed to use recovery code", err)
+
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log.Errorln("error checking rows affected", err)
+
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
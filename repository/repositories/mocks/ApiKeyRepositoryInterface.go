@@ -0,0 +1,155 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "ypeskov/go_hillel_9/repository/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ApiKeyRepositoryInterface is an autogenerated mock type for the ApiKeyRepositoryInterface type
+type ApiKeyRepositoryInterface struct {
+	mock.Mock
+}
+
+// CreateApiKey provides a mock function with given fields: ctx, apiKey
+func (_m *ApiKeyRepositoryInterface) CreateApiKey(ctx context.Context, apiKey *models.ApiKey) (*models.ApiKey, error) {
+	ret := _m.Called(ctx, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateApiKey")
+	}
+
+	var r0 *models.ApiKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ApiKey) (*models.ApiKey, error)); ok {
+		return rf(ctx, apiKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ApiKey) *models.ApiKey); ok {
+		r0 = rf(ctx, apiKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ApiKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ApiKey) error); ok {
+		r1 = rf(ctx, apiKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApiKeyByHash provides a mock function with given fields: ctx, keyHash
+func (_m *ApiKeyRepositoryInterface) GetApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error) {
+	ret := _m.Called(ctx, keyHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetApiKeyByHash")
+	}
+
+	var r0 *models.ApiKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.ApiKey, error)); ok {
+		return rf(ctx, keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.ApiKey); ok {
+		r0 = rf(ctx, keyHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ApiKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, keyHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApiKeysList provides a mock function with given fields: ctx, userId
+func (_m *ApiKeyRepositoryInterface) GetApiKeysList(ctx context.Context, userId int) ([]*models.ApiKey, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetApiKeysList")
+	}
+
+	var r0 []*models.ApiKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*models.ApiKey, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.ApiKey); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ApiKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeApiKey provides a mock function with given fields: ctx, id, userId
+func (_m *ApiKeyRepositoryInterface) RevokeApiKey(ctx context.Context, id int, userId int) error {
+	ret := _m.Called(ctx, id, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeApiKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, id, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TouchApiKey provides a mock function with given fields: ctx, id
+func (_m *ApiKeyRepositoryInterface) TouchApiKey(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchApiKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewApiKeyRepositoryInterface creates a new instance of ApiKeyRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewApiKeyRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ApiKeyRepositoryInterface {
+	mock := &ApiKeyRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,141 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "ypeskov/go_hillel_9/repository/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IdempotencyRepositoryInterface is an autogenerated mock type for the IdempotencyRepositoryInterface type
+type IdempotencyRepositoryInterface struct {
+	mock.Mock
+}
+
+// CompleteIdempotencyKey provides a mock function with given fields: ctx, key
+func (_m *IdempotencyRepositoryInterface) CompleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteIdempotencyKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IdempotencyKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteIdempotencyKey provides a mock function with given fields: ctx, key
+func (_m *IdempotencyRepositoryInterface) DeleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteIdempotencyKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IdempotencyKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExtendIdempotencyKey provides a mock function with given fields: ctx, key
+func (_m *IdempotencyRepositoryInterface) ExtendIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExtendIdempotencyKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IdempotencyKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetIdempotencyKey provides a mock function with given fields: ctx, userId, key
+func (_m *IdempotencyRepositoryInterface) GetIdempotencyKey(ctx context.Context, userId int, key string) (*models.IdempotencyKey, error) {
+	ret := _m.Called(ctx, userId, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdempotencyKey")
+	}
+
+	var r0 *models.IdempotencyKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (*models.IdempotencyKey, error)); ok {
+		return rf(ctx, userId, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) *models.IdempotencyKey); ok {
+		r0 = rf(ctx, userId, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.IdempotencyKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userId, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReserveIdempotencyKey provides a mock function with given fields: ctx, key
+func (_m *IdempotencyRepositoryInterface) ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReserveIdempotencyKey")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IdempotencyKey) (bool, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IdempotencyKey) bool); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.IdempotencyKey) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIdempotencyRepositoryInterface creates a new instance of IdempotencyRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIdempotencyRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IdempotencyRepositoryInterface {
+	mock := &IdempotencyRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -1,8 +1,9 @@
-// Code generated by mockery v2.43.1. DO NOT EDIT.
+// Code generated by mockery v2.53.7. DO NOT EDIT.
 
 package mocks
 
 import (
+	context "context"
 	models "ypeskov/go_hillel_9/repository/models"
 
 	mock "github.com/stretchr/testify/mock"
@@ -13,9 +14,39 @@ type ItemRepositoryInterface struct {
 	mock.Mock
 }
 
-// CreateItem provides a mock function with given fields: srcItem
-func (_m *ItemRepositoryInterface) CreateItem(srcItem *models.Item) (*models.Item, error) {
-	ret := _m.Called(srcItem)
+// ConfirmItemSale provides a mock function with given fields: ctx, id, buyerId
+func (_m *ItemRepositoryInterface) ConfirmItemSale(ctx context.Context, id int, buyerId int) (*models.Item, error) {
+	ret := _m.Called(ctx, id, buyerId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmItemSale")
+	}
+
+	var r0 *models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*models.Item, error)); ok {
+		return rf(ctx, id, buyerId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *models.Item); ok {
+		r0 = rf(ctx, id, buyerId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, id, buyerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateItem provides a mock function with given fields: ctx, srcItem
+func (_m *ItemRepositoryInterface) CreateItem(ctx context.Context, srcItem *models.Item) (*models.Item, error) {
+	ret := _m.Called(ctx, srcItem)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateItem")
@@ -23,19 +54,19 @@ func (_m *ItemRepositoryInterface) CreateItem(srcItem *models.Item) (*models.Ite
 
 	var r0 *models.Item
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*models.Item) (*models.Item, error)); ok {
-		return rf(srcItem)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Item) (*models.Item, error)); ok {
+		return rf(ctx, srcItem)
 	}
-	if rf, ok := ret.Get(0).(func(*models.Item) *models.Item); ok {
-		r0 = rf(srcItem)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Item) *models.Item); ok {
+		r0 = rf(ctx, srcItem)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Item)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*models.Item) error); ok {
-		r1 = rf(srcItem)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Item) error); ok {
+		r1 = rf(ctx, srcItem)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -43,17 +74,95 @@ func (_m *ItemRepositoryInterface) CreateItem(srcItem *models.Item) (*models.Ite
 	return r0, r1
 }
 
-// DeleteItem provides a mock function with given fields: id, userId
-func (_m *ItemRepositoryInterface) DeleteItem(id int, userId int) error {
-	ret := _m.Called(id, userId)
+// CreateItemComment provides a mock function with given fields: ctx, comment
+func (_m *ItemRepositoryInterface) CreateItemComment(ctx context.Context, comment *models.ItemComment) (*models.ItemComment, error) {
+	ret := _m.Called(ctx, comment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateItemComment")
+	}
+
+	var r0 *models.ItemComment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ItemComment) (*models.ItemComment, error)); ok {
+		return rf(ctx, comment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ItemComment) *models.ItemComment); ok {
+		r0 = rf(ctx, comment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ItemComment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ItemComment) error); ok {
+		r1 = rf(ctx, comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateItems provides a mock function with given fields: ctx, items
+func (_m *ItemRepositoryInterface) CreateItems(ctx context.Context, items []*models.Item) ([]*models.Item, error) {
+	ret := _m.Called(ctx, items)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateItems")
+	}
+
+	var r0 []*models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*models.Item) ([]*models.Item, error)); ok {
+		return rf(ctx, items)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*models.Item) []*models.Item); ok {
+		r0 = rf(ctx, items)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*models.Item) error); ok {
+		r1 = rf(ctx, items)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeclineItemSale provides a mock function with given fields: ctx, id, buyerId
+func (_m *ItemRepositoryInterface) DeclineItemSale(ctx context.Context, id int, buyerId int) error {
+	ret := _m.Called(ctx, id, buyerId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeclineItemSale")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, id, buyerId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteItem provides a mock function with given fields: ctx, id, userId
+func (_m *ItemRepositoryInterface) DeleteItem(ctx context.Context, id int, userId int) error {
+	ret := _m.Called(ctx, id, userId)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteItem")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int, int) error); ok {
-		r0 = rf(id, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, id, userId)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -61,9 +170,69 @@ func (_m *ItemRepositoryInterface) DeleteItem(id int, userId int) error {
 	return r0
 }
 
-// GetItemById provides a mock function with given fields: id, userId
-func (_m *ItemRepositoryInterface) GetItemById(id int, userId int) (*models.Item, error) {
-	ret := _m.Called(id, userId)
+// GetAllItems provides a mock function with given fields: ctx
+func (_m *ItemRepositoryInterface) GetAllItems(ctx context.Context) ([]*models.Item, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllItems")
+	}
+
+	var r0 []*models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.Item, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.Item); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetItem provides a mock function with given fields: ctx, id
+func (_m *ItemRepositoryInterface) GetItem(ctx context.Context, id int) (*models.Item, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetItem")
+	}
+
+	var r0 *models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Item, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Item); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetItemById provides a mock function with given fields: ctx, id, userId
+func (_m *ItemRepositoryInterface) GetItemById(ctx context.Context, id int, userId int) (*models.Item, error) {
+	ret := _m.Called(ctx, id, userId)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetItemById")
@@ -71,19 +240,19 @@ func (_m *ItemRepositoryInterface) GetItemById(id int, userId int) (*models.Item
 
 	var r0 *models.Item
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int, int) (*models.Item, error)); ok {
-		return rf(id, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*models.Item, error)); ok {
+		return rf(ctx, id, userId)
 	}
-	if rf, ok := ret.Get(0).(func(int, int) *models.Item); ok {
-		r0 = rf(id, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *models.Item); ok {
+		r0 = rf(ctx, id, userId)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Item)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int, int) error); ok {
-		r1 = rf(id, userId)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, id, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -91,9 +260,9 @@ func (_m *ItemRepositoryInterface) GetItemById(id int, userId int) (*models.Item
 	return r0, r1
 }
 
-// GetItemsList provides a mock function with given fields: userId
-func (_m *ItemRepositoryInterface) GetItemsList(userId int) ([]*models.Item, error) {
-	ret := _m.Called(userId)
+// GetItemsList provides a mock function with given fields: ctx, filter
+func (_m *ItemRepositoryInterface) GetItemsList(ctx context.Context, filter models.ItemsFilter) ([]*models.Item, error) {
+	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetItemsList")
@@ -101,19 +270,19 @@ func (_m *ItemRepositoryInterface) GetItemsList(userId int) ([]*models.Item, err
 
 	var r0 []*models.Item
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) ([]*models.Item, error)); ok {
-		return rf(userId)
+	if rf, ok := ret.Get(0).(func(context.Context, models.ItemsFilter) ([]*models.Item, error)); ok {
+		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(int) []*models.Item); ok {
-		r0 = rf(userId)
+	if rf, ok := ret.Get(0).(func(context.Context, models.ItemsFilter) []*models.Item); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Item)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(userId)
+	if rf, ok := ret.Get(1).(func(context.Context, models.ItemsFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -121,9 +290,87 @@ func (_m *ItemRepositoryInterface) GetItemsList(userId int) ([]*models.Item, err
 	return r0, r1
 }
 
-// UpdateItem provides a mock function with given fields: id, srcItem, userId
-func (_m *ItemRepositoryInterface) UpdateItem(id int, srcItem *models.Item, userId int) (*models.Item, error) {
-	ret := _m.Called(id, srcItem, userId)
+// GetSoldItem provides a mock function with given fields: ctx, id
+func (_m *ItemRepositoryInterface) GetSoldItem(ctx context.Context, id int) (*models.Item, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSoldItem")
+	}
+
+	var r0 *models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Item, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Item); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkItemSold provides a mock function with given fields: ctx, id, userId, buyerId, soldPrice
+func (_m *ItemRepositoryInterface) MarkItemSold(ctx context.Context, id int, userId int, buyerId int, soldPrice float64) (*models.Item, error) {
+	ret := _m.Called(ctx, id, userId, buyerId, soldPrice)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkItemSold")
+	}
+
+	var r0 *models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int, float64) (*models.Item, error)); ok {
+		return rf(ctx, id, userId, buyerId, soldPrice)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int, float64) *models.Item); ok {
+		r0 = rf(ctx, id, userId, buyerId, soldPrice)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int, float64) error); ok {
+		r1 = rf(ctx, id, userId, buyerId, soldPrice)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StreamItems provides a mock function with given fields: ctx, filter, fn
+func (_m *ItemRepositoryInterface) StreamItems(ctx context.Context, filter models.ItemsFilter, fn func(*models.Item) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamItems")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.ItemsFilter, func(*models.Item) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateItem provides a mock function with given fields: ctx, id, srcItem, userId
+func (_m *ItemRepositoryInterface) UpdateItem(ctx context.Context, id int, srcItem *models.Item, userId int) (*models.Item, error) {
+	ret := _m.Called(ctx, id, srcItem, userId)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateItem")
@@ -131,19 +378,19 @@ func (_m *ItemRepositoryInterface) UpdateItem(id int, srcItem *models.Item, user
 
 	var r0 *models.Item
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int, *models.Item, int) (*models.Item, error)); ok {
-		return rf(id, srcItem, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, *models.Item, int) (*models.Item, error)); ok {
+		return rf(ctx, id, srcItem, userId)
 	}
-	if rf, ok := ret.Get(0).(func(int, *models.Item, int) *models.Item); ok {
-		r0 = rf(id, srcItem, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, *models.Item, int) *models.Item); ok {
+		r0 = rf(ctx, id, srcItem, userId)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Item)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int, *models.Item, int) error); ok {
-		r1 = rf(id, srcItem, userId)
+	if rf, ok := ret.Get(1).(func(context.Context, int, *models.Item, int) error); ok {
+		r1 = rf(ctx, id, srcItem, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
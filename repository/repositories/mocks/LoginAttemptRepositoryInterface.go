@@ -0,0 +1,145 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "ypeskov/go_hillel_9/repository/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// LoginAttemptRepositoryInterface is an autogenerated mock type for the LoginAttemptRepositoryInterface type
+type LoginAttemptRepositoryInterface struct {
+	mock.Mock
+}
+
+// AddLockoutEvent provides a mock function with given fields: ctx, event
+func (_m *LoginAttemptRepositoryInterface) AddLockoutEvent(ctx context.Context, event *models.LoginLockoutEvent) error {
+	ret := _m.Called(ctx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddLockoutEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoginLockoutEvent) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddLoginFailure provides a mock function with given fields: ctx, subjectType, subject, windowStart
+func (_m *LoginAttemptRepositoryInterface) AddLoginFailure(ctx context.Context, subjectType string, subject string, windowStart time.Time) (*models.LoginFailure, error) {
+	ret := _m.Called(ctx, subjectType, subject, windowStart)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddLoginFailure")
+	}
+
+	var r0 *models.LoginFailure
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) (*models.LoginFailure, error)); ok {
+		return rf(ctx, subjectType, subject, windowStart)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) *models.LoginFailure); ok {
+		r0 = rf(ctx, subjectType, subject, windowStart)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LoginFailure)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = rf(ctx, subjectType, subject, windowStart)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoginFailure provides a mock function with given fields: ctx, subjectType, subject
+func (_m *LoginAttemptRepositoryInterface) GetLoginFailure(ctx context.Context, subjectType string, subject string) (*models.LoginFailure, error) {
+	ret := _m.Called(ctx, subjectType, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoginFailure")
+	}
+
+	var r0 *models.LoginFailure
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.LoginFailure, error)); ok {
+		return rf(ctx, subjectType, subject)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.LoginFailure); ok {
+		r0 = rf(ctx, subjectType, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LoginFailure)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, subjectType, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockLogin provides a mock function with given fields: ctx, subjectType, subject, until
+func (_m *LoginAttemptRepositoryInterface) LockLogin(ctx context.Context, subjectType string, subject string, until time.Time) error {
+	ret := _m.Called(ctx, subjectType, subject, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockLogin")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) error); ok {
+		r0 = rf(ctx, subjectType, subject, until)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetLoginFailures provides a mock function with given fields: ctx, subjectType, subject
+func (_m *LoginAttemptRepositoryInterface) ResetLoginFailures(ctx context.Context, subjectType string, subject string) error {
+	ret := _m.Called(ctx, subjectType, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetLoginFailures")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, subjectType, subject)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLoginAttemptRepositoryInterface creates a new instance of LoginAttemptRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoginAttemptRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoginAttemptRepositoryInterface {
+	mock := &LoginAttemptRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,359 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "ypeskov/go_hillel_9/repository/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MessageRepositoryInterface is an autogenerated mock type for the MessageRepositoryInterface type
+type MessageRepositoryInterface struct {
+	mock.Mock
+}
+
+// BlockUser provides a mock function with given fields: ctx, blockerId, blockedId
+func (_m *MessageRepositoryInterface) BlockUser(ctx context.Context, blockerId int, blockedId int) error {
+	ret := _m.Called(ctx, blockerId, blockedId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BlockUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, blockerId, blockedId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateConversation provides a mock function with given fields: ctx, conversation
+func (_m *MessageRepositoryInterface) CreateConversation(ctx context.Context, conversation *models.Conversation) (*models.Conversation, error) {
+	ret := _m.Called(ctx, conversation)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateConversation")
+	}
+
+	var r0 *models.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Conversation) (*models.Conversation, error)); ok {
+		return rf(ctx, conversation)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Conversation) *models.Conversation); ok {
+		r0 = rf(ctx, conversation)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Conversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Conversation) error); ok {
+		r1 = rf(ctx, conversation)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateMessage provides a mock function with given fields: ctx, message
+func (_m *MessageRepositoryInterface) CreateMessage(ctx context.Context, message *models.Message) (*models.Message, error) {
+	ret := _m.Called(ctx, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMessage")
+	}
+
+	var r0 *models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Message) (*models.Message, error)); ok {
+		return rf(ctx, message)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Message) *models.Message); ok {
+		r0 = rf(ctx, message)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Message) error); ok {
+		r1 = rf(ctx, message)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindConversation provides a mock function with given fields: ctx, userAId, userBId, itemId
+func (_m *MessageRepositoryInterface) FindConversation(ctx context.Context, userAId int, userBId int, itemId *int) (*models.Conversation, error) {
+	ret := _m.Called(ctx, userAId, userBId, itemId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindConversation")
+	}
+
+	var r0 *models.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, *int) (*models.Conversation, error)); ok {
+		return rf(ctx, userAId, userBId, itemId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, *int) *models.Conversation); ok {
+		r0 = rf(ctx, userAId, userBId, itemId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Conversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, *int) error); ok {
+		r1 = rf(ctx, userAId, userBId, itemId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlockedUsers provides a mock function with given fields: ctx, blockerId
+func (_m *MessageRepositoryInterface) GetBlockedUsers(ctx context.Context, blockerId int) ([]*models.UserBlock, error) {
+	ret := _m.Called(ctx, blockerId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockedUsers")
+	}
+
+	var r0 []*models.UserBlock
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*models.UserBlock, error)); ok {
+		return rf(ctx, blockerId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.UserBlock); ok {
+		r0 = rf(ctx, blockerId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.UserBlock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, blockerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetConversation provides a mock function with given fields: ctx, id
+func (_m *MessageRepositoryInterface) GetConversation(ctx context.Context, id int) (*models.Conversation, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetConversation")
+	}
+
+	var r0 *models.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Conversation, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Conversation); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Conversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessages provides a mock function with given fields: ctx, conversationId, beforeId, limit
+func (_m *MessageRepositoryInterface) GetMessages(ctx context.Context, conversationId int, beforeId int, limit int) ([]*models.Message, error) {
+	ret := _m.Called(ctx, conversationId, beforeId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessages")
+	}
+
+	var r0 []*models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Message, error)); ok {
+		return rf(ctx, conversationId, beforeId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Message); ok {
+		r0 = rf(ctx, conversationId, beforeId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = rf(ctx, conversationId, beforeId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUnreadCount provides a mock function with given fields: ctx, userId
+func (_m *MessageRepositoryInterface) GetUnreadCount(ctx context.Context, userId int) (int, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnreadCount")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserConversations provides a mock function with given fields: ctx, userId
+func (_m *MessageRepositoryInterface) GetUserConversations(ctx context.Context, userId int) ([]*models.ConversationSummary, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserConversations")
+	}
+
+	var r0 []*models.ConversationSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*models.ConversationSummary, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.ConversationSummary); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ConversationSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsBlocked provides a mock function with given fields: ctx, blockerId, blockedId
+func (_m *MessageRepositoryInterface) IsBlocked(ctx context.Context, blockerId int, blockedId int) (bool, error) {
+	ret := _m.Called(ctx, blockerId, blockedId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsBlocked")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (bool, error)); ok {
+		return rf(ctx, blockerId, blockedId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) bool); ok {
+		r0 = rf(ctx, blockerId, blockedId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, blockerId, blockedId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkRead provides a mock function with given fields: ctx, conversationId, readerId
+func (_m *MessageRepositoryInterface) MarkRead(ctx context.Context, conversationId int, readerId int) (int, error) {
+	ret := _m.Called(ctx, conversationId, readerId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkRead")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (int, error)); ok {
+		return rf(ctx, conversationId, readerId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) int); ok {
+		r0 = rf(ctx, conversationId, readerId)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, conversationId, readerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnblockUser provides a mock function with given fields: ctx, blockerId, blockedId
+func (_m *MessageRepositoryInterface) UnblockUser(ctx context.Context, blockerId int, blockedId int) error {
+	ret := _m.Called(ctx, blockerId, blockedId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnblockUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, blockerId, blockedId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMessageRepositoryInterface creates a new instance of MessageRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMessageRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MessageRepositoryInterface {
+	mock := &MessageRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,138 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MfaRepositoryInterface is an autogenerated mock type for the MfaRepositoryInterface type
+type MfaRepositoryInterface struct {
+	mock.Mock
+}
+
+// DisableMfa provides a mock function with given fields: ctx, userId
+func (_m *MfaRepositoryInterface) DisableMfa(ctx context.Context, userId int) error {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableMfa")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnableMfa provides a mock function with given fields: ctx, userId, step, recoveryCodeHashes
+func (_m *MfaRepositoryInterface) EnableMfa(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error {
+	ret := _m.Called(ctx, userId, step, recoveryCodeHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnableMfa")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int64, []string) error); ok {
+		r0 = rf(ctx, userId, step, recoveryCodeHashes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetMfaSecret provides a mock function with given fields: ctx, userId, secret
+func (_m *MfaRepositoryInterface) SetMfaSecret(ctx context.Context, userId int, secret string) error {
+	ret := _m.Called(ctx, userId, secret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMfaSecret")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, userId, secret)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UseMfaStep provides a mock function with given fields: ctx, userId, step
+func (_m *MfaRepositoryInterface) UseMfaStep(ctx context.Context, userId int, step int64) (bool, error) {
+	ret := _m.Called(ctx, userId, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseMfaStep")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int64) (bool, error)); ok {
+		return rf(ctx, userId, step)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int64) bool); ok {
+		r0 = rf(ctx, userId, step)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int64) error); ok {
+		r1 = rf(ctx, userId, step)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseRecoveryCode provides a mock function with given fields: ctx, userId, codeHash
+func (_m *MfaRepositoryInterface) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error) {
+	ret := _m.Called(ctx, userId, codeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseRecoveryCode")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (bool, error)); ok {
+		return rf(ctx, userId, codeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) bool); ok {
+		r0 = rf(ctx, userId, codeHash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userId, codeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMfaRepositoryInterface creates a new instance of MfaRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMfaRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MfaRepositoryInterface {
+	mock := &MfaRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "ypeskov/go_hillel_9/repository/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ProfileRepositoryInterface is an autogenerated mock type for the ProfileRepositoryInterface type
+type ProfileRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetUserProfile provides a mock function with given fields: ctx, userId
+func (_m *ProfileRepositoryInterface) GetUserProfile(ctx context.Context, userId int) (*models.UserProfile, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserProfile")
+	}
+
+	var r0 *models.UserProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.UserProfile, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.UserProfile); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewProfileRepositoryInterface creates a new instance of ProfileRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProfileRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ProfileRepositoryInterface {
+	mock := &ProfileRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
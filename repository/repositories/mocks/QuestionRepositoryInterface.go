@@ -0,0 +1,149 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "ypeskov/go_hillel_9/repository/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// QuestionRepositoryInterface is an autogenerated mock type for the QuestionRepositoryInterface type
+type QuestionRepositoryInterface struct {
+	mock.Mock
+}
+
+// AnswerQuestion provides a mock function with given fields: ctx, id, answer
+func (_m *QuestionRepositoryInterface) AnswerQuestion(ctx context.Context, id int, answer string) (*models.ItemQuestion, error) {
+	ret := _m.Called(ctx, id, answer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AnswerQuestion")
+	}
+
+	var r0 *models.ItemQuestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (*models.ItemQuestion, error)); ok {
+		return rf(ctx, id, answer)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) *models.ItemQuestion); ok {
+		r0 = rf(ctx, id, answer)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ItemQuestion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, id, answer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateQuestion provides a mock function with given fields: ctx, question
+func (_m *QuestionRepositoryInterface) CreateQuestion(ctx context.Context, question *models.ItemQuestion) (*models.ItemQuestion, error) {
+	ret := _m.Called(ctx, question)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateQuestion")
+	}
+
+	var r0 *models.ItemQuestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ItemQuestion) (*models.ItemQuestion, error)); ok {
+		return rf(ctx, question)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ItemQuestion) *models.ItemQuestion); ok {
+		r0 = rf(ctx, question)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ItemQuestion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ItemQuestion) error); ok {
+		r1 = rf(ctx, question)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetItemQuestions provides a mock function with given fields: ctx, itemId, viewerId, includeUnanswered
+func (_m *QuestionRepositoryInterface) GetItemQuestions(ctx context.Context, itemId int, viewerId int, includeUnanswered bool) ([]*models.ItemQuestion, error) {
+	ret := _m.Called(ctx, itemId, viewerId, includeUnanswered)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetItemQuestions")
+	}
+
+	var r0 []*models.ItemQuestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, bool) ([]*models.ItemQuestion, error)); ok {
+		return rf(ctx, itemId, viewerId, includeUnanswered)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, bool) []*models.ItemQuestion); ok {
+		r0 = rf(ctx, itemId, viewerId, includeUnanswered)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ItemQuestion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, bool) error); ok {
+		r1 = rf(ctx, itemId, viewerId, includeUnanswered)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQuestionById provides a mock function with given fields: ctx, id
+func (_m *QuestionRepositoryInterface) GetQuestionById(ctx context.Context, id int) (*models.ItemQuestion, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuestionById")
+	}
+
+	var r0 *models.ItemQuestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.ItemQuestion, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.ItemQuestion); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ItemQuestion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewQuestionRepositoryInterface creates a new instance of QuestionRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewQuestionRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *QuestionRepositoryInterface {
+	mock := &QuestionRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
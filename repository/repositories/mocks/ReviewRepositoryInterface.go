@@ -0,0 +1,261 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "ypeskov/go_hillel_9/repository/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReviewRepositoryInterface is an autogenerated mock type for the ReviewRepositoryInterface type
+type ReviewRepositoryInterface struct {
+	mock.Mock
+}
+
+// AddResponse provides a mock function with given fields: ctx, id, response
+func (_m *ReviewRepositoryInterface) AddResponse(ctx context.Context, id int, response string) error {
+	ret := _m.Called(ctx, id, response)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddResponse")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, id, response)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateReview provides a mock function with given fields: ctx, review
+func (_m *ReviewRepositoryInterface) CreateReview(ctx context.Context, review *models.Review) (*models.Review, error) {
+	ret := _m.Called(ctx, review)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateReview")
+	}
+
+	var r0 *models.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Review) (*models.Review, error)); ok {
+		return rf(ctx, review)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Review) *models.Review); ok {
+		r0 = rf(ctx, review)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Review) error); ok {
+		r1 = rf(ctx, review)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DisputeReview provides a mock function with given fields: ctx, id, reason
+func (_m *ReviewRepositoryInterface) DisputeReview(ctx context.Context, id int, reason string) error {
+	ret := _m.Called(ctx, id, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisputeReview")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, id, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDisputedReviews provides a mock function with given fields: ctx
+func (_m *ReviewRepositoryInterface) GetDisputedReviews(ctx context.Context) ([]*models.Review, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDisputedReviews")
+	}
+
+	var r0 []*models.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.Review, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.Review); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRatingSummary provides a mock function with given fields: ctx, revieweeId
+func (_m *ReviewRepositoryInterface) GetRatingSummary(ctx context.Context, revieweeId int) (*models.RatingSummary, error) {
+	ret := _m.Called(ctx, revieweeId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRatingSummary")
+	}
+
+	var r0 *models.RatingSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.RatingSummary, error)); ok {
+		return rf(ctx, revieweeId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.RatingSummary); ok {
+		r0 = rf(ctx, revieweeId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RatingSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, revieweeId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewById provides a mock function with given fields: ctx, id
+func (_m *ReviewRepositoryInterface) GetReviewById(ctx context.Context, id int) (*models.Review, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewById")
+	}
+
+	var r0 *models.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Review, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Review); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserReviews provides a mock function with given fields: ctx, revieweeId, limit, offset
+func (_m *ReviewRepositoryInterface) GetUserReviews(ctx context.Context, revieweeId int, limit int, offset int) ([]*models.Review, error) {
+	ret := _m.Called(ctx, revieweeId, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserReviews")
+	}
+
+	var r0 []*models.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Review, error)); ok {
+		return rf(ctx, revieweeId, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Review); ok {
+		r0 = rf(ctx, revieweeId, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = rf(ctx, revieweeId, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HasReview provides a mock function with given fields: ctx, itemId, reviewerId
+func (_m *ReviewRepositoryInterface) HasReview(ctx context.Context, itemId int, reviewerId int) (bool, error) {
+	ret := _m.Called(ctx, itemId, reviewerId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HasReview")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (bool, error)); ok {
+		return rf(ctx, itemId, reviewerId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) bool); ok {
+		r0 = rf(ctx, itemId, reviewerId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, itemId, reviewerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveDispute provides a mock function with given fields: ctx, id, adminId, status
+func (_m *ReviewRepositoryInterface) ResolveDispute(ctx context.Context, id int, adminId int, status string) error {
+	ret := _m.Called(ctx, id, adminId, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveDispute")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, string) error); ok {
+		r0 = rf(ctx, id, adminId, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewReviewRepositoryInterface creates a new instance of ReviewRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReviewRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReviewRepositoryInterface {
+	mock := &ReviewRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,112 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// RevocationRepositoryInterface is an autogenerated mock type for the RevocationRepositoryInterface type
+type RevocationRepositoryInterface struct {
+	mock.Mock
+}
+
+// AddRevokedToken provides a mock function with given fields: ctx, jti, userId, expiresAt
+func (_m *RevocationRepositoryInterface) AddRevokedToken(ctx context.Context, jti string, userId int, expiresAt time.Time) error {
+	ret := _m.Called(ctx, jti, userId, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddRevokedToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Time) error); ok {
+		r0 = rf(ctx, jti, userId, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredRevokedTokens provides a mock function with given fields: ctx
+func (_m *RevocationRepositoryInterface) DeleteExpiredRevokedTokens(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredRevokedTokens")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IsTokenRevoked provides a mock function with given fields: ctx, jti
+func (_m *RevocationRepositoryInterface) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	ret := _m.Called(ctx, jti)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsTokenRevoked")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, jti)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, jti)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, jti)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetTokensValidAfter provides a mock function with given fields: ctx, userId, validAfter
+func (_m *RevocationRepositoryInterface) SetTokensValidAfter(ctx context.Context, userId int, validAfter time.Time) error {
+	ret := _m.Called(ctx, userId, validAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTokensValidAfter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) error); ok {
+		r0 = rf(ctx, userId, validAfter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRevocationRepositoryInterface creates a new instance of RevocationRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRevocationRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RevocationRepositoryInterface {
+	mock := &RevocationRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
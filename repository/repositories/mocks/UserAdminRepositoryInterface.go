@@ -0,0 +1,212 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "ypeskov/go_hillel_9/repository/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// UserAdminRepositoryInterface is an autogenerated mock type for the UserAdminRepositoryInterface type
+type UserAdminRepositoryInterface struct {
+	mock.Mock
+}
+
+// AddModerationEvent provides a mock function with given fields: ctx, event
+func (_m *UserAdminRepositoryInterface) AddModerationEvent(ctx context.Context, event *models.UserModerationEvent) error {
+	ret := _m.Called(ctx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddModerationEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserModerationEvent) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetModerationEvents provides a mock function with given fields: ctx, userId
+func (_m *UserAdminRepositoryInterface) GetModerationEvents(ctx context.Context, userId int) ([]*models.UserModerationEvent, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetModerationEvents")
+	}
+
+	var r0 []*models.UserModerationEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*models.UserModerationEvent, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.UserModerationEvent); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.UserModerationEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserActivity provides a mock function with given fields: ctx, userId
+func (_m *UserAdminRepositoryInterface) GetUserActivity(ctx context.Context, userId int) (*models.UserActivity, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserActivity")
+	}
+
+	var r0 *models.UserActivity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.UserActivity, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.UserActivity); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserActivity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserById provides a mock function with given fields: ctx, id
+func (_m *UserAdminRepositoryInterface) GetUserById(ctx context.Context, id int) (*models.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserById")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchUsers provides a mock function with given fields: ctx, query, limit, offset
+func (_m *UserAdminRepositoryInterface) SearchUsers(ctx context.Context, query string, limit int, offset int) ([]*models.User, int, error) {
+	ret := _m.Called(ctx, query, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchUsers")
+	}
+
+	var r0 []*models.User
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]*models.User, int, error)); ok {
+		return rf(ctx, query, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []*models.User); ok {
+		r0 = rf(ctx, query, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) int); ok {
+		r1 = rf(ctx, query, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, int, int) error); ok {
+		r2 = rf(ctx, query, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetUserStatus provides a mock function with given fields: ctx, userId, status, suspendedUntil, reason
+func (_m *UserAdminRepositoryInterface) SetUserStatus(ctx context.Context, userId int, status string, suspendedUntil *time.Time, reason string) error {
+	ret := _m.Called(ctx, userId, status, suspendedUntil, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, *time.Time, string) error); ok {
+		r0 = rf(ctx, userId, status, suspendedUntil, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetUserType provides a mock function with given fields: ctx, userId, userTypeId
+func (_m *UserAdminRepositoryInterface) SetUserType(ctx context.Context, userId int, userTypeId int) error {
+	ret := _m.Called(ctx, userId, userTypeId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserType")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, userTypeId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUserAdminRepositoryInterface creates a new instance of UserAdminRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserAdminRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserAdminRepositoryInterface {
+	mock := &UserAdminRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -1,11 +1,14 @@
-// Code generated by mockery v2.43.1. DO NOT EDIT.
+// Code generated by mockery v2.53.7. DO NOT EDIT.
 
 package mocks
 
 import (
+	context "context"
 	models "ypeskov/go_hillel_9/repository/models"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // UserRepositoryInterface is an autogenerated mock type for the UserRepositoryInterface type
@@ -13,17 +16,17 @@ type UserRepositoryInterface struct {
 	mock.Mock
 }
 
-// AddOrUpdateRefreshToken provides a mock function with given fields: userId, token
-func (_m *UserRepositoryInterface) AddOrUpdateRefreshToken(userId int, token string) error {
-	ret := _m.Called(userId, token)
+// AddEmailVerificationToken provides a mock function with given fields: ctx, userId, tokenHash, expiresAt
+func (_m *UserRepositoryInterface) AddEmailVerificationToken(ctx context.Context, userId int, tokenHash string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, userId, tokenHash, expiresAt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for AddOrUpdateRefreshToken")
+		panic("no return value specified for AddEmailVerificationToken")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int, string) error); ok {
-		r0 = rf(userId, token)
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, time.Time) error); ok {
+		r0 = rf(ctx, userId, tokenHash, expiresAt)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -31,9 +34,27 @@ func (_m *UserRepositoryInterface) AddOrUpdateRefreshToken(userId int, token str
 	return r0
 }
 
-// CreateUser provides a mock function with given fields: srcUser
-func (_m *UserRepositoryInterface) CreateUser(srcUser *models.User) (*models.User, error) {
-	ret := _m.Called(srcUser)
+// AddUserIdentity provides a mock function with given fields: ctx, userId, issuer, subject
+func (_m *UserRepositoryInterface) AddUserIdentity(ctx context.Context, userId int, issuer string, subject string) error {
+	ret := _m.Called(ctx, userId, issuer, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddUserIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) error); ok {
+		r0 = rf(ctx, userId, issuer, subject)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateUser provides a mock function with given fields: ctx, srcUser
+func (_m *UserRepositoryInterface) CreateUser(ctx context.Context, srcUser *models.User) (*models.User, error) {
+	ret := _m.Called(ctx, srcUser)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateUser")
@@ -41,19 +62,19 @@ func (_m *UserRepositoryInterface) CreateUser(srcUser *models.User) (*models.Use
 
 	var r0 *models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*models.User) (*models.User, error)); ok {
-		return rf(srcUser)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) (*models.User, error)); ok {
+		return rf(ctx, srcUser)
 	}
-	if rf, ok := ret.Get(0).(func(*models.User) *models.User); ok {
-		r0 = rf(srcUser)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
+		r0 = rf(ctx, srcUser)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*models.User) error); ok {
-		r1 = rf(srcUser)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, srcUser)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -61,17 +82,37 @@ func (_m *UserRepositoryInterface) CreateUser(srcUser *models.User) (*models.Use
 	return r0, r1
 }
 
-// GetUserByEmail provides a mock function with given fields: email
-func (_m *UserRepositoryInterface) GetUserByEmail(email string) *models.User {
-	ret := _m.Called(email)
+// GetUserByEmail provides a mock function with given fields: ctx, email
+func (_m *UserRepositoryInterface) GetUserByEmail(ctx context.Context, email string) *models.User {
+	ret := _m.Called(ctx, email)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserByEmail")
 	}
 
 	var r0 *models.User
-	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
-		r0 = rf(email)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	return r0
+}
+
+// GetUserByEmailVerificationToken provides a mock function with given fields: ctx, tokenHash
+func (_m *UserRepositoryInterface) GetUserByEmailVerificationToken(ctx context.Context, tokenHash string) *models.User {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByEmailVerificationToken")
+	}
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = rf(ctx, tokenHash)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
@@ -81,17 +122,17 @@ func (_m *UserRepositoryInterface) GetUserByEmail(email string) *models.User {
 	return r0
 }
 
-// GetUserByRefreshToken provides a mock function with given fields: token
-func (_m *UserRepositoryInterface) GetUserByRefreshToken(token string) *models.User {
-	ret := _m.Called(token)
+// GetUserById provides a mock function with given fields: ctx, id
+func (_m *UserRepositoryInterface) GetUserById(ctx context.Context, id int) *models.User {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserByRefreshToken")
+		panic("no return value specified for GetUserById")
 	}
 
 	var r0 *models.User
-	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
-		r0 = rf(token)
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.User); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
@@ -101,9 +142,59 @@ func (_m *UserRepositoryInterface) GetUserByRefreshToken(token string) *models.U
 	return r0
 }
 
-// GetUsersList provides a mock function with given fields:
-func (_m *UserRepositoryInterface) GetUsersList() ([]*models.User, error) {
-	ret := _m.Called()
+// GetUserByIdentity provides a mock function with given fields: ctx, issuer, subject
+func (_m *UserRepositoryInterface) GetUserByIdentity(ctx context.Context, issuer string, subject string) *models.User {
+	ret := _m.Called(ctx, issuer, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByIdentity")
+	}
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.User); ok {
+		r0 = rf(ctx, issuer, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	return r0
+}
+
+// GetUserType provides a mock function with given fields: ctx, user
+func (_m *UserRepositoryInterface) GetUserType(ctx context.Context, user *models.User) (*models.UserType, error) {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserType")
+	}
+
+	var r0 *models.UserType
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) (*models.UserType, error)); ok {
+		return rf(ctx, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.UserType); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserType)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsersList provides a mock function with given fields: ctx
+func (_m *UserRepositoryInterface) GetUsersList(ctx context.Context) ([]*models.User, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUsersList")
@@ -111,19 +202,19 @@ func (_m *UserRepositoryInterface) GetUsersList() ([]*models.User, error) {
 
 	var r0 []*models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]*models.User, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.User, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func() []*models.User); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.User); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -131,6 +222,42 @@ func (_m *UserRepositoryInterface) GetUsersList() ([]*models.User, error) {
 	return r0, r1
 }
 
+// SetEmailVerified provides a mock function with given fields: ctx, userId
+func (_m *UserRepositoryInterface) SetEmailVerified(ctx context.Context, userId int) error {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetEmailVerified")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePasswordHash provides a mock function with given fields: ctx, userId, passwordHash
+func (_m *UserRepositoryInterface) UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error {
+	ret := _m.Called(ctx, userId, passwordHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePasswordHash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, userId, passwordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewUserRepositoryInterface creates a new instance of UserRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepositoryInterface(t interface {
@@ -0,0 +1,231 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "ypeskov/go_hillel_9/repository/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserTypeRepositoryInterface is an autogenerated mock type for the UserTypeRepositoryInterface type
+type UserTypeRepositoryInterface struct {
+	mock.Mock
+}
+
+// CreateUserType provides a mock function with given fields: ctx, userType
+func (_m *UserTypeRepositoryInterface) CreateUserType(ctx context.Context, userType *models.UserType) (*models.UserType, error) {
+	ret := _m.Called(ctx, userType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUserType")
+	}
+
+	var r0 *models.UserType
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserType) (*models.UserType, error)); ok {
+		return rf(ctx, userType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserType) *models.UserType); ok {
+		r0 = rf(ctx, userType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserType)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.UserType) error); ok {
+		r1 = rf(ctx, userType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserTypeByCode provides a mock function with given fields: ctx, typeCode
+func (_m *UserTypeRepositoryInterface) GetUserTypeByCode(ctx context.Context, typeCode string) (*models.UserType, error) {
+	ret := _m.Called(ctx, typeCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserTypeByCode")
+	}
+
+	var r0 *models.UserType
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.UserType, error)); ok {
+		return rf(ctx, typeCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.UserType); ok {
+		r0 = rf(ctx, typeCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserType)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, typeCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserTypeById provides a mock function with given fields: ctx, id
+func (_m *UserTypeRepositoryInterface) GetUserTypeById(ctx context.Context, id int) (*models.UserType, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserTypeById")
+	}
+
+	var r0 *models.UserType
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.UserType, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.UserType); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserType)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserTypesList provides a mock function with given fields: ctx
+func (_m *UserTypeRepositoryInterface) GetUserTypesList(ctx context.Context) ([]*models.UserType, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserTypesList")
+	}
+
+	var r0 []*models.UserType
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.UserType, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.UserType); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.UserType)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetireUserType provides a mock function with given fields: ctx, id, replacementId
+func (_m *UserTypeRepositoryInterface) RetireUserType(ctx context.Context, id int, replacementId int) (int, error) {
+	ret := _m.Called(ctx, id, replacementId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetireUserType")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (int, error)); ok {
+		return rf(ctx, id, replacementId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) int); ok {
+		r0 = rf(ctx, id, replacementId)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, id, replacementId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetMfaRequired provides a mock function with given fields: ctx, id, required
+func (_m *UserTypeRepositoryInterface) SetMfaRequired(ctx context.Context, id int, required bool) error {
+	ret := _m.Called(ctx, id, required)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMfaRequired")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) error); ok {
+		r0 = rf(ctx, id, required)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetSelfAssignable provides a mock function with given fields: ctx, id, assignable
+func (_m *UserTypeRepositoryInterface) SetSelfAssignable(ctx context.Context, id int, assignable bool) error {
+	ret := _m.Called(ctx, id, assignable)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSelfAssignable")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) error); ok {
+		r0 = rf(ctx, id, assignable)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateUserTypeDescription provides a mock function with given fields: ctx, id, description
+func (_m *UserTypeRepositoryInterface) UpdateUserTypeDescription(ctx context.Context, id int, description string) error {
+	ret := _m.Called(ctx, id, description)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserTypeDescription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, id, description)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUserTypeRepositoryInterface creates a new instance of UserTypeRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserTypeRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserTypeRepositoryInterface {
+	mock := &UserTypeRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
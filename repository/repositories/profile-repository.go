@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"strings"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type ProfileRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type ProfileRepositoryInterface interface {
+	GetUserProfile(ctx context.Context, userId int) (*models.UserProfile, error)
+}
+
+func GetProfileRepository(log *log.Logger, connection database.Database) ProfileRepositoryInterface {
+	return &ProfileRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+type profileRow struct {
+	Id             int       `db:"id"`
+	FirstName      string    `db:"first_name"`
+	LastName       string    `db:"last_name"`
+	CreatedAt      time.Time `db:"created_at"`
+	ItemsSold      int       `db:"items_sold"`
+	ActiveListings int       `db:"active_listings"`
+}
+
+// GetUserProfile returns NotFoundErr for banned users as well, their profiles are hidden
+func (r *ProfileRepository) GetUserProfile(ctx context.Context, userId int) (*models.UserProfile, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var row profileRow
+
+	query := `SELECT u.id, u.first_name, u.last_name, u.created_at,
+				(SELECT COUNT(*) FROM items WHERE user_id = u.id AND sold_at IS NOT NULL) AS items_sold,
+				(SELECT COUNT(*) FROM items WHERE user_id = u.id AND sold_price IS NULL) AS active_listings
+			  FROM users u
+			  WHERE u.id = $1 AND u.status <> $2`
+	err := r.db.GetContext(ctx, &row, query, userId, models.UserStatusBanned)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get user profile", err)
+
+		return nil, err
+	}
+
+	return &models.UserProfile{
+		Id:             row.Id,
+		DisplayName:    DisplayName(row.FirstName, row.LastName),
+		MemberSince:    row.CreatedAt,
+		ItemsSold:      row.ItemsSold,
+		ActiveListings: row.ActiveListings,
+	}, nil
+}
+
+// DisplayName shows only the initial of the last name, e.g. "John D."
+func DisplayName(firstName string, lastName string) string {
+	lastName = strings.TrimSpace(lastName)
+	if lastName == "" {
+		return firstName
+	}
+
+	return firstName + " " + strings.ToUpper(string([]rune(lastName)[:1])) + "."
+}
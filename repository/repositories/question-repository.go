@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type QuestionRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type QuestionRepositoryInterface interface {
+	CreateQuestion(ctx context.Context, question *models.ItemQuestion) (*models.ItemQuestion, error)
+	GetQuestionById(ctx context.Context, id int) (*models.ItemQuestion, error)
+	GetItemQuestions(ctx context.Context, itemId int, viewerId int,
+		includeUnanswered bool) ([]*models.ItemQuestion, error)
+	AnswerQuestion(ctx context.Context, id int, answer string) (*models.ItemQuestion, error)
+}
+
+func GetQuestionRepository(log *log.Logger, connection database.Database) QuestionRepositoryInterface {
+	return &QuestionRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+func (r *QuestionRepository) CreateQuestion(ctx context.Context,
+	question *models.ItemQuestion) (*models.ItemQuestion, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var newQuestion models.ItemQuestion
+
+	err := r.db.GetContext(ctx, &newQuestion, `INSERT INTO item_questions (item_id, asker_id, question)
+								   VALUES ($1, $2, $3) RETURNING *`,
+		question.ItemId, question.AskerId, question.Question)
+	if err != nil {
+		r.log.Errorln("failed to insert question", err)
+
+		return nil, err
+	}
+
+	return &newQuestion, nil
+}
+
+func (r *QuestionRepository) GetQuestionById(ctx context.Context, id int) (*models.ItemQuestion, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var question models.ItemQuestion
+
+	err := r.db.GetContext(ctx, &question, "SELECT * FROM item_questions WHERE id = $1", id)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get question", err)
+
+		return nil, err
+	}
+
+	return &question, nil
+}
+
+// GetItemQuestions returns answered questions and the unanswered ones of the viewer,
+// includeUnanswered adds the unanswered questions of everybody
+func (r *QuestionRepository) GetItemQuestions(ctx context.Context, itemId int, viewerId int,
+	includeUnanswered bool) ([]*models.ItemQuestion, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var questions []*models.ItemQuestion
+
+	err := r.db.SelectContext(ctx, &questions, `SELECT * FROM item_questions
+									WHERE item_id = $1 AND (answer IS NOT NULL OR asker_id = $2 OR $3)
+									ORDER BY created_at, id`, itemId, viewerId, includeUnanswered)
+	if err != nil {
+		r.log.Errorln("failed to get questions", err)
+
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+// AnswerQuestion returns NotFoundErr if the question is already answered
+func (r *QuestionRepository) AnswerQuestion(ctx context.Context, id int, answer string) (*models.ItemQuestion, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var question models.ItemQuestion
+
+	err := r.db.GetContext(ctx, &question, `UPDATE item_questions SET answer = $1, answered_at = $2
+							   WHERE id = $3 AND answer IS NULL RETURNING *`, answer, time.Now().UTC(), id)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to answer question", err)
+
+		return nil, err
+	}
+
+	return &question, nil
+}
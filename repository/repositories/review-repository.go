@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"math"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type ReviewRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type ReviewRepositoryInterface interface {
+	CreateReview(ctx context.Context, review *models.Review) (*models.Review, error)
+	GetReviewById(ctx context.Context, id int) (*models.Review, error)
+	HasReview(ctx context.Context, itemId int, reviewerId int) (bool, error)
+	GetUserReviews(ctx context.Context, revieweeId int, limit int, offset int) ([]*models.Review, error)
+	GetRatingSummary(ctx context.Context, revieweeId int) (*models.RatingSummary, error)
+	AddResponse(ctx context.Context, id int, response string) error
+	DisputeReview(ctx context.Context, id int, reason string) error
+	ResolveDispute(ctx context.Context, id int, adminId int, status string) error
+	GetDisputedReviews(ctx context.Context) ([]*models.Review, error)
+}
+
+func GetReviewRepository(log *log.Logger, connection database.Database) ReviewRepositoryInterface {
+	return &ReviewRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+// CreateReview returns ReviewExistsErr if the reviewer has already reviewed the sale,
+// the unique key decides when two requests race
+func (r *ReviewRepository) CreateReview(ctx context.Context, review *models.Review) (*models.Review, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var newReview models.Review
+
+	err := r.db.GetContext(ctx, &newReview, `INSERT INTO reviews (item_id, reviewer_id, reviewee_id, rating, review)
+								 VALUES ($1, $2, $3, $4, $5)
+								 ON CONFLICT (item_id, reviewer_id) DO NOTHING RETURNING *`,
+		review.ItemId, review.ReviewerId, review.RevieweeId, review.Rating, review.Review)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ReviewExistsErr
+		}
+		r.log.Errorln("failed to insert review", err)
+
+		return nil, err
+	}
+
+	return &newReview, nil
+}
+
+func (r *ReviewRepository) GetReviewById(ctx context.Context, id int) (*models.Review, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var review models.Review
+
+	err := r.db.GetContext(ctx, &review, "SELECT * FROM reviews WHERE id = $1", id)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get review", err)
+
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (r *ReviewRepository) HasReview(ctx context.Context, itemId int, reviewerId int) (bool, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+
+	err := r.db.GetContext(ctx, &exists,
+		"SELECT EXISTS (SELECT 1 FROM reviews WHERE item_id = $1 AND reviewer_id = $2)",
+		itemId, reviewerId)
+	if err != nil {
+		r.log.Errorln("failed to check review", err)
+
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// GetUserReviews returns the visible reviews of the user, the newest first
+func (r *ReviewRepository) GetUserReviews(ctx context.Context, revieweeId int, limit int,
+	offset int) ([]*models.Review, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var reviews []*models.Review
+
+	err := r.db.SelectContext(ctx, &reviews, `SELECT * FROM reviews WHERE reviewee_id = $1 AND status <> $2
+								  ORDER BY created_at DESC, id DESC LIMIT $3 OFFSET $4`,
+		revieweeId, models.ReviewStatusRemoved, limit, offset)
+	if err != nil {
+		r.log.Errorln("failed to get reviews", err)
+
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *ReviewRepository) GetRatingSummary(ctx context.Context, revieweeId int) (*models.RatingSummary, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var summary models.RatingSummary
+
+	err := r.db.GetContext(ctx, &summary, `SELECT AVG(rating) AS average_rating, COUNT(*) AS ratings_count
+							   FROM reviews WHERE reviewee_id = $1 AND status <> $2`,
+		revieweeId, models.ReviewStatusRemoved)
+	if err != nil {
+		r.log.Errorln("failed to get rating summary", err)
+
+		return nil, err
+	}
+	if summary.AverageRating != nil {
+		rounded := math.Round(*summary.AverageRating*100) / 100
+		summary.AverageRating = &rounded
+	}
+
+	return &summary, nil
+}
+
+func (r *ReviewRepository) AddResponse(ctx context.Context, id int, response string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE reviews SET response = $1, responded_at = $2
+							  WHERE id = $3 AND response IS NULL`, response, time.Now().UTC(), id)
+	if err != nil {
+		r.log.Errorln("failed to add review response", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+func (r *ReviewRepository) DisputeReview(ctx context.Context, id int, reason string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE reviews SET status = $1, dispute_reason = $2, disputed_at = $3
+							  WHERE id = $4 AND status = $5`,
+		models.ReviewStatusDisputed, reason, time.Now().UTC(), id, models.ReviewStatusPublished)
+	if err != nil {
+		r.log.Errorln("failed to dispute review", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+func (r *ReviewRepository) ResolveDispute(ctx context.Context, id int, adminId int, status string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE reviews SET status = $1, resolved_by = $2, resolved_at = $3
+							  WHERE id = $4 AND status = $5`,
+		status, adminId, time.Now().UTC(), id, models.ReviewStatusDisputed)
+	if err != nil {
+		r.log.Errorln("failed to resolve review dispute", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+func (r *ReviewRepository) GetDisputedReviews(ctx context.Context) ([]*models.Review, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var reviews []*models.Review
+
+	err := r.db.SelectContext(ctx, &reviews, "SELECT * FROM reviews WHERE status = $1 ORDER BY disputed_at",
+		models.ReviewStatusDisputed)
+	if err != nil {
+		r.log.Errorln("failed to get disputed reviews", err)
+
+		return nil, err
+	}
+
+	return reviews, nil
+}
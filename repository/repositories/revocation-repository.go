@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/log"
+)
+
+type RevocationRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type RevocationRepositoryInterface interface {
+	AddRevokedToken(ctx context.Context, jti string, userId int, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) error
+	SetTokensValidAfter(ctx context.Context, userId int, validAfter time.Time) error
+}
+
+func GetRevocationRepository(log *log.Logger, connection database.Database) RevocationRepositoryInterface {
+	return &RevocationRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+func (r *RevocationRepository) AddRevokedToken(ctx context.Context, jti string, userId int, expiresAt time.Time) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (jti) DO NOTHING`
+	_, err := r.db.ExecContext(ctx, query, jti, userId, expiresAt, time.Now().UTC())
+	if err != nil {
+		r.log.Errorln("failed to insert revoked token into db", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *RevocationRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var count int
+
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM revoked_tokens WHERE jti = $1", jti)
+	if err != nil {
+		r.log.Errorln("failed to check revoked token", err)
+
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *RevocationRepository) DeleteExpiredRevokedTokens(ctx context.Context) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < $1", time.Now().UTC())
+	if err != nil {
+		r.log.Errorln("failed to delete expired revoked tokens", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *RevocationRepository) SetTokensValidAfter(ctx context.Context, userId int, validAfter time.Time) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET tokens_valid_after = $1 WHERE id = $2", validAfter, userId)
+	if err != nil {
+		r.log.Errorln("failed to set tokens_valid_after", err)
+
+		return err
+	}
+
+	return nil
+}
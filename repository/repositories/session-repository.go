@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type SessionRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type SessionRepositoryInterface interface {
+	CreateSession(ctx context.Context, session *models.Session) (*models.Session, error)
+	GetSessionById(ctx context.Context, id int) (*models.Session, error)
+	GetActiveSessionsList(ctx context.Context, userId int) ([]*models.Session, error)
+	TouchSession(ctx context.Context, id int, ip string, userAgent string) error
+	RevokeSession(ctx context.Context, id int, userId int) error
+	RevokeOtherSessions(ctx context.Context, userId int, keepSessionId int) error
+	RevokeAllSessions(ctx context.Context, userId int) error
+	AddRefreshToken(ctx context.Context, sessionId int, tokenHash string, expiresAt time.Time) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id int) (bool, error)
+}
+
+func GetSessionRepository(log *log.Logger, connection database.Database) SessionRepositoryInterface {
+	return &SessionRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *models.Session) (*models.Session, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+	session.CreatedAt = now
+	session.LastUsedAt = now
+
+	insertQuery := `INSERT INTO sessions (user_id, device, user_agent, ip, created_at, last_used_at)
+					VALUES (:user_id, :device, :user_agent, :ip, :created_at, :last_used_at)
+					RETURNING *`
+
+	rows, err := r.db.NamedQueryContext(ctx, insertQuery, session)
+	if err != nil {
+		r.log.Errorln("failed to insert session into db", err)
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var newSession models.Session
+	if rows.Next() {
+		err := rows.StructScan(&newSession)
+		if err != nil {
+			r.log.Errorf("Failed to scan session: %v", err)
+
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf("failed to scan new session")
+	}
+
+	return &newSession, nil
+}
+
+func (r *SessionRepository) GetSessionById(ctx context.Context, id int) (*models.Session, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var session models.Session
+
+	err := r.db.GetContext(ctx, &session, "SELECT * FROM sessions WHERE id = $1", id)
+	if err != nil {
+		r.log.Errorln("failed to get session by id", err)
+
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *SessionRepository) GetActiveSessionsList(ctx context.Context, userId int) ([]*models.Session, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var sessions []*models.Session
+
+	err := r.db.SelectContext(ctx, &sessions,
+		"SELECT * FROM sessions WHERE user_id = $1 AND revoked_at IS NULL ORDER BY last_used_at DESC", userId)
+	if err != nil {
+		r.log.Errorln("failed to get sessions from db", err)
+
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *SessionRepository) TouchSession(ctx context.Context, id int, ip string, userAgent string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE sessions SET last_used_at = $1, ip = $2, user_agent = $3 WHERE id = $4",
+		time.Now().UTC(), ip, userAgent, id)
+	if err != nil {
+		r.log.Errorln("failed to update session", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, id int, userId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL",
+		time.Now().UTC(), id, userId)
+	if err != nil {
+		r.log.Errorln("failed to revoke session", err)
+
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log.Errorln("error checking rows affected", err)
+
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFoundErr
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeOtherSessions(ctx context.Context, userId int, keepSessionId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL",
+		time.Now().UTC(), userId, keepSessionId)
+	if err != nil {
+		r.log.Errorln("failed to revoke other sessions", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllSessions(ctx context.Context, userId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL",
+		time.Now().UTC(), userId)
+	if err != nil {
+		r.log.Errorln("failed to revoke all sessions", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) AddRefreshToken(ctx context.Context, sessionId int, tokenHash string,
+	expiresAt time.Time) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO refresh_tokens (session_id, token_hash, created_at, expires_at)
+			  VALUES ($1, $2, $3, $4)`
+	_, err := r.db.ExecContext(ctx, query, sessionId, tokenHash, time.Now().UTC(), expiresAt)
+	if err != nil {
+		r.log.Errorln("failed to insert refresh token into db", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var token models.RefreshToken
+
+	err := r.db.GetContext(ctx, &token, "SELECT * FROM refresh_tokens WHERE token_hash = $1", tokenHash)
+	if err != nil {
+		r.log.Errorln("failed to get refresh token from db", err)
+
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed returns false if the token has already been used,
+// so two concurrent refreshes with the same token can't both succeed.
+func (r *SessionRepository) MarkRefreshTokenUsed(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL",
+		time.Now().UTC(), id)
+	if err != nil {
+		r.log.Errorln("failed to mark refresh token as used", err)
+
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log.Errorln("error checking rows affected", err)
+
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
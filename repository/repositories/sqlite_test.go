@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/database"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/migrations"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+// newSqliteDB returns a new SQLite database with all migrations applied
+func newSqliteDB(t *testing.T) (database.Database, *log.Logger) {
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+	cfg.DbDriver = database.SqliteDriver
+	cfg.DbPath = filepath.Join(t.TempDir(), "auction.db")
+
+	db, err := database.Connect(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	logger := log.New(cfg)
+	migrator, err := migrations.New(db, logger)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+
+	return db, logger
+}
+
+func TestSqliteRepositories(t *testing.T) {
+	ctx := context.Background()
+	db, logger := newSqliteDB(t)
+	userRepo := GetUserRepository(logger, db)
+	userTypeRepo := GetUserTypeRepository(logger, db)
+	itemRepo := GetItemRepository(logger, db)
+
+	seller, err := userRepo.CreateUser(ctx, &models.User{FirstName: "Jane", LastName: "Doe",
+		Email: "jane@example.com", PasswordHash: "hash", UserTypeId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, models.UserStatusActive, seller.Status)
+	assert.False(t, seller.CreatedAt.IsZero())
+	_, err = userRepo.CreateUser(ctx, &models.User{FirstName: "Jane", LastName: "Doe",
+		Email: "jane@example.com", UserTypeId: 1})
+	assert.Error(t, err)
+
+	t.Run("Items", func(t *testing.T) {
+		item, err := itemRepo.CreateItem(ctx, &models.Item{UserId: seller.Id, Title: "Bike", InitialPrice: 99.5})
+		require.NoError(t, err)
+		assert.Equal(t, 99.5, item.InitialPrice)
+
+		sold, err := itemRepo.MarkItemSold(ctx, item.Id, seller.Id, seller.Id, 120)
+		require.NoError(t, err)
+		assert.Equal(t, 120.0, *sold.SoldPrice)
+		assert.Nil(t, sold.SoldAt)
+		_, err = itemRepo.GetSoldItem(ctx, item.Id)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+
+		_, err = itemRepo.MarkItemSold(ctx, item.Id, seller.Id, seller.Id, 130)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+
+		sold, err = itemRepo.ConfirmItemSale(ctx, item.Id, seller.Id)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), *sold.SoldAt, time.Minute)
+		_, err = itemRepo.ConfirmItemSale(ctx, item.Id, seller.Id)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+		assert.ErrorIs(t, itemRepo.DeclineItemSale(ctx, item.Id, seller.Id), apperrors.NotFoundErr)
+
+		reviewRepo := GetReviewRepository(logger, db)
+		review := &models.Review{ItemId: &item.Id, ReviewerId: seller.Id, RevieweeId: seller.Id, Rating: 5}
+		_, err = reviewRepo.CreateReview(ctx, review)
+		require.NoError(t, err)
+		_, err = reviewRepo.CreateReview(ctx, review)
+		assert.ErrorIs(t, err, apperrors.ReviewExistsErr)
+
+		items, err := itemRepo.GetItemsList(ctx, models.ItemsFilter{UserId: seller.Id, ActiveOnly: true})
+		require.NoError(t, err)
+		assert.Empty(t, items)
+	})
+
+	t.Run("Items in bulk", func(t *testing.T) {
+		description := "Brass"
+		created, err := itemRepo.CreateItems(ctx, []*models.Item{
+			{UserId: seller.Id, Title: "Lamp", InitialPrice: 10, Description: &description},
+			{UserId: seller.Id, Title: "Sofa", InitialPrice: 20},
+		})
+		require.NoError(t, err)
+		require.Len(t, created, 2)
+		assert.Equal(t, "Brass", *created[0].Description)
+
+		_, err = itemRepo.MarkItemSold(ctx, created[1].Id, seller.Id, seller.Id, 25)
+		require.NoError(t, err)
+
+		soldItems := func() []string {
+			var sold []string
+			err := itemRepo.StreamItems(ctx, models.ItemsFilter{UserId: seller.Id, SoldOnly: true},
+				func(item *models.Item) error {
+					sold = append(sold, item.Title)
+
+					return nil
+				})
+			require.NoError(t, err)
+
+			return sold
+		}
+		assert.Equal(t, []string{"Bike"}, soldItems(), "a pending sale isn't sold yet")
+
+		_, err = itemRepo.ConfirmItemSale(ctx, created[1].Id, seller.Id)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bike", "Sofa"}, soldItems())
+	})
+
+	t.Run("Email verification tokens expire", func(t *testing.T) {
+		require.NoError(t, userRepo.AddEmailVerificationToken(ctx, seller.Id, "fresh", time.Now().UTC().Add(time.Hour)))
+		require.NoError(t, userRepo.AddEmailVerificationToken(ctx, seller.Id, "old", time.Now().UTC().Add(-time.Hour)))
+
+		assert.NotNil(t, userRepo.GetUserByEmailVerificationToken(ctx, "fresh"))
+		assert.Nil(t, userRepo.GetUserByEmailVerificationToken(ctx, "old"))
+	})
+
+	t.Run("Retiring a user type moves its users in one transaction", func(t *testing.T) {
+		moved, err := userTypeRepo.RetireUserType(ctx, 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 1, moved)
+		assert.Equal(t, int32(2), userRepo.GetUserById(ctx, seller.Id).UserTypeId)
+
+		_, err = userTypeRepo.RetireUserType(ctx, 1, 2)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+	})
+
+	t.Run("Search is case insensitive", func(t *testing.T) {
+		users, total, err := GetUserAdminRepository(logger, db).SearchUsers(ctx, "JANE D", 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, seller.Id, users[0].Id)
+
+		_, total, err = GetUserAdminRepository(logger, db).SearchUsers(ctx, "%", 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+	})
+
+	t.Run("Login failures are counted with an upsert", func(t *testing.T) {
+		loginRepo := GetLoginAttemptRepository(logger, db)
+		windowStart := time.Now().UTC().Add(-time.Minute)
+		for i := 1; i <= 3; i++ {
+			failure, err := loginRepo.AddLoginFailure(ctx, models.LoginSubjectAccount, "jane@example.com",
+				windowStart)
+			require.NoError(t, err)
+			assert.Equal(t, i, failure.Failures)
+		}
+
+		failure, err := loginRepo.AddLoginFailure(ctx, models.LoginSubjectAccount, "jane@example.com",
+			time.Now().UTC().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, failure.Failures)
+	})
+
+	t.Run("Revoked tokens", func(t *testing.T) {
+		revocationRepo := GetRevocationRepository(logger, db)
+		expiresAt := time.Now().UTC().Add(time.Hour)
+		require.NoError(t, revocationRepo.AddRevokedToken(ctx, "jti", seller.Id, expiresAt))
+		require.NoError(t, revocationRepo.AddRevokedToken(ctx, "jti", seller.Id, expiresAt))
+
+		revoked, err := revocationRepo.IsTokenRevoked(ctx, "jti")
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("Only seller and buyer types are self-assignable", func(t *testing.T) {
+		userTypes, err := userTypeRepo.GetUserTypesList(ctx)
+		require.NoError(t, err)
+		for _, userType := range userTypes {
+			assert.Equal(t, !userType.IsAdmin(), userType.SelfAssignable, userType.TypeCode)
+		}
+	})
+
+	t.Run("Deleting an item removes its conversations", func(t *testing.T) {
+		buyer, err := userRepo.CreateUser(ctx, &models.User{FirstName: "John", LastName: "Roe",
+			Email: "john@example.com", PasswordHash: "hash", UserTypeId: 2})
+		require.NoError(t, err)
+		item, err := itemRepo.CreateItem(ctx, &models.Item{UserId: seller.Id, Title: "Lamp", InitialPrice: 10})
+		require.NoError(t, err)
+
+		messageRepo := GetMessageRepository(logger, db)
+		aboutItem, err := messageRepo.CreateConversation(ctx,
+			&models.Conversation{UserAId: seller.Id, UserBId: buyer.Id, ItemId: &item.Id})
+		require.NoError(t, err)
+		_, err = messageRepo.CreateMessage(ctx,
+			&models.Message{ConversationId: aboutItem.Id, SenderId: buyer.Id, Body: "Hi"})
+		require.NoError(t, err)
+		general, err := messageRepo.CreateConversation(ctx,
+			&models.Conversation{UserAId: seller.Id, UserBId: buyer.Id})
+		require.NoError(t, err)
+
+		require.NoError(t, itemRepo.DeleteItem(ctx, item.Id, seller.Id))
+
+		_, err = messageRepo.GetConversation(ctx, aboutItem.Id)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+		_, err = messageRepo.GetConversation(ctx, general.Id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Idempotency keys are reserved once", func(t *testing.T) {
+		idempotencyRepo := GetIdempotencyRepository(logger, db)
+		now := time.Now().UTC()
+		key := &models.IdempotencyKey{UserId: seller.Id, Key: "key", RequestHash: "hash", LockToken: "first",
+			CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+
+		reserved, err := idempotencyRepo.ReserveIdempotencyKey(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		reserved, err = idempotencyRepo.ReserveIdempotencyKey(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+
+		other := *key
+		other.LockToken = "other"
+		other.ExpiresAt = now.Add(time.Hour)
+		assert.ErrorIs(t, idempotencyRepo.ExtendIdempotencyKey(ctx, &other), apperrors.NotFoundErr)
+		require.NoError(t, idempotencyRepo.DeleteIdempotencyKey(ctx, &other))
+		require.NoError(t, idempotencyRepo.ExtendIdempotencyKey(ctx, &models.IdempotencyKey{UserId: seller.Id,
+			Key: "key", LockToken: "first", ExpiresAt: now.Add(2 * time.Minute)}))
+
+		status := 201
+		contentType := "application/json"
+		completed := &models.IdempotencyKey{UserId: seller.Id, Key: "key", LockToken: "other", Status: &status,
+			ContentType: &contentType, ResponseBody: []byte(`{"id":1}`), ExpiresAt: now.Add(time.Hour)}
+		assert.ErrorIs(t, idempotencyRepo.CompleteIdempotencyKey(ctx, completed), apperrors.NotFoundErr)
+		completed.LockToken = "first"
+		require.NoError(t, idempotencyRepo.CompleteIdempotencyKey(ctx, completed))
+		assert.ErrorIs(t, idempotencyRepo.CompleteIdempotencyKey(ctx, completed), apperrors.NotFoundErr)
+
+		stored, err := idempotencyRepo.GetIdempotencyKey(ctx, seller.Id, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "hash", stored.RequestHash)
+		assert.Equal(t, 201, *stored.Status)
+		assert.Equal(t, `{"id":1}`, string(stored.ResponseBody))
+
+		// a completed key is kept for the retries
+		require.NoError(t, idempotencyRepo.DeleteIdempotencyKey(ctx, key))
+		_, err = idempotencyRepo.GetIdempotencyKey(ctx, seller.Id, "key")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Expired idempotency key is taken over", func(t *testing.T) {
+		idempotencyRepo := GetIdempotencyRepository(logger, db)
+		now := time.Now().UTC()
+		first := &models.IdempotencyKey{UserId: seller.Id, Key: "slow", RequestHash: "hash", LockToken: "first",
+			CreatedAt: now.Add(-2 * time.Minute), ExpiresAt: now.Add(-time.Minute)}
+		reserved, err := idempotencyRepo.ReserveIdempotencyKey(ctx, first)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		retry := &models.IdempotencyKey{UserId: seller.Id, Key: "slow", RequestHash: "hash", LockToken: "retry",
+			CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+		reserved, err = idempotencyRepo.ReserveIdempotencyKey(ctx, retry)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+
+		require.NoError(t, idempotencyRepo.DeleteIdempotencyKey(ctx, first))
+		stored, err := idempotencyRepo.GetIdempotencyKey(ctx, seller.Id, "slow")
+		require.NoError(t, err)
+		assert.Equal(t, "retry", stored.LockToken)
+	})
+}
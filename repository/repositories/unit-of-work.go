@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/log"
+)
+
+// UnitOfWork lets services run several repository calls in one transaction
+type UnitOfWork struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type UnitOfWorkInterface interface {
+	// InTransaction commits the changes made by fn if it returns nil and rolls them back otherwise.
+	// Repository calls must use the context passed to fn to join the transaction.
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func GetUnitOfWork(log *log.Logger, connection database.Database) UnitOfWorkInterface {
+	return &UnitOfWork{
+		log: log,
+		db:  connection,
+	}
+}
+
+func (u *UnitOfWork) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := u.db.InTransaction(ctx, fn)
+	if err != nil {
+		u.log.Debugln("transaction rolled back:", err)
+	}
+
+	return err
+}
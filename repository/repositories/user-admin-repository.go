@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"strings"
+	"time"
+	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+// UserAdminRepository holds the queries of the admin user management
+type UserAdminRepository struct {
+	log *log.Logger
+	db  database.Database
+}
+
+type UserAdminRepositoryInterface interface {
+	SearchUsers(ctx context.Context, query string, limit int, offset int) ([]*models.User, int, error)
+	GetUserById(ctx context.Context, id int) (*models.User, error)
+	GetUserActivity(ctx context.Context, userId int) (*models.UserActivity, error)
+	SetUserStatus(ctx context.Context, userId int, status string, suspendedUntil *time.Time, reason string) error
+	SetUserType(ctx context.Context, userId int, userTypeId int) error
+	AddModerationEvent(ctx context.Context, event *models.UserModerationEvent) error
+	GetModerationEvents(ctx context.Context, userId int) ([]*models.UserModerationEvent, error)
+}
+
+func GetUserAdminRepository(log *log.Logger, connection database.Database) UserAdminRepositoryInterface {
+	return &UserAdminRepository{
+		log: log,
+		db:  connection,
+	}
+}
+
+// SearchUsers matches the query against the email and the full name and returns one page and the total count
+func (r *UserAdminRepository) SearchUsers(ctx context.Context, query string, limit int, offset int) ([]*models.User,
+	int, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	// LOWER instead of ILIKE, which SQLite doesn't have
+	pattern := "%" + escapeLike(strings.ToLower(strings.TrimSpace(query))) + "%"
+	where := `WHERE LOWER(email) LIKE $1 ESCAPE '\' OR LOWER(first_name || ' ' || last_name) LIKE $1 ESCAPE '\'`
+
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM users "+where, pattern)
+	if err != nil {
+		r.log.Errorln("failed to count users", err)
+
+		return nil, 0, err
+	}
+
+	users := []*models.User{}
+	err = r.db.SelectContext(ctx, &users, "SELECT * FROM users "+where+" ORDER BY id LIMIT $2 OFFSET $3",
+		pattern, limit, offset)
+	if err != nil {
+		r.log.Errorln("failed to search users", err)
+
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *UserAdminRepository) GetUserById(ctx context.Context, id int) (*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var user models.User
+
+	err := r.db.GetContext(ctx, &user, "SELECT * FROM users WHERE id = $1", id)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get user from db", err)
+
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserAdminRepository) GetUserActivity(ctx context.Context, userId int) (*models.UserActivity, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var activity models.UserActivity
+
+	query := `SELECT
+				(SELECT COUNT(*) FROM items WHERE user_id = $1) AS items_count,
+				(SELECT COUNT(*) FROM item_comments WHERE user_id = $1) AS comments_count,
+				(SELECT COUNT(*) FROM sessions WHERE user_id = $1 AND revoked_at IS NULL) AS active_sessions_count,
+				(SELECT COUNT(*) FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL) AS api_keys_count`
+	err := r.db.GetContext(ctx, &activity, query, userId)
+	if err != nil {
+		r.log.Errorln("failed to get user activity", err)
+
+		return nil, err
+	}
+
+	return &activity, nil
+}
+
+func (r *UserAdminRepository) SetUserStatus(ctx context.Context, userId int, status string, suspendedUntil *time.Time,
+	reason string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET status = $1, suspended_until = $2, status_reason = $3 WHERE id = $4",
+		status, suspendedUntil, reason, userId)
+	if err != nil {
+		r.log.Errorln("failed to update user status", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+func (r *UserAdminRepository) SetUserType(ctx context.Context, userId int, userTypeId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "UPDATE users SET user_type_id = $1 WHERE id = $2", userTypeId, userId)
+	if err != nil {
+		r.log.Errorln("failed to update user type", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+func (r *UserAdminRepository) AddModerationEvent(ctx context.Context, event *models.UserModerationEvent) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	event.CreatedAt = time.Now().UTC()
+
+	insertQuery := `INSERT INTO user_moderation_events (user_id, admin_id, action, reason, details, created_at)
+					VALUES (:user_id, :admin_id, :action, :reason, :details, :created_at)`
+	_, err := r.db.NamedExecContext(ctx, insertQuery, event)
+	if err != nil {
+		r.log.Errorln("failed to insert moderation event into db", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *UserAdminRepository) GetModerationEvents(ctx context.Context,
+	userId int) ([]*models.UserModerationEvent, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	events := []*models.UserModerationEvent{}
+
+	err := r.db.SelectContext(ctx, &events,
+		"SELECT * FROM user_moderation_events WHERE user_id = $1 ORDER BY created_at DESC",
+		userId)
+	if err != nil {
+		r.log.Errorln("failed to get moderation events from db", err)
+
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// escapeLike makes % and _ typed by the admin match literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// requireRowsAffected returns NotFoundErr if an update didn't match any row
+func requireRowsAffected(log *log.Logger, result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Errorln("error checking rows affected", err)
+
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.NotFoundErr
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
 	"time"
 	"ypeskov/go_hillel_9/internal/database"
@@ -14,12 +15,17 @@ type UserRepository struct {
 }
 
 type UserRepositoryInterface interface {
-	GetUsersList() ([]*models.User, error)
-	CreateUser(srcUser *models.User) (*models.User, error)
-	GetUserByEmail(email string) *models.User
-	AddOrUpdateRefreshToken(userId int, token string) error
-	GetUserByRefreshToken(token string) *models.User
-	GetUserType(user *models.User) (*models.UserType, error)
+	GetUsersList(ctx context.Context) ([]*models.User, error)
+	CreateUser(ctx context.Context, srcUser *models.User) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) *models.User
+	GetUserById(ctx context.Context, id int) *models.User
+	GetUserByIdentity(ctx context.Context, issuer string, subject string) *models.User
+	AddUserIdentity(ctx context.Context, userId int, issuer string, subject string) error
+	UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error
+	GetUserType(ctx context.Context, user *models.User) (*models.UserType, error)
+	AddEmailVerificationToken(ctx context.Context, userId int, tokenHash string, expiresAt time.Time) error
+	GetUserByEmailVerificationToken(ctx context.Context, tokenHash string) *models.User
+	SetEmailVerified(ctx context.Context, userId int) error
 }
 
 func GetUserRepository(log *log.Logger, connection database.Database) UserRepositoryInterface {
@@ -29,10 +35,13 @@ func GetUserRepository(log *log.Logger, connection database.Database) UserReposi
 	}
 }
 
-func (r *UserRepository) GetUsersList() ([]*models.User, error) {
+func (r *UserRepository) GetUsersList(ctx context.Context) ([]*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	var users []*models.User
 
-	err := r.db.Select(&users, "SELECT * FROM users")
+	err := r.db.SelectContext(ctx, &users, "SELECT * FROM users")
 	if err != nil {
 		r.log.Error("failed to get users from db", err)
 
@@ -42,21 +51,28 @@ func (r *UserRepository) GetUsersList() ([]*models.User, error) {
 	return users, nil
 }
 
-func (r *UserRepository) CreateUser(srcUser *models.User) (*models.User, error) {
+func (r *UserRepository) CreateUser(ctx context.Context, srcUser *models.User) (*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	now := time.Now().UTC()
 	srcUser.LastLoginUtc = now
+	srcUser.CreatedAt = now
 
-	insertQuery := `INSERT INTO users (first_name, last_name, email, password_hash, last_login_utc, user_type_id) 
-                    VALUES (:first_name, :last_name, :email, :password_hash, :last_login_utc, :user_type_id) 
+	insertQuery := `INSERT INTO users (first_name, last_name, email, password_hash, last_login_utc, created_at,
+                                       user_type_id)
+                    VALUES (:first_name, :last_name, :email, :password_hash, :last_login_utc, :created_at,
+                            :user_type_id)
                     RETURNING *`
 
-	rows, err := r.db.NamedQuery(insertQuery, srcUser)
+	rows, err := r.db.NamedQueryContext(ctx, insertQuery, srcUser)
 	if err != nil {
 		r.log.Errorln("failed to insert srcUser into db", err)
 		r.log.Errorf("srcUser: %+v\n", srcUser)
 
 		return nil, err
 	}
+	defer rows.Close()
 
 	var newUser models.User
 	if rows.Next() {
@@ -73,10 +89,13 @@ func (r *UserRepository) CreateUser(srcUser *models.User) (*models.User, error)
 	return &newUser, nil
 }
 
-func (r *UserRepository) GetUserByEmail(email string) *models.User {
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) *models.User {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	var user models.User
 
-	err := r.db.Get(&user, "SELECT * FROM users WHERE email = $1", email)
+	err := r.db.GetContext(ctx, &user, "SELECT * FROM users WHERE email = $1", email)
 	if err != nil {
 		r.log.Error("failed to get user from db", err)
 
@@ -86,30 +105,33 @@ func (r *UserRepository) GetUserByEmail(email string) *models.User {
 	return &user
 }
 
-func (r *UserRepository) AddOrUpdateRefreshToken(userId int, token string) error {
-	query := `
-        INSERT INTO refresh_tokens (user_id, token, created_at)
-        VALUES ($1, $2, now())
-        ON CONFLICT (user_id) DO UPDATE
-        SET token = EXCLUDED.token, created_at = now()
-    `
-	_, err := r.db.Exec(query, userId, token)
+func (r *UserRepository) GetUserById(ctx context.Context, id int) *models.User {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var user models.User
+
+	err := r.db.GetContext(ctx, &user, "SELECT * FROM users WHERE id = $1", id)
 	if err != nil {
-		r.log.Error("failed to insert or update refresh token in db", err)
+		r.log.Error("failed to get user from db", err)
 
-		return err
+		return nil
 	}
 
-	return nil
+	return &user
 }
 
-func (r *UserRepository) GetUserByRefreshToken(token string) *models.User {
+// GetUserByIdentity finds the user linked to an account at an external identity provider
+func (r *UserRepository) GetUserByIdentity(ctx context.Context, issuer string, subject string) *models.User {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	var user models.User
 
-	err := r.db.Get(&user,
-		`SELECT u.* FROM users u JOIN refresh_tokens rt ON u.id = rt.user_id WHERE rt.token = $1`, token)
+	err := r.db.GetContext(ctx, &user, `SELECT u.* FROM users u JOIN user_identities ui ON u.id = ui.user_id
+							WHERE ui.issuer = $1 AND ui.subject = $2`, issuer, subject)
 	if err != nil {
-		r.log.Errorln("failed to get user from db with refresh token", err)
+		r.log.Error("failed to get user by identity from db", err)
 
 		return nil
 	}
@@ -117,10 +139,43 @@ func (r *UserRepository) GetUserByRefreshToken(token string) *models.User {
 	return &user
 }
 
-func (r *UserRepository) GetUserType(user *models.User) (*models.UserType, error) {
+func (r *UserRepository) AddUserIdentity(ctx context.Context, userId int, issuer string, subject string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO user_identities (user_id, issuer, subject, created_at) VALUES ($1, $2, $3, $4)",
+		userId, issuer, subject, time.Now().UTC())
+	if err != nil {
+		r.log.Errorln("failed to insert user identity into db", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET password_hash = $1 WHERE id = $2", passwordHash, userId)
+	if err != nil {
+		r.log.Errorln("failed to update password hash", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetUserType(ctx context.Context, user *models.User) (*models.UserType, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	var userType models.UserType
 
-	err := r.db.Get(&userType, "SELECT * FROM user_types WHERE id = $1", user.UserTypeId)
+	err := r.db.GetContext(ctx, &userType, "SELECT * FROM user_types WHERE id = $1", user.UserTypeId)
 	if err != nil {
 		r.log.Error("failed to get user type from db", err)
 
@@ -129,3 +184,59 @@ func (r *UserRepository) GetUserType(user *models.User) (*models.UserType, error
 
 	return &userType, nil
 }
+
+func (r *UserRepository) AddEmailVerificationToken(ctx context.Context, userId int, tokenHash string,
+	expiresAt time.Time) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO email_verification_tokens (user_id, token_hash, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4)`
+	_, err := r.db.ExecContext(ctx, query, userId, tokenHash, expiresAt, time.Now().UTC())
+	if err != nil {
+		r.log.Errorln("failed to insert email verification token into db", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetUserByEmailVerificationToken(ctx context.Context, tokenHash string) *models.User {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var user models.User
+
+	err := r.db.GetContext(ctx, &user,
+		`SELECT u.* FROM users u JOIN email_verification_tokens evt ON u.id = evt.user_id
+		 WHERE evt.token_hash = $1 AND evt.expires_at > $2`, tokenHash, time.Now().UTC())
+	if err != nil {
+		r.log.Errorln("failed to get user from db with email verification token", err)
+
+		return nil
+	}
+
+	return &user
+}
+
+func (r *UserRepository) SetEmailVerified(ctx context.Context, userId int) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET email_verified = TRUE WHERE id = $1", userId)
+	if err != nil {
+		r.log.Errorln("failed to set email verified", err)
+
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, "DELETE FROM email_verification_tokens WHERE user_id = $1", userId)
+	if err != nil {
+		r.log.Errorln("failed to delete email verification tokens", err)
+
+		return err
+	}
+
+	return nil
+}
@@ -1,7 +1,12 @@
 package repositories
 
 import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"time"
 	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
 	"ypeskov/go_hillel_9/internal/log"
 	"ypeskov/go_hillel_9/repository/models"
 )
@@ -12,7 +17,14 @@ type UserTypeRepository struct {
 }
 
 type UserTypeRepositoryInterface interface {
-	GetUserTypesList() ([]*models.UserType, error)
+	GetUserTypesList(ctx context.Context) ([]*models.UserType, error)
+	GetUserTypeById(ctx context.Context, id int) (*models.UserType, error)
+	GetUserTypeByCode(ctx context.Context, typeCode string) (*models.UserType, error)
+	CreateUserType(ctx context.Context, userType *models.UserType) (*models.UserType, error)
+	UpdateUserTypeDescription(ctx context.Context, id int, description string) error
+	RetireUserType(ctx context.Context, id int, replacementId int) (int, error)
+	SetMfaRequired(ctx context.Context, id int, required bool) error
+	SetSelfAssignable(ctx context.Context, id int, assignable bool) error
 }
 
 func GetUserTypeRepository(log *log.Logger, connection database.Database) UserTypeRepositoryInterface {
@@ -22,10 +34,13 @@ func GetUserTypeRepository(log *log.Logger, connection database.Database) UserTy
 	}
 }
 
-func (r *UserTypeRepository) GetUserTypesList() ([]*models.UserType, error) {
+func (r *UserTypeRepository) GetUserTypesList(ctx context.Context) ([]*models.UserType, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
 	var userTypes []*models.UserType
 
-	err := r.db.Select(&userTypes, "SELECT * FROM user_types")
+	err := r.db.SelectContext(ctx, &userTypes, "SELECT * FROM user_types ORDER BY id")
 	if err != nil {
 		r.log.Errorln("failed to get user_types from db", err)
 
@@ -34,3 +49,156 @@ func (r *UserTypeRepository) GetUserTypesList() ([]*models.UserType, error) {
 
 	return userTypes, nil
 }
+
+func (r *UserTypeRepository) GetUserTypeById(ctx context.Context, id int) (*models.UserType, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var userType models.UserType
+
+	err := r.db.GetContext(ctx, &userType, "SELECT * FROM user_types WHERE id = $1", id)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get user_type from db", err)
+
+		return nil, err
+	}
+
+	return &userType, nil
+}
+
+func (r *UserTypeRepository) GetUserTypeByCode(ctx context.Context, typeCode string) (*models.UserType, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var userType models.UserType
+
+	err := r.db.GetContext(ctx, &userType, "SELECT * FROM user_types WHERE type_code = $1", typeCode)
+	if err != nil {
+		if goerrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.NotFoundErr
+		}
+		r.log.Errorln("failed to get user_type from db", err)
+
+		return nil, err
+	}
+
+	return &userType, nil
+}
+
+func (r *UserTypeRepository) CreateUserType(ctx context.Context, userType *models.UserType) (*models.UserType, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var newUserType models.UserType
+
+	err := r.db.GetContext(ctx, &newUserType,
+		`INSERT INTO user_types (type_name, type_description, type_code, mfa_required, self_assignable)
+								   VALUES ($1, $2, $3, $4, $5) RETURNING *`,
+		userType.TypeName, userType.TypeDescription, userType.TypeCode, userType.MfaRequired,
+		userType.SelfAssignable)
+	if err != nil {
+		r.log.Errorln("failed to insert user_type", err)
+
+		return nil, err
+	}
+
+	return &newUserType, nil
+}
+
+func (r *UserTypeRepository) UpdateUserTypeDescription(ctx context.Context, id int, description string) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "UPDATE user_types SET type_description = $1 WHERE id = $2", description, id)
+	if err != nil {
+		r.log.Errorln("failed to update user_type", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
+
+// RetireUserType moves the users of the type to the replacement and marks the type as retired,
+// it returns the number of moved users
+func (r *UserTypeRepository) RetireUserType(ctx context.Context, id int, replacementId int) (int, error) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var moved int64
+	err := r.db.InTransaction(ctx, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx,
+			"UPDATE user_types SET retired_at = $1 WHERE id = $2 AND retired_at IS NULL", time.Now().UTC(), id)
+		if err != nil {
+			r.log.Errorln("failed to retire user_type", err)
+
+			return err
+		}
+		err = requireRowsAffected(r.log, result)
+		if err != nil {
+			return err
+		}
+
+		result, err = r.db.ExecContext(ctx, "UPDATE users SET user_type_id = $1 WHERE user_type_id = $2",
+			replacementId, id)
+		if err != nil {
+			r.log.Errorln("failed to reassign users", err)
+
+			return err
+		}
+		moved, err = result.RowsAffected()
+		if err != nil {
+			r.log.Errorln("error checking rows affected", err)
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(moved), nil
+}
+
+func (r *UserTypeRepository) SetMfaRequired(ctx context.Context, id int, required bool) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "UPDATE user_types SET mfa_required = $1 WHERE id = $2", required, id)
+	if err != nil {
+		r.log.Errorln("failed to update user_type", err)
+
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log.Errorln("error checking rows affected", err)
+
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.NotFoundErr
+	}
+
+	return nil
+}
+
+func (r *UserTypeRepository) SetSelfAssignable(ctx context.Context, id int, assignable bool) error {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "UPDATE user_types SET self_assignable = $1 WHERE id = $2", assignable, id)
+	if err != nil {
+		r.log.Errorln("failed to update user_type", err)
+
+		return err
+	}
+
+	return requireRowsAffected(r.log, result)
+}
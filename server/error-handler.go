@@ -0,0 +1,100 @@
+package server
+
+import (
+	goerrors "errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/i18n"
+	"ypeskov/go_hillel_9/internal/log"
+)
+
+// errorHandler writes the errors returned by handlers and middleware. Errors of the errors package are
+// responded with their own status, any other error is logged and hidden behind InternalServerErr.
+// Messages are translated to the language negotiated from the Accept-Language header.
+func errorHandler(logger *log.Logger, catalogue *i18n.Catalogue) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		status, body := errorResponse(logger, err)
+
+		language := catalogue.Negotiate(c.Request().Header.Get("Accept-Language"))
+		c.Response().Header().Set("Content-Language", language)
+		c.Response().Header().Add("Vary", "Accept-Language")
+
+		body = localize(catalogue, language, body)
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(status)
+		} else {
+			err = c.JSON(status, body)
+		}
+		if err != nil {
+			logger.Errorln("failed to write error response", err)
+		}
+	}
+}
+
+func errorResponse(logger *log.Logger, err error) (int, interface{}) {
+	var validationErr *errors.ValidationError
+	if goerrors.As(err, &validationErr) {
+		return validationErr.HTTPStatus(), validationErr
+	}
+
+	var appErr errors.Error
+	if goerrors.As(err, &appErr) {
+		return appErr.HTTPStatus(), appErr
+	}
+
+	var appErrPtr *errors.Error
+	if goerrors.As(err, &appErrPtr) && appErrPtr != nil {
+		return appErrPtr.HTTPStatus(), appErrPtr
+	}
+
+	// errors of Echo itself, e.g. unknown routes
+	var httpErr *echo.HTTPError
+	if goerrors.As(err, &httpErr) {
+		message, ok := httpErr.Message.(string)
+		if !ok {
+			message = http.StatusText(httpErr.Code)
+		}
+
+		return httpErr.Code, errors.Error{
+			Code:    strings.ToUpper(strings.ReplaceAll(http.StatusText(httpErr.Code), " ", "_")),
+			Message: message,
+		}
+	}
+
+	logger.Errorln("unhandled error", err)
+
+	return http.StatusInternalServerError, errors.InternalServerErr
+}
+
+// localize returns a copy of the response body with the messages in the language, the codes stay the same.
+// Messages given by WithMessage are kept, the catalogue only has the general message of the code.
+func localize(catalogue *i18n.Catalogue, language string, body interface{}) interface{} {
+	switch body := body.(type) {
+	case errors.Error:
+		if !body.Detailed() {
+			body.Message = catalogue.ErrorMessage(language, body.Code, body.Message)
+		}
+
+		return body
+	case *errors.Error:
+		return localize(catalogue, language, *body)
+	case *errors.ValidationError:
+		localized := *body
+		localized.Message = catalogue.ErrorMessage(language, body.Code, body.Message)
+		localized.Fields = make([]errors.FieldError, len(body.Fields))
+		for i, field := range body.Fields {
+			field.Message = catalogue.FieldMessage(language, field.Field, field.Rule, field.Param)
+			localized.Fields[i] = field
+		}
+
+		return &localized
+	default:
+		return body
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/i18n"
+)
+
+func TestLocalize(t *testing.T) {
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+	catalogue, err := i18n.New(cfg)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		body            interface{}
+		expectedMessage string
+	}{
+		{"Default message is translated", errors.WeakPasswordErr, "Пароль не відповідає вимогам до паролів"},
+		{"Pointer is translated", &errors.NotFoundErr, "Не знайдено"},
+		{"Detailed message is kept", errors.WeakPasswordErr.WithMessage("password must be at least 12 characters"),
+			"password must be at least 12 characters"},
+		{"Import row details are kept", errors.InvalidImportRowErr.WithMessage("initialPrice is not a number"),
+			"initialPrice is not a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localized, ok := localize(catalogue, "uk", tt.body).(errors.Error)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedMessage, localized.Message)
+		})
+	}
+}
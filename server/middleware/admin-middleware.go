@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/services"
+)
+
+// AdminMiddleware must run after AuthMiddleware, it lets only administrators through
+func AdminMiddleware(logger *log.Logger, userService services.UsersServiceInterface) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			user, ok := c.Get("user").(*models.User)
+			if !ok || user == nil {
+				logger.Errorln("user is not in context")
+
+				return errors.UnauthorizedErr
+			}
+
+			userType, err := userService.GetUserType(ctx, user)
+			if err != nil {
+				logger.Errorln("failed to get user type", err)
+
+				return errors.InternalServerErr
+			}
+
+			if userType.TypeCode != models.AdminTypeCode {
+				logger.Errorf("user %d is not an admin", user.Id)
+
+				return errors.ForbiddenErr
+			}
+
+			return next(c)
+		}
+	}
+}
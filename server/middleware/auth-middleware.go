@@ -4,58 +4,152 @@ import (
 	goerrors "errors"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
-	"net/http"
 	"strings"
-	"ypeskov/go_hillel_9/internal/config"
 	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/jwtkeys"
 	"ypeskov/go_hillel_9/internal/log"
 	"ypeskov/go_hillel_9/services"
 )
 
-const numberPartsOfToken = 2
+const (
+	numberPartsOfToken = 2
+	ApiKeyHeader       = "X-Api-Key"
+)
+
+// AuthMiddleware accepts an access token in the Auth-Token header or an API key in the X-Api-Key header
+func AuthMiddleware(logger *log.Logger, keys *jwtkeys.KeySet,
+	userService services.UsersServiceInterface,
+	revocationService services.RevocationServiceInterface,
+	apiKeysService services.ApiKeysServiceInterface) echo.MiddlewareFunc {
+	return authMiddleware(logger, keys, userService, revocationService, apiKeysService, false)
+}
+
+// SessionAuthMiddleware accepts only access tokens, it guards routes that manage
+// sessions and credentials, which must not be reachable with an API key
+func SessionAuthMiddleware(logger *log.Logger, keys *jwtkeys.KeySet,
+	userService services.UsersServiceInterface,
+	revocationService services.RevocationServiceInterface) echo.MiddlewareFunc {
+	return authMiddleware(logger, keys, userService, revocationService, nil, false)
+}
 
-func AuthMiddleware(logger *log.Logger, cfg *config.Config,
-	userService services.UsersServiceInterface) echo.MiddlewareFunc {
+// MfaEnrollmentAuthMiddleware also lets through users who must enable two-factor
+// authentication first, it guards the routes they need to do so
+func MfaEnrollmentAuthMiddleware(logger *log.Logger, keys *jwtkeys.KeySet,
+	userService services.UsersServiceInterface,
+	revocationService services.RevocationServiceInterface) echo.MiddlewareFunc {
+	return authMiddleware(logger, keys, userService, revocationService, nil, true)
+}
+
+// authMiddleware accepts API keys only when apiKeysService is set
+func authMiddleware(logger *log.Logger, keys *jwtkeys.KeySet,
+	userService services.UsersServiceInterface,
+	revocationService services.RevocationServiceInterface,
+	apiKeysService services.ApiKeysServiceInterface, allowMfaEnrollment bool) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			apiKeyHeader := c.Request().Header.Get(ApiKeyHeader)
+			if apiKeyHeader != "" && c.Request().Header.Get("Auth-Token") == "" {
+				if apiKeysService == nil {
+					logger.Errorln("api keys are not accepted by this route")
+
+					return errors.ForbiddenErr
+				}
+
+				user, apiKey, err := apiKeysService.Authenticate(ctx, apiKeyHeader)
+				if err != nil {
+					if goerrors.Is(err, errors.InvalidApiKeyErr) {
+						logger.Errorln("invalid api key")
+
+						return errors.InvalidApiKeyErr
+					}
+					logger.Errorln("failed to check api key", err)
+
+					return errors.InternalServerErr
+				}
+
+				if err := services.CheckAccountStatus(user); err != nil {
+					logger.Errorf("user %d is blocked", user.Id)
+
+					return err
+				}
+
+				c.Set("user", user)
+				c.Set("apiKey", apiKey)
+
+				return next(c)
+			}
+
 			authTokenHeader := c.Request().Header.Get("Auth-Token")
 
 			if authTokenHeader == "" {
 				logger.Errorln("auth token is empty")
 
-				return c.JSON(http.StatusUnauthorized, errors.UnauthorizedErr)
+				return errors.UnauthorizedErr
 			}
 
 			tokenHeaderParts := strings.Split(authTokenHeader, "Bearer ")
 			if len(tokenHeaderParts) != numberPartsOfToken {
 				logger.Errorln("invalid token format. Expected [Bearer <token>]")
 
-				return c.JSON(http.StatusUnauthorized, errors.UnauthorizedErr)
+				return errors.UnauthorizedErr
 			}
 			token := tokenHeaderParts[1]
 
 			claims := &services.Claims{}
-			_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
-				return []byte(cfg.SecretKey), nil
-			})
+			_, err := keys.ParseWithClaims(token, claims)
 			if err != nil {
 				if goerrors.Is(err, jwt.ErrTokenExpired) {
 					logger.Errorln("token expired")
 
-					return c.JSON(http.StatusUnauthorized, errors.TokenExpiredErr)
+					return errors.TokenExpiredErr
 				}
 				logger.Errorln("failed to parse token", err)
 
-				return c.JSON(http.StatusUnauthorized, errors.UnauthorizedErr)
+				return errors.UnauthorizedErr
 			}
 
-			user := userService.GetUserByEmail(claims.Email)
+			if claims.TokenUse != services.AccessTokenUse {
+				logger.Errorln("token is not an access token")
+
+				return errors.InvalidTokenTypeErr
+			}
+
+			user := userService.GetUserByEmail(ctx, claims.Email)
 			if user == nil {
 				logger.Errorln("user not found")
 
-				return c.JSON(http.StatusUnauthorized, errors.UnauthorizedErr)
+				return errors.UnauthorizedErr
+			}
+
+			revoked, err := revocationService.IsRevoked(ctx, claims, user)
+			if err != nil {
+				logger.Errorln("failed to check token revocation", err)
+
+				return errors.InternalServerErr
+			}
+			if revoked {
+				logger.Errorln("token is revoked")
+
+				return errors.TokenRevokedErr
+			}
+
+			err = services.CheckAccountStatus(user)
+			if err != nil {
+				logger.Errorf("user %d is blocked", user.Id)
+
+				return err
 			}
+
+			if claims.MfaEnrollmentRequired && !user.MfaEnabled && !allowMfaEnrollment {
+				logger.Errorln("two-factor authentication must be enabled first")
+
+				return errors.MfaEnrollmentRequiredErr
+			}
+
 			c.Set("user", user)
+			c.Set("sessionId", claims.SessionId)
+			c.Set("claims", claims)
 
 			return next(c)
 		}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	goerrors "errors"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/services"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1024 * 1024
+)
+
+// IdempotencyMiddleware must run after AuthMiddleware. A request with an Idempotency-Key header is handled
+// once per user and key, retries get the stored status and body of the first response. Responses with
+// a server error aren't stored, so the request can be retried.
+func IdempotencyMiddleware(logger *log.Logger, idempotency services.IdempotencyServiceInterface) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return errors.InvalidIdempotencyKeyErr
+			}
+
+			user, ok := c.Get("user").(*models.User)
+			if !ok || user == nil {
+				logger.Errorln("user is not in context")
+
+				return errors.UnauthorizedErr
+			}
+
+			// the body is read to compare it with the first request and put back for the handler
+			body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxIdempotentRequestBytes+1))
+			if err != nil || len(body) > maxIdempotentRequestBytes {
+				return errors.IncorrectReqBodyErr
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := c.Request().Context()
+			stored, err := idempotency.Begin(ctx, user.Id, key, requestHash(c.Request(), body))
+			if err != nil {
+				logger.Errorf("idempotency key of user %d rejected: %v", user.Id, err)
+
+				return err
+			}
+			if stored.Status != nil {
+				c.Response().Header().Set(IdempotentReplayedHeader, "true")
+
+				return c.Blob(*stored.Status, stringValue(stored.ContentType), stored.ResponseBody)
+			}
+
+			return handleOnce(c, next, logger, idempotency, stored)
+		}
+	}
+}
+
+// handleOnce runs the handler with the key reserved and stores its response. Errors returned by the handler
+// are written here by the error handler of the server, so their responses are stored too.
+func handleOnce(c echo.Context, next echo.HandlerFunc, logger *log.Logger,
+	idempotency services.IdempotencyServiceInterface, reservation *models.IdempotencyKey) error {
+	recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+	c.Response().Writer = recorder
+
+	done := make(chan struct{})
+	go extendWhileRunning(logger, idempotency, reservation, done)
+
+	handled := false
+	// the key is released even if the handler panics, the request context may be canceled by then
+	defer func() {
+		close(done)
+		if !handled {
+			err := idempotency.Release(context.Background(), reservation)
+			if err != nil {
+				logger.Errorln("failed to release idempotency key", err)
+			}
+		}
+	}()
+
+	err := next(c)
+	if err != nil {
+		c.Error(err)
+	}
+
+	status := c.Response().Status
+	if status >= http.StatusInternalServerError {
+		return nil
+	}
+
+	// the request has had its effect, so the key isn't released even if the response can't be stored.
+	// It stays in progress until the lock times out instead of letting a retry run the request again right away.
+	handled = true
+	err = idempotency.Complete(context.Background(), reservation, status,
+		c.Response().Header().Get(echo.HeaderContentType), recorder.body.Bytes())
+	if err != nil {
+		logger.Errorln("failed to store idempotent response", err)
+	}
+
+	return nil
+}
+
+// extendWhileRunning extends the lock of the key until done is closed, so a retry doesn't take over
+// the key of a handler running longer than the lock timeout
+func extendWhileRunning(logger *log.Logger, idempotency services.IdempotencyServiceInterface,
+	reservation *models.IdempotencyKey, done <-chan struct{}) {
+	ticker := time.NewTicker(services.IdempotencyLockTimeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			err := idempotency.Extend(context.Background(), reservation)
+			if goerrors.Is(err, errors.NotFoundErr) {
+				return
+			}
+			if err != nil {
+				logger.Errorln("failed to extend idempotency key", err)
+			}
+		}
+	}
+}
+
+// requestHash identifies the request a key was used for by its method, path and body
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the body written to the client
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
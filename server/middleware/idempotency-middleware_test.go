@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	goerrors "errors"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/services"
+)
+
+// idempotencyServiceStub reserves every key and records what the middleware does with the reservation
+type idempotencyServiceStub struct {
+	services.IdempotencyServiceInterface
+	reservation *models.IdempotencyKey
+	completeErr error
+	completed   []*models.IdempotencyKey
+	released    []*models.IdempotencyKey
+}
+
+func (s *idempotencyServiceStub) Begin(ctx context.Context, userId int, key string,
+	requestHash string) (*models.IdempotencyKey, error) {
+	return s.reservation, nil
+}
+
+func (s *idempotencyServiceStub) Complete(ctx context.Context, reservation *models.IdempotencyKey, status int,
+	contentType string, body []byte) error {
+	s.completed = append(s.completed, reservation)
+
+	return s.completeErr
+}
+
+func (s *idempotencyServiceStub) Release(ctx context.Context, reservation *models.IdempotencyKey) error {
+	s.released = append(s.released, reservation)
+
+	return nil
+}
+
+func TestIdempotencyMiddlewareRelease(t *testing.T) {
+	cfg, _ := config.NewConfig()
+	logger := log.New(cfg)
+
+	tests := []struct {
+		name          string
+		status        int
+		completeErr   error
+		expectStored  bool
+		expectRelease bool
+	}{
+		{"Success is stored", http.StatusCreated, nil, true, false},
+		{"Success isn't released when storing fails", http.StatusCreated, goerrors.New("db down"), true, false},
+		{"Server error is released", http.StatusInternalServerError, nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &idempotencyServiceStub{
+				reservation: &models.IdempotencyKey{UserId: 1, Key: "key", LockToken: "token"},
+				completeErr: tt.completeErr,
+			}
+			handler := IdempotencyMiddleware(logger, stub)(func(c echo.Context) error {
+				return c.NoContent(tt.status)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/items/", nil)
+			req.Header.Set(IdempotencyKeyHeader, "key")
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+			c.Set("user", &models.User{Id: 1})
+
+			assert.NoError(t, handler(c))
+			assert.Equal(t, tt.expectStored, len(stub.completed) == 1)
+			if tt.expectRelease {
+				assert.Equal(t, []*models.IdempotencyKey{stub.reservation}, stub.released)
+			} else {
+				assert.Empty(t, stub.released)
+			}
+		})
+	}
+}
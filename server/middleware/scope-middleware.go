@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+// ScopeMiddleware must run after AuthMiddleware. Requests authenticated with an API key need
+// readScope for GET and HEAD requests and writeScope for the rest, access tokens are not limited.
+func ScopeMiddleware(logger *log.Logger, readScope string, writeScope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			apiKey, ok := c.Get("apiKey").(*models.ApiKey)
+			if !ok {
+				return next(c)
+			}
+
+			scope := writeScope
+			method := c.Request().Method
+			if method == http.MethodGet || method == http.MethodHead {
+				scope = readScope
+			}
+
+			if !apiKey.HasScope(scope) {
+				logger.Errorf("api key %d doesn't have scope %s", apiKey.Id, scope)
+
+				return errors.InsufficientScopeErr
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package routes
+
+import (
+	goerrors "errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/validation"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type UnlockLoginRequest struct {
+	Email string `json:"email" validate:"required_without=Ip,omitempty,email"`
+	Ip    string `json:"ip" validate:"required_without=Email,omitempty,ip"`
+}
+
+type MfaRequiredRequest struct {
+	Required bool `json:"required"`
+}
+
+func (r *Routes) RegisterAdminRoutes(g *echo.Group) {
+	g.POST("/login-lockouts/unlock/", r.unlockLogin)
+	g.PUT("/user-types/:id/mfa-required", r.setMfaRequired)
+
+	r.registerAdminUserTypesRoutes(g)
+	r.registerAdminUsersRoutes(g)
+	r.registerAdminReviewsRoutes(g)
+}
+
+// unlockLogin lifts a login lockout of an account or an IP address.
+// @summary Unlock Login
+// @tags Admin
+// @description Resets failed login attempts of an email or an IP address. Available to administrators only.
+// @accept json
+// @produce json
+// @param request body UnlockLoginRequest true "Email or IP to unlock"
+// @success 204 "Unlocked"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 403 {object} errors.Error "Forbidden"
+// @router /admin/login-lockouts/unlock/ [post]
+func (r *Routes) unlockLogin(c echo.Context) error {
+	r.Log.Infof("Unlocking login ...")
+	ctx := c.Request().Context()
+
+	req := new(UnlockLoginRequest)
+	err := c.Bind(req)
+	if err != nil {
+		r.Log.Errorln("failed to parse request body", err)
+
+		return errors.BadRequestErr
+	}
+
+	err = validation.Struct(req)
+	if err != nil {
+		r.Log.Errorln("failed to validate request body", err)
+
+		return err
+	}
+
+	admin := c.Get("user").(*models.User)
+
+	if req.Email != "" {
+		err = r.LoginAttemptsService.Unlock(ctx, models.LoginSubjectAccount, req.Email, admin.Id)
+		if err != nil {
+			r.Log.Errorln("failed to unlock account login", err)
+
+			return errors.InternalServerErr
+		}
+	}
+
+	if req.Ip != "" {
+		err = r.LoginAttemptsService.Unlock(ctx, models.LoginSubjectIp, req.Ip, admin.Id)
+		if err != nil {
+			r.Log.Errorln("failed to unlock ip login", err)
+
+			return errors.InternalServerErr
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// setMfaRequired makes two-factor authentication mandatory for a user type.
+// @summary Require Two-Factor Authentication
+// @tags Admin
+// @description Users of the type without two-factor authentication can only enroll it until they do.
+// @accept json
+// @produce json
+// @param id path int true "ID of the user type"
+// @param request body MfaRequiredRequest true "Whether two-factor authentication is required"
+// @success 204 "Updated"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 403 {object} errors.Error "Forbidden"
+// @failure 404 {object} errors.Error "User type not found"
+// @router /admin/user-types/{id}/mfa-required [put]
+func (r *Routes) setMfaRequired(c echo.Context) error {
+	r.Log.Infof("Setting mfa requirement of user type %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(MfaRequiredRequest)
+	err = c.Bind(req)
+	if err != nil {
+		r.Log.Errorln("failed to parse request body", err)
+
+		return errors.BadRequestErr
+	}
+
+	err = r.UserTypeService.SetMfaRequired(ctx, id, req.Required)
+	if err != nil {
+		if goerrors.Is(err, errors.NotFoundErr) {
+			r.Log.Errorln("user type not found", err)
+
+			return errors.UserTypeNotFoundErr
+		}
+		r.Log.Errorln("failed to update user type", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
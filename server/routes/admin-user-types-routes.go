@@ -0,0 +1,215 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type CreateUserTypeRequest struct {
+	TypeName        string `json:"typeName" validate:"required,max=255"`
+	TypeDescription string `json:"typeDescription"`
+	TypeCode        string `json:"typeCode" validate:"required,max=255"`
+	MfaRequired     bool   `json:"mfaRequired"`
+	SelfAssignable  bool   `json:"selfAssignable"`
+}
+
+type UpdateUserTypeRequest struct {
+	TypeDescription string `json:"typeDescription"`
+}
+
+type RetireUserTypeRequest struct {
+	ReplacementTypeCode string `json:"replacementTypeCode" validate:"required"`
+}
+
+type RetireUserTypeResponse struct {
+	MovedUsers int `json:"movedUsers"`
+}
+
+type SelfAssignableRequest struct {
+	SelfAssignable bool `json:"selfAssignable"`
+}
+
+func (r *Routes) registerAdminUserTypesRoutes(g *echo.Group) {
+	g.GET("/user-types/", r.getUserTypesList)
+	g.POST("/user-types/", r.createUserType)
+	g.PUT("/user-types/:id", r.updateUserType)
+	g.POST("/user-types/:id/retire", r.retireUserType)
+	g.PUT("/user-types/:id/self-assignable", r.setSelfAssignable)
+}
+
+// getUserTypesList retrieves all user types including retired ones.
+// @summary List User Types
+// @tags Admin
+// @description Retrieves all user types. Retired types have the retiredAt field.
+// @produce json
+// @success 200 {array} models.UserType "List of user types"
+// @failure 403 {object} errors.Error "Forbidden"
+// @router /admin/user-types/ [get]
+func (r *Routes) getUserTypesList(c echo.Context) error {
+	r.Log.Infof("Getting user types list ...")
+	ctx := c.Request().Context()
+
+	userTypes, err := r.UserTypeService.GetUserTypesList(ctx)
+	if err != nil {
+		r.Log.Errorln("failed to get user types list", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.JSON(http.StatusOK, userTypes)
+}
+
+// createUserType adds a new user type.
+// @summary Create User Type
+// @tags Admin
+// @description Creates a user type. The code must be unique, it is converted to upper case and may contain letters, digits and underscores.
+// @description Only self-assignable types can be chosen at sign-up.
+// @accept json
+// @produce json
+// @param request body CreateUserTypeRequest true "New user type"
+// @success 201 {object} models.UserType "Created user type"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 409 {object} errors.Error "User type with this code already exists"
+// @router /admin/user-types/ [post]
+func (r *Routes) createUserType(c echo.Context) error {
+	r.Log.Infof("Creating user type ...")
+	ctx := c.Request().Context()
+
+	req := new(CreateUserTypeRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	userType, err := r.UserTypeService.CreateUserType(ctx, &models.UserType{
+		TypeName:        req.TypeName,
+		TypeDescription: req.TypeDescription,
+		TypeCode:        req.TypeCode,
+		MfaRequired:     req.MfaRequired,
+		SelfAssignable:  req.SelfAssignable,
+	})
+	if err != nil {
+		r.Log.Errorln("failed to create user type", err)
+
+		return notFoundAs(err, errors.UserTypeNotFoundErr)
+	}
+
+	return c.JSON(http.StatusCreated, userType)
+}
+
+// updateUserType changes the description of a user type.
+// @summary Update User Type
+// @tags Admin
+// @description Changes the description of a user type.
+// @accept json
+// @produce json
+// @param id path int true "ID of the user type"
+// @param request body UpdateUserTypeRequest true "New description"
+// @success 204 "Updated"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 404 {object} errors.Error "User type not found"
+// @router /admin/user-types/{id} [put]
+func (r *Routes) updateUserType(c echo.Context) error {
+	r.Log.Infof("Updating user type %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(UpdateUserTypeRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	err = r.UserTypeService.UpdateUserTypeDescription(ctx, id, req.TypeDescription)
+	if err != nil {
+		r.Log.Errorln("failed to update user type", err)
+
+		return notFoundAs(err, errors.UserTypeNotFoundErr)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// retireUserType closes a user type and moves its users to another type.
+// @summary Retire User Type
+// @tags Admin
+// @description Retires a user type. Its users are moved to the replacement type and new users can't get it.
+// @description Built-in types can't be retired.
+// @accept json
+// @produce json
+// @param id path int true "ID of the user type"
+// @param request body RetireUserTypeRequest true "Code of the type that gets the users"
+// @success 200 {object} RetireUserTypeResponse "Number of moved users"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 404 {object} errors.Error "User type not found"
+// @router /admin/user-types/{id}/retire [post]
+func (r *Routes) retireUserType(c echo.Context) error {
+	r.Log.Infof("Retiring user type %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(RetireUserTypeRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	moved, err := r.UserTypeService.RetireUserType(ctx, id, req.ReplacementTypeCode)
+	if err != nil {
+		r.Log.Errorln("failed to retire user type", err)
+
+		return notFoundAs(err, errors.UserTypeNotFoundErr)
+	}
+
+	return c.JSON(http.StatusOK, RetireUserTypeResponse{MovedUsers: moved})
+}
+
+// setSelfAssignable opens a user type to sign-up or closes it.
+// @summary Set User Type Self-Assignable
+// @tags Admin
+// @description Opens a user type to sign-up or closes it. The administrator type can't be opened.
+// @accept json
+// @produce json
+// @param id path int true "ID of the user type"
+// @param request body SelfAssignableRequest true "Whether the type can be chosen at sign-up"
+// @success 204 "Updated"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 404 {object} errors.Error "User type not found"
+// @router /admin/user-types/{id}/self-assignable [put]
+func (r *Routes) setSelfAssignable(c echo.Context) error {
+	r.Log.Infof("Setting self-assignable for user type %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(SelfAssignableRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	err = r.UserTypeService.SetSelfAssignable(ctx, id, req.SelfAssignable)
+	if err != nil {
+		r.Log.Errorln("failed to set self-assignable", err)
+
+		return notFoundAs(err, errors.UserTypeNotFoundErr)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
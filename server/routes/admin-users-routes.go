@@ -0,0 +1,268 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/validation"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type SuspendUserRequest struct {
+	Until  time.Time `json:"until" validate:"required"`
+	Reason string    `json:"reason" validate:"required"`
+}
+
+type ModerationRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type ChangeUserTypeRequest struct {
+	UserTypeCode string `json:"userTypeCode" validate:"required"`
+}
+
+func (r *Routes) registerAdminUsersRoutes(g *echo.Group) {
+	g.GET("/users/", r.searchUsers)
+	g.GET("/users/:id", r.getUserDetails)
+	g.POST("/users/:id/suspend", r.suspendUser)
+	g.POST("/users/:id/ban", r.banUser)
+	g.POST("/users/:id/reactivate", r.reactivateUser)
+	g.PUT("/users/:id/role", r.changeUserType)
+}
+
+// searchUsers finds users by email or name.
+// @summary Search Users
+// @tags Admin
+// @description Searches users by a part of the email or the name. Without a query all users are returned.
+// @produce json
+// @param q query string false "Part of the email or the name"
+// @param page query int false "Page number, starting from 1"
+// @param perPage query int false "Users per page, at most 100"
+// @success 200 {object} services.UsersPage "Page of users"
+// @failure 403 {object} errors.Error "Forbidden"
+// @router /admin/users/ [get]
+func (r *Routes) searchUsers(c echo.Context) error {
+	r.Log.Infof("Searching users ...")
+	ctx := c.Request().Context()
+
+	// invalid numbers fall back to the defaults
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	perPage, _ := strconv.Atoi(c.QueryParam("perPage"))
+
+	result, err := r.UserAdminService.SearchUsers(ctx, c.QueryParam("q"), page, perPage)
+	if err != nil {
+		r.Log.Errorln("failed to search users", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// getUserDetails retrieves a user with their items and activity.
+// @summary Get User Details
+// @tags Admin
+// @description Retrieves a user, their items, activity counts and moderation history.
+// @produce json
+// @param id path int true "ID of the user"
+// @success 200 {object} services.UserDetails "User details"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "User not found"
+// @router /admin/users/{id} [get]
+func (r *Routes) getUserDetails(c echo.Context) error {
+	r.Log.Infof("Getting user details with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	details, err := r.UserAdminService.GetUserDetails(ctx, id)
+	if err != nil {
+		r.Log.Errorln("failed to get user details", err)
+
+		return notFoundAs(err, errors.NotFoundErr.WithMessage("User or user type not found"))
+	}
+
+	return c.JSON(http.StatusOK, details)
+}
+
+// suspendUser blocks a user until a date.
+// @summary Suspend User
+// @tags Admin
+// @description Suspends a user until the given time. The user's tokens and API keys are rejected right away.
+// @accept json
+// @produce json
+// @param id path int true "ID of the user"
+// @param request body SuspendUserRequest true "End of the suspension and its reason"
+// @success 204 "Suspended"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 404 {object} errors.Error "User not found"
+// @router /admin/users/{id}/suspend [post]
+func (r *Routes) suspendUser(c echo.Context) error {
+	r.Log.Infof("Suspending user with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(SuspendUserRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	admin := c.Get("user").(*models.User)
+	err = r.UserAdminService.SuspendUser(ctx, admin, id, req.Until, req.Reason)
+	if err != nil {
+		r.Log.Errorln("failed to suspend user", err)
+
+		return notFoundAs(err, errors.NotFoundErr.WithMessage("User or user type not found"))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// banUser blocks a user permanently.
+// @summary Ban User
+// @tags Admin
+// @description Bans a user. The user's tokens and API keys are rejected right away.
+// @accept json
+// @produce json
+// @param id path int true "ID of the user"
+// @param request body ModerationRequest true "Reason of the ban"
+// @success 204 "Banned"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 404 {object} errors.Error "User not found"
+// @router /admin/users/{id}/ban [post]
+func (r *Routes) banUser(c echo.Context) error {
+	r.Log.Infof("Banning user with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(ModerationRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	admin := c.Get("user").(*models.User)
+	err = r.UserAdminService.BanUser(ctx, admin, id, req.Reason)
+	if err != nil {
+		r.Log.Errorln("failed to ban user", err)
+
+		return notFoundAs(err, errors.NotFoundErr.WithMessage("User or user type not found"))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// reactivateUser lifts a suspension or a ban.
+// @summary Reactivate User
+// @tags Admin
+// @description Lifts a suspension or a ban. The user has to log in again.
+// @accept json
+// @produce json
+// @param id path int true "ID of the user"
+// @param request body ModerationRequest true "Reason of the reactivation"
+// @success 204 "Reactivated"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 404 {object} errors.Error "User not found"
+// @router /admin/users/{id}/reactivate [post]
+func (r *Routes) reactivateUser(c echo.Context) error {
+	r.Log.Infof("Reactivating user with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(ModerationRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	admin := c.Get("user").(*models.User)
+	err = r.UserAdminService.ReactivateUser(ctx, admin, id, req.Reason)
+	if err != nil {
+		r.Log.Errorln("failed to reactivate user", err)
+
+		return notFoundAs(err, errors.NotFoundErr.WithMessage("User or user type not found"))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// changeUserType changes the role of a user.
+// @summary Change User Role
+// @tags Admin
+// @description Changes the user type of a user, e.g. promotes a buyer to a seller or an administrator.
+// @accept json
+// @produce json
+// @param id path int true "ID of the user"
+// @param request body ChangeUserTypeRequest true "Code of the new user type"
+// @success 204 "Changed"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 404 {object} errors.Error "User or user type not found"
+// @router /admin/users/{id}/role [put]
+func (r *Routes) changeUserType(c echo.Context) error {
+	r.Log.Infof("Changing role of user with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(ChangeUserTypeRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	admin := c.Get("user").(*models.User)
+	err = r.UserAdminService.ChangeUserType(ctx, admin, id, req.UserTypeCode)
+	if err != nil {
+		r.Log.Errorln("failed to change user type", err)
+
+		return notFoundAs(err, errors.NotFoundErr.WithMessage("User or user type not found"))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// bindAndValidate parses the request body and checks it by its validate tags
+func (r *Routes) bindAndValidate(c echo.Context, req any) error {
+	err := c.Bind(req)
+	if err != nil {
+		r.Log.Errorln("failed to parse request body", err)
+
+		return errors.BadRequestErr
+	}
+
+	err = validation.Struct(req)
+	if err != nil {
+		r.Log.Errorln("failed to validate request body", err)
+
+		return err
+	}
+
+	return nil
+}
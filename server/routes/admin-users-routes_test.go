@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/services"
+)
+
+// userAdminServiceStub counts the moderation actions, the other methods aren't used by the tests
+type userAdminServiceStub struct {
+	services.UserAdminServiceInterface
+	calls int
+}
+
+func (s *userAdminServiceStub) SuspendUser(ctx context.Context, admin *models.User, userId int, until time.Time,
+	reason string) error {
+	s.calls++
+
+	return nil
+}
+
+func (s *userAdminServiceStub) BanUser(ctx context.Context, admin *models.User, userId int, reason string) error {
+	s.calls++
+
+	return nil
+}
+
+func (s *userAdminServiceStub) ChangeUserType(ctx context.Context, admin *models.User, userId int,
+	typeCode string) error {
+	s.calls++
+
+	return nil
+}
+
+func TestModerationRejectsInvalidBody(t *testing.T) {
+	cfg, _ := config.NewConfig()
+	stub := new(userAdminServiceStub)
+	r := &Routes{Log: log.New(cfg), UserAdminService: stub}
+
+	tests := []struct {
+		name        string
+		handler     echo.HandlerFunc
+		body        string
+		expectedErr error
+	}{
+		{"Ban without reason", r.banUser, `{}`, apperrors.ValidationFailedErr},
+		{"Suspend without until", r.suspendUser, `{"reason": "spam"}`, apperrors.ValidationFailedErr},
+		{"Role change without type", r.changeUserType, `{"userTypeCode": ""}`, apperrors.ValidationFailedErr},
+		{"Ban with malformed body", r.banUser, `{"reason":`, apperrors.BadRequestErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("2")
+			c.Set("user", &models.User{Id: 1})
+
+			err := tt.handler(c)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.False(t, c.Response().Committed)
+			assert.Zero(t, stub.calls)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package routes
+
+import (
+	goerrors "errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"time"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/validation"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=255"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreateApiKeyResponse contains the key itself, it is not possible to get it again later
+type CreateApiKeyResponse struct {
+	*models.ApiKey
+	Key string `json:"key"`
+}
+
+func (r *Routes) RegisterApiKeysRoutes(g *echo.Group) {
+	g.GET("/", r.getApiKeysList)
+	g.POST("/", r.createApiKey)
+	g.DELETE("/:id", r.revokeApiKey)
+}
+
+// getApiKeysList retrieves the active API keys of the current user.
+// @summary Get API Keys List
+// @tags API Keys
+// @description Retrieves the API keys of the current user. Keys themselves are not returned, only their prefixes.
+// @produce json
+// @success 200 {array} models.ApiKey "List of API keys"
+// @failure 500 {object} errors.Error "Internal server error"
+// @router /users/api-keys/ [get]
+func (r *Routes) getApiKeysList(c echo.Context) error {
+	r.Log.Infof("Getting api keys list ...")
+	ctx := c.Request().Context()
+
+	user := c.Get("user").(*models.User)
+
+	apiKeys, err := r.ApiKeysService.GetApiKeysList(ctx, user.Id)
+	if err != nil {
+		r.Log.Errorln("failed to get api keys from db", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.JSON(http.StatusOK, &apiKeys)
+}
+
+// createApiKey creates an API key for machine clients of the current user.
+// @summary Create API Key
+// @tags API Keys
+// @description Creates an API key with the given scopes. Send it in the X-Api-Key header.
+// @description The key is returned only in this response.
+// @accept json
+// @produce json
+// @param request body CreateApiKeyRequest true "Name, scopes and optional expiry of the key"
+// @success 201 {object} CreateApiKeyResponse "Created API key"
+// @failure 400 {object} errors.Error "Bad Request"
+// @router /users/api-keys/ [post]
+func (r *Routes) createApiKey(c echo.Context) error {
+	r.Log.Infof("Creating api key ...")
+	ctx := c.Request().Context()
+
+	req := new(CreateApiKeyRequest)
+	err := c.Bind(req)
+	if err != nil {
+		r.Log.Errorln("failed to parse request body", err)
+
+		return errors.BadRequestErr
+	}
+
+	err = validation.Struct(req)
+	if err != nil {
+		r.Log.Errorln("failed to validate request body", err)
+
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+
+	apiKey, key, err := r.ApiKeysService.CreateApiKey(ctx, user, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		r.Log.Errorln("failed to create api key", err)
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, &CreateApiKeyResponse{ApiKey: apiKey, Key: key})
+}
+
+// revokeApiKey revokes one of the API keys of the current user.
+// @summary Revoke API Key
+// @tags API Keys
+// @description Revokes an API key by its ID. Requests with the key are rejected right away.
+// @produce json
+// @param id path int true "ID of the API key to revoke"
+// @success 204 "API key revoked"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "API key not found"
+// @router /users/api-keys/{id} [delete]
+func (r *Routes) revokeApiKey(c echo.Context) error {
+	r.Log.Infof("Revoke api key with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	user := c.Get("user").(*models.User)
+	err = r.ApiKeysService.RevokeApiKey(ctx, user.Id, id)
+	if err != nil {
+		if goerrors.Is(err, errors.NotFoundErr) {
+			r.Log.Errorln("api key not found", err)
+
+			return errors.ApiKeyNotFoundErr
+		}
+		r.Log.Errorln("failed to revoke api key", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -7,17 +7,27 @@ import (
 	"strconv"
 	"ypeskov/go_hillel_9/internal/errors"
 	"ypeskov/go_hillel_9/repository/models"
-	"ypeskov/go_hillel_9/services"
 )
 
-func (r *Routes) RegisterItemsRoutes(g *echo.Group) {
+func (r *Routes) RegisterItemsRoutes(g *echo.Group, idempotency echo.MiddlewareFunc) {
 	g.GET("/", r.getItemsList)
 	g.GET("/all", r.getAllItems)
-	g.POST("/comments", r.createItemComment)
-	g.POST("/", r.createItem)
+	r.registerItemsTransferRoutes(g)
+	g.POST("/comments", r.createItemComment, idempotency)
+	g.POST("/", r.createItem, idempotency)
 	g.GET("/:id", r.getItem)
 	g.PUT("/:id", r.updateItem)
 	g.DELETE("/:id", r.deleteItem)
+	g.POST("/:id/sale", r.markItemSold)
+	g.POST("/:id/sale/confirm", r.confirmItemSale)
+	g.POST("/:id/sale/decline", r.declineItemSale)
+
+	r.registerQuestionsRoutes(g)
+}
+
+type ItemSaleRequest struct {
+	BuyerId   int     `json:"buyerId" validate:"required"`
+	SoldPrice float64 `json:"soldPrice" validate:"min=0"`
 }
 
 // getItemsList retrieves a list of items.
@@ -32,19 +42,18 @@ func (r *Routes) RegisterItemsRoutes(g *echo.Group) {
 // @router /items/ [get]
 func (r *Routes) getItemsList(c echo.Context) error {
 	r.Log.Infof("Getting items list ...")
+	ctx := c.Request().Context()
 	user := c.Get("user").(*models.User)
 	if user == nil {
 		r.Log.Error("failed to get user from context")
 
-		return c.JSON(http.StatusInternalServerError,
-			errors.NewError("INTERNAL_SERVER_ERROR", "Failed to get user from context"))
+		return errors.InternalServerErr
 	}
-	items, err := r.ItemsService.GetItemsList(user.Id)
+	items, err := r.ItemsService.GetItemsList(ctx, user.Id)
 	if err != nil {
 		r.Log.Error("failed to get items from db", err)
 
-		return c.JSON(http.StatusInternalServerError,
-			errors.NewError("INTERNAL_SERVER_ERROR", "Failed to get items from db"))
+		return errors.InternalServerErr
 	}
 
 	return c.JSON(http.StatusOK, &items)
@@ -58,11 +67,16 @@ func (r *Routes) getItemsList(c echo.Context) error {
 // @accept json
 // @produce json
 // @param item body models.Item true "Item details"
+// @param Idempotency-Key header string false "Key that makes a retried request return the first response"
 // @success 201 {object} models.Item "Item created successfully"
 // @failure 400 {object} errors.Error "Bad Request: Failed to parse request body or validation failed"
+// @failure 403 {object} errors.Error "User is not a seller or email is not verified"
+// @failure 409 {object} errors.Error "A request with the same idempotency key is in progress"
+// @failure 422 {object} errors.Error "The idempotency key was used with a different request"
 // @router /items/ [post]
 func (r *Routes) createItem(c echo.Context) error {
 	r.Log.Infof("Creating item ...")
+	ctx := c.Request().Context()
 
 	req := new(models.Item)
 
@@ -70,32 +84,23 @@ func (r *Routes) createItem(c echo.Context) error {
 	if err != nil {
 		r.Log.Error("failed to parse request body", err)
 
-		return c.JSON(http.StatusBadRequest,
-			errors.NewError("INCORRECT_REQUEST_BODY", "Failed to parse request body"))
+		return errors.IncorrectReqBodyErr
 	}
 
 	err = req.Validate()
 	if err != nil {
 		r.Log.Error("validation failed: ", err)
 
-		return c.JSON(http.StatusBadRequest,
-			errors.NewError(errors.ValidationFailedErr.Code, err.Error()))
+		return err
 	}
 
 	user := c.Get("user").(*models.User)
 	req.UserId = user.Id
-	item, err := r.ItemsService.CreateItem(req, user)
+	item, err := r.ItemsService.CreateItem(ctx, req, user)
 	if err != nil {
 		r.Log.Errorln("failed to create item", err)
-		if goerrors.Is(err, services.IncorrectUserRoleErr) {
-
-			return c.JSON(http.StatusForbidden,
-				errors.NewError("INCORRECT_USER_ROLE", "User is not a seller"))
-		}
-
-		return c.JSON(http.StatusInternalServerError,
-			errors.NewError("INTERNAL_SERVER_ERROR", "Failed to create item"))
 
+		return err
 	}
 
 	r.Log.Infof("Inserted ID: %d", item.Id)
@@ -116,27 +121,27 @@ func (r *Routes) createItem(c echo.Context) error {
 // @router /items/{id} [get]
 func (r *Routes) getItem(c echo.Context) error {
 	r.Log.Infof("Get item with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		r.Log.Errorln("failed to convert id to int!!!", err)
 
-		return c.JSON(http.StatusBadRequest, errors.NewError("INVALID_ID", "Invalid ID"))
+		return errors.InvalidIdErr
 	}
 
 	user := c.Get("user").(*models.User)
 	if user == nil {
 		r.Log.Error("failed to get user from context")
 
-		return c.JSON(http.StatusInternalServerError,
-			errors.NewError("INTERNAL_SERVER_ERROR", "Failed to get user from context"))
+		return errors.InternalServerErr
 	}
 
-	item, err := r.ItemsService.GetItemById(id, user.Id)
+	item, err := r.ItemsService.GetItemById(ctx, id, user.Id)
 	if err != nil {
 		r.Log.Errorln("failed to get item by id", err)
 
-		return c.JSON(http.StatusNotFound, errors.NewError("ITEM_NOT_FOUND", "Item not found"))
+		return errors.ItemNotFoundErr
 	}
 
 	return c.JSON(http.StatusOK, &item)
@@ -156,6 +161,7 @@ func (r *Routes) getItem(c echo.Context) error {
 // @router /items/{id} [put]
 func (r *Routes) updateItem(c echo.Context) error {
 	r.Log.Infof("Update item with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
 
 	req := new(models.Item)
 
@@ -163,36 +169,35 @@ func (r *Routes) updateItem(c echo.Context) error {
 	if err != nil {
 		r.Log.Error("failed to parse request body", err)
 
-		return c.JSON(http.StatusBadRequest, errors.NewError("INCORRECT_REQUEST_BODY", "Failed to parse request body"))
+		return errors.IncorrectReqBodyErr
 	}
 
 	err = req.Validate()
 	if err != nil {
 		r.Log.Error("validation failed: ", err)
 
-		return c.JSON(http.StatusBadRequest, errors.NewError(errors.ValidationFailedErr.Code, err.Error()))
+		return err
 	}
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		r.Log.Error("failed to convert id to int!!!", err)
 
-		return c.JSON(http.StatusBadRequest, errors.NewError("INVALID_ID", "Invalid ID"))
+		return errors.InvalidIdErr
 	}
 
 	user := c.Get("user").(*models.User)
 	if user == nil {
 		r.Log.Error("failed to get user from context")
 
-		return c.JSON(http.StatusInternalServerError, errors.NewError("INTERNAL_SERVER_ERROR",
-			"Failed to get user from context"))
+		return errors.InternalServerErr
 	}
 
-	item, err := r.ItemsService.UpdateItem(id, req, user.Id)
+	item, err := r.ItemsService.UpdateItem(ctx, id, req, user.Id)
 	if err != nil {
 		r.Log.Error("failed to update item", err)
 
-		return c.JSON(http.StatusInternalServerError, errors.NewError("INTERNAL_SERVER_ERROR", "Failed to update item"))
+		return errors.InternalServerErr
 	}
 
 	return c.JSON(http.StatusOK, &item)
@@ -211,32 +216,31 @@ func (r *Routes) updateItem(c echo.Context) error {
 // @router /items/{id} [delete]
 func (r *Routes) deleteItem(c echo.Context) error {
 	r.Log.Infof("Delete item with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		r.Log.Error("failed to convert id to int!!!", err)
 
-		return c.JSON(http.StatusBadRequest, errors.NewError("INVALID_ID", "Invalid ID"))
+		return errors.InvalidIdErr
 	}
 
 	user := c.Get("user").(*models.User)
 	if user == nil {
 		r.Log.Error("failed to get user from context")
 
-		return c.JSON(http.StatusInternalServerError, errors.NewError("INTERNAL_SERVER_ERROR",
-			"Failed to get user from context"))
+		return errors.InternalServerErr
 	}
-	err = r.ItemsService.DeleteItem(id, user.Id)
+	err = r.ItemsService.DeleteItem(ctx, id, user.Id)
 	if err != nil {
 		if goerrors.Is(err, errors.NotFoundErr) {
 			r.Log.Error("item not found", err)
 
-			return c.JSON(http.StatusNotFound, errors.NewError("ITEM_NOT_FOUND", "Item not found"))
+			return errors.ItemNotFoundErr
 		}
 		r.Log.Errorln("failed to delete item", err)
 
-		return c.JSON(http.StatusInternalServerError,
-			errors.NewError("INTERNAL_SERVER_ERROR", "Failed to delete item"))
+		return errors.InternalServerErr
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -244,12 +248,12 @@ func (r *Routes) deleteItem(c echo.Context) error {
 
 func (r *Routes) getAllItems(c echo.Context) error {
 	r.Log.Infof("Getting all items ...")
-	items, err := r.ItemsService.GetAllItems()
+	ctx := c.Request().Context()
+	items, err := r.ItemsService.GetAllItems(ctx)
 	if err != nil {
 		r.Log.Error("failed to get items from db", err)
 
-		return c.JSON(http.StatusInternalServerError,
-			errors.NewError("INTERNAL_SERVER_ERROR", "Failed to get items from db"))
+		return errors.InternalServerErr
 	}
 
 	return c.JSON(http.StatusOK, &items)
@@ -257,6 +261,7 @@ func (r *Routes) getAllItems(c echo.Context) error {
 
 func (r *Routes) createItemComment(c echo.Context) error {
 	r.Log.Infof("Creating item comment ...")
+	ctx := c.Request().Context()
 
 	itemComment := new(models.ItemComment)
 
@@ -264,29 +269,128 @@ func (r *Routes) createItemComment(c echo.Context) error {
 	if err != nil {
 		r.Log.Error("failed to parse request body", err)
 
-		return c.JSON(http.StatusBadRequest,
-			errors.NewError("INCORRECT_REQUEST_BODY", "Failed to parse request body"))
+		return errors.IncorrectReqBodyErr
 	}
 
 	err = itemComment.Validate()
 	if err != nil {
 		r.Log.Error("validation failed: ", err)
 
-		return c.JSON(http.StatusBadRequest,
-			errors.NewError(errors.ValidationFailedErr.Code, err.Error()))
+		return err
 	}
 
 	user := c.Get("user").(*models.User)
 	itemComment.UserId = user.Id
-	comment, err := r.ItemsService.CreateItemComment(itemComment)
+	comment, err := r.ItemsService.CreateItemComment(ctx, itemComment)
 	if err != nil {
 		r.Log.Errorln("failed to create item comment", err)
 
-		return c.JSON(http.StatusInternalServerError,
-			errors.NewError("INTERNAL_SERVER_ERROR", "Failed to create item comment"))
+		return errors.InternalServerErr
 	}
 
 	r.Log.Infof("Inserted ID: %d", comment.Id)
 
 	return c.JSON(http.StatusCreated, &comment)
 }
+
+// markItemSold records the sale of an item.
+// @summary Mark Item Sold
+// @tags Items
+// @description Records who bought the item and for how much. Only the seller can do it, once.
+// @description The sale is pending until the buyer confirms it, after that they can review each other.
+// @accept json
+// @produce json
+// @param id path int true "ID of the sold item"
+// @param request body ItemSaleRequest true "Buyer and price"
+// @success 200 {object} models.Item "Sold item"
+// @failure 400 {object} errors.Error "Bad Request: validation failed or invalid buyer"
+// @failure 404 {object} errors.Error "Item not found"
+// @failure 409 {object} errors.Error "Item is already sold"
+// @router /items/{id}/sale [post]
+func (r *Routes) markItemSold(c echo.Context) error {
+	r.Log.Infof("Marking item %s as sold ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(ItemSaleRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+	item, err := r.ItemsService.MarkItemSold(ctx, id, user.Id, req.BuyerId, req.SoldPrice)
+	if err != nil {
+		r.Log.Errorln("failed to mark item as sold", err)
+
+		return notFoundAs(err, errors.ItemNotFoundErr)
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// confirmItemSale lets the buyer confirm a pending sale.
+// @summary Confirm Item Sale
+// @tags Items
+// @description Confirms the sale the seller recorded. After that the buyer and the seller can review each other.
+// @produce json
+// @param id path int true "ID of the bought item"
+// @success 200 {object} models.Item "Sold item"
+// @failure 404 {object} errors.Error "No pending sale of the item to the user"
+// @router /items/{id}/sale/confirm [post]
+func (r *Routes) confirmItemSale(c echo.Context) error {
+	r.Log.Infof("Confirming sale of item %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return c.JSON(http.StatusBadRequest, errors.NewError("INVALID_ID", "Invalid ID"))
+	}
+
+	user := c.Get("user").(*models.User)
+	item, err := r.ItemsService.ConfirmItemSale(ctx, id, user.Id)
+	if err != nil {
+		r.Log.Errorln("failed to confirm item sale", err)
+
+		return notFoundAs(err, errors.NoPendingSaleErr)
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// declineItemSale lets the buyer reject a pending sale.
+// @summary Decline Item Sale
+// @tags Items
+// @description Rejects the sale the seller recorded, the item is for sale again.
+// @param id path int true "ID of the item"
+// @success 204 "Declined"
+// @failure 404 {object} errors.Error "No pending sale of the item to the user"
+// @router /items/{id}/sale/decline [post]
+func (r *Routes) declineItemSale(c echo.Context) error {
+	r.Log.Infof("Declining sale of item %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return c.JSON(http.StatusBadRequest, errors.NewError("INVALID_ID", "Invalid ID"))
+	}
+
+	user := c.Get("user").(*models.User)
+	err = r.ItemsService.DeclineItemSale(ctx, id, user.Id)
+	if err != nil {
+		r.Log.Errorln("failed to decline item sale", err)
+
+		return notFoundAs(err, errors.NoPendingSaleErr)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
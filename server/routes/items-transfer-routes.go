@@ -0,0 +1,202 @@
+package routes
+
+import (
+	goerrors "errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/services"
+)
+
+// contentTypes of the export formats
+var contentTypes = map[string]string{
+	services.FormatCsv:    "text/csv; charset=utf-8",
+	services.FormatNdjson: "application/x-ndjson",
+}
+
+func (r *Routes) registerItemsTransferRoutes(g *echo.Group) {
+	g.POST("/import", r.importItems)
+	g.GET("/export", r.exportItems)
+	g.GET("/sales/export", r.exportSales)
+}
+
+// importItems creates items of the seller from a CSV or NDJSON file.
+// @summary Import Items
+// @tags Items
+// @description Creates the items of a CSV file with a header row (title, initial_price, description) or of an
+// @description NDJSON file with one item object per line. The file is the request body, its Content-Type is
+// @description text/csv or application/x-ndjson, or the file field of a multipart form. The format query
+// @description parameter overrides the detected format. Invalid rows are skipped and listed in the report,
+// @description with dryRun=true nothing is created.
+// @accept text/csv,application/x-ndjson,multipart/form-data
+// @produce json
+// @param format query string false "csv or ndjson"
+// @param dryRun query bool false "only validate the file"
+// @success 200 {object} services.ItemsImportReport "Dry run report"
+// @success 201 {object} services.ItemsImportReport "Import report"
+// @failure 400 {object} errors.Error "The file can't be read"
+// @failure 403 {object} errors.Error "User is not a seller or email is not verified"
+// @failure 413 {object} errors.Error "The file has too many rows or is too large"
+// @failure 415 {object} errors.Error "Unsupported format"
+// @router /items/import [post]
+func (r *Routes) importItems(c echo.Context) error {
+	r.Log.Infof("Importing items ...")
+	ctx := c.Request().Context()
+
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dryRun"))
+
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, r.cfg.ItemsImportMaxBytes)
+	format, file, err := importFile(c)
+	if err != nil {
+		r.Log.Errorln("failed to read the import file", err)
+
+		return importTooLarge(err)
+	}
+	defer file.Close()
+
+	user := c.Get("user").(*models.User)
+	report, err := r.ItemsTransferService.ImportItems(ctx, user, format, file, dryRun)
+	if err != nil {
+		r.Log.Errorln("failed to import items", err)
+
+		return importTooLarge(err)
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+
+	return c.JSON(status, report)
+}
+
+// importTooLarge replaces the error of reading past ItemsImportMaxBytes with ImportTooLargeErr
+func importTooLarge(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if goerrors.As(err, &maxBytesErr) {
+		return errors.ImportTooLargeErr.WithMessage(fmt.Sprintf("The file is larger than %d bytes", maxBytesErr.Limit))
+	}
+
+	return err
+}
+
+// importFile returns the uploaded file and its format
+func importFile(c echo.Context) (string, io.ReadCloser, error) {
+	format := strings.ToLower(c.QueryParam("format"))
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != echo.MIMEMultipartForm {
+		if format == "" {
+			format = formatOf(mediaType, "")
+		}
+
+		return format, c.Request().Body, nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if goerrors.As(err, &maxBytesErr) {
+			return "", nil, err
+		}
+
+		return "", nil, errors.IncorrectReqBodyErr.WithMessage("The form has no file field")
+	}
+	if format == "" {
+		format = formatOf(header.Header.Get(echo.HeaderContentType), header.Filename)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return format, file, nil
+}
+
+// formatOf detects the format by the media type or the extension of the file name
+func formatOf(mediaType string, fileName string) string {
+	switch mediaType {
+	case "text/csv":
+		return services.FormatCsv
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return services.FormatNdjson
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return services.FormatCsv
+	case ".ndjson", ".jsonl":
+		return services.FormatNdjson
+	}
+
+	return ""
+}
+
+// exportItems streams the items of the user.
+// @summary Export Items
+// @tags Items
+// @description Streams all items of the user as CSV or NDJSON, the CSV columns are the ones of the import.
+// @produce text/csv,application/x-ndjson
+// @param format query string false "csv (default) or ndjson"
+// @success 200 {string} string "Items"
+// @failure 415 {object} errors.Error "Unsupported format"
+// @router /items/export [get]
+func (r *Routes) exportItems(c echo.Context) error {
+	r.Log.Infof("Exporting items ...")
+	user := c.Get("user").(*models.User)
+
+	return r.export(c, "items", func(format string, w io.Writer) error {
+		return r.ItemsTransferService.ExportItems(c.Request().Context(), user.Id, format, w)
+	})
+}
+
+// exportSales streams the sold items of the seller.
+// @summary Export Sales
+// @tags Items
+// @description Streams the sold items of the seller with buyers, sold prices and dates as CSV or NDJSON.
+// @produce text/csv,application/x-ndjson
+// @param format query string false "csv (default) or ndjson"
+// @success 200 {string} string "Sales"
+// @failure 415 {object} errors.Error "Unsupported format"
+// @router /items/sales/export [get]
+func (r *Routes) exportSales(c echo.Context) error {
+	r.Log.Infof("Exporting sales ...")
+	user := c.Get("user").(*models.User)
+
+	return r.export(c, "sales", func(format string, w io.Writer) error {
+		return r.ItemsTransferService.ExportSales(c.Request().Context(), user.Id, format, w)
+	})
+}
+
+// export writes the headers of the download and streams the rows written by write into the response
+func (r *Routes) export(c echo.Context, name string, write func(format string, w io.Writer) error) error {
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" {
+		format = services.FormatCsv
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		return errors.UnsupportedFormatErr
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, contentType)
+	header.Set(echo.HeaderContentDisposition, "attachment; filename=\""+name+"."+format+"\"")
+	c.Response().WriteHeader(http.StatusOK)
+
+	err := write(format, c.Response())
+	if err != nil {
+		// the status is sent already, the client gets a cut short file
+		r.Log.Errorf("failed to export %s: %v", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type StartConversationRequest struct {
+	RecipientId int    `json:"recipientId" validate:"required"`
+	ItemId      *int   `json:"itemId"`
+	Body        string `json:"body" validate:"required,max=5000"`
+}
+
+type StartConversationResponse struct {
+	Conversation *models.Conversation `json:"conversation"`
+	Message      *models.Message      `json:"message"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
+
+type UnreadCountResponse struct {
+	Unread int `json:"unread"`
+}
+
+type MarkReadResponse struct {
+	Marked int `json:"marked"`
+}
+
+type BlockUserRequest struct {
+	UserId int `json:"userId" validate:"required"`
+}
+
+func (r *Routes) RegisterConversationsRoutes(g *echo.Group) {
+	g.GET("/", r.getConversations)
+	g.POST("/", r.startConversation)
+	g.GET("/unread", r.getUnreadCount)
+	g.GET("/:id/messages", r.getMessages)
+	g.POST("/:id/messages", r.sendMessage)
+	g.POST("/:id/read", r.markConversationRead)
+}
+
+func (r *Routes) RegisterBlocksRoutes(g *echo.Group) {
+	g.GET("/", r.getBlockedUsers)
+	g.POST("/", r.blockUser)
+	g.DELETE("/:id", r.unblockUser)
+}
+
+// getConversations retrieves the conversations of the current user.
+// @summary List Conversations
+// @tags Messages
+// @description Retrieves the conversations of the current user with the latest activity first and their unread counts.
+// @produce json
+// @success 200 {array} models.ConversationSummary "Conversations"
+// @failure 401 {object} errors.Error "Unauthorized"
+// @router /conversations/ [get]
+func (r *Routes) getConversations(c echo.Context) error {
+	r.Log.Infof("Getting conversations ...")
+	ctx := c.Request().Context()
+
+	user := c.Get("user").(*models.User)
+	conversations, err := r.MessagesService.GetConversations(ctx, user)
+	if err != nil {
+		r.Log.Errorln("failed to get conversations", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.JSON(http.StatusOK, conversations)
+}
+
+// startConversation sends a message to another user.
+// @summary Start Conversation
+// @tags Messages
+// @description Sends a message to another user, optionally about an item of one of you.
+// @description Messages about the same item, or without an item, go to the same conversation.
+// @accept json
+// @produce json
+// @param request body StartConversationRequest true "Recipient, item and message"
+// @success 201 {object} StartConversationResponse "Conversation and the sent message"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 403 {object} errors.Error "The recipient has blocked you"
+// @router /conversations/ [post]
+func (r *Routes) startConversation(c echo.Context) error {
+	r.Log.Infof("Starting conversation ...")
+	ctx := c.Request().Context()
+
+	req := new(StartConversationRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+	conversation, message, err := r.MessagesService.StartConversation(ctx, user, req.RecipientId, req.ItemId, req.Body)
+	if err != nil {
+		r.Log.Errorln("failed to start conversation", err)
+
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, StartConversationResponse{Conversation: conversation, Message: message})
+}
+
+// getUnreadCount retrieves the number of unread messages.
+// @summary Get Unread Count
+// @tags Messages
+// @description Retrieves the number of unread messages in all conversations of the current user.
+// @produce json
+// @success 200 {object} UnreadCountResponse "Unread messages"
+// @failure 401 {object} errors.Error "Unauthorized"
+// @router /conversations/unread [get]
+func (r *Routes) getUnreadCount(c echo.Context) error {
+	ctx := c.Request().Context()
+	user := c.Get("user").(*models.User)
+	unread, err := r.MessagesService.GetUnreadCount(ctx, user)
+	if err != nil {
+		r.Log.Errorln("failed to count unread messages", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.JSON(http.StatusOK, UnreadCountResponse{Unread: unread})
+}
+
+// getMessages retrieves the messages of a conversation.
+// @summary List Messages
+// @tags Messages
+// @description Retrieves messages of a conversation the newest first. Pass the id of the oldest received message
+// @description as beforeId to get the previous page.
+// @produce json
+// @param id path int true "ID of the conversation"
+// @param beforeId query int false "Only messages older than this one"
+// @param limit query int false "Number of messages, at most 100"
+// @success 200 {array} models.Message "Messages"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "Conversation not found"
+// @router /conversations/{id}/messages [get]
+func (r *Routes) getMessages(c echo.Context) error {
+	r.Log.Infof("Getting messages of conversation %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	// invalid numbers fall back to the defaults
+	beforeId, _ := strconv.Atoi(c.QueryParam("beforeId"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	user := c.Get("user").(*models.User)
+	messages, err := r.MessagesService.GetMessages(ctx, user, id, beforeId, limit)
+	if err != nil {
+		r.Log.Errorln("failed to get messages", err)
+
+		return err
+	}
+
+	return c.JSON(http.StatusOK, messages)
+}
+
+// sendMessage sends a message to a conversation.
+// @summary Send Message
+// @tags Messages
+// @description Sends a message to a conversation of the current user.
+// @accept json
+// @produce json
+// @param id path int true "ID of the conversation"
+// @param request body SendMessageRequest true "Message"
+// @success 201 {object} models.Message "Sent message"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 403 {object} errors.Error "The other participant has blocked you"
+// @failure 404 {object} errors.Error "Conversation not found"
+// @router /conversations/{id}/messages [post]
+func (r *Routes) sendMessage(c echo.Context) error {
+	r.Log.Infof("Sending message to conversation %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(SendMessageRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+	message, err := r.MessagesService.SendMessage(ctx, user, id, req.Body)
+	if err != nil {
+		r.Log.Errorln("failed to send message", err)
+
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, message)
+}
+
+// markConversationRead marks the received messages of a conversation as read.
+// @summary Mark Conversation Read
+// @tags Messages
+// @description Marks all messages the current user has received in the conversation as read.
+// @produce json
+// @param id path int true "ID of the conversation"
+// @success 200 {object} MarkReadResponse "Number of messages marked as read"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "Conversation not found"
+// @router /conversations/{id}/read [post]
+func (r *Routes) markConversationRead(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	user := c.Get("user").(*models.User)
+	marked, err := r.MessagesService.MarkRead(ctx, user, id)
+	if err != nil {
+		r.Log.Errorln("failed to mark conversation as read", err)
+
+		return err
+	}
+
+	return c.JSON(http.StatusOK, MarkReadResponse{Marked: marked})
+}
+
+// getBlockedUsers retrieves the users blocked by the current user.
+// @summary List Blocked Users
+// @tags Messages
+// @description Retrieves the users who can't send messages to the current user.
+// @produce json
+// @success 200 {array} models.UserBlock "Blocked users"
+// @failure 401 {object} errors.Error "Unauthorized"
+// @router /users/blocks/ [get]
+func (r *Routes) getBlockedUsers(c echo.Context) error {
+	ctx := c.Request().Context()
+	user := c.Get("user").(*models.User)
+	blocks, err := r.MessagesService.GetBlockedUsers(ctx, user)
+	if err != nil {
+		r.Log.Errorln("failed to get blocked users", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.JSON(http.StatusOK, blocks)
+}
+
+// blockUser stops a user from sending messages to the current user.
+// @summary Block User
+// @tags Messages
+// @description Stops the user from sending messages to the current user. Existing conversations stay readable.
+// @accept json
+// @produce json
+// @param request body BlockUserRequest true "User to block"
+// @success 204 "Blocked"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 404 {object} errors.Error "User not found"
+// @router /users/blocks/ [post]
+func (r *Routes) blockUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	req := new(BlockUserRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+	err := r.MessagesService.BlockUser(ctx, user, req.UserId)
+	if err != nil {
+		r.Log.Errorln("failed to block user", err)
+
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// unblockUser lets a blocked user send messages again.
+// @summary Unblock User
+// @tags Messages
+// @description Lets the user send messages to the current user again.
+// @param id path int true "ID of the blocked user"
+// @success 204 "Unblocked"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "User is not blocked"
+// @router /users/blocks/{id} [delete]
+func (r *Routes) unblockUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	user := c.Get("user").(*models.User)
+	err = r.MessagesService.UnblockUser(ctx, user, id)
+	if err != nil {
+		r.Log.Errorln("failed to unblock user", err)
+
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
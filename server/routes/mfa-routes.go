@@ -0,0 +1,141 @@
+package routes
+
+import (
+	goerrors "errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/validation"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type MfaCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+func (r *Routes) RegisterMfaRoutes(g *echo.Group) {
+	g.POST("/enroll/", r.enrollMfa)
+	g.POST("/confirm/", r.confirmMfa)
+	g.POST("/disable/", r.disableMfa)
+}
+
+// enrollMfa creates a new secret for the authenticator app of the current user.
+// @summary Enroll Two-Factor Authentication
+// @tags MFA
+// @description Creates a TOTP secret and an otpauth URI to show as a QR code.
+// @description Two-factor authentication is enabled only after the first code is confirmed.
+// @produce json
+// @success 200 {object} services.MfaEnrollment "Secret and otpauth URI"
+// @failure 409 {object} errors.Error "Two-factor authentication is already enabled"
+// @router /users/mfa/enroll/ [post]
+func (r *Routes) enrollMfa(c echo.Context) error {
+	r.Log.Infof("Enrolling two-factor authentication ...")
+	ctx := c.Request().Context()
+
+	user := c.Get("user").(*models.User)
+
+	enrollment, err := r.MfaService.Enroll(ctx, user)
+	if err != nil {
+		r.Log.Errorln("failed to enroll two-factor authentication", err)
+
+		return mfaError(err)
+	}
+
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+// confirmMfa enables two-factor authentication with the first code from the authenticator app.
+// @summary Confirm Two-Factor Authentication
+// @tags MFA
+// @description Enables two-factor authentication and returns recovery codes. They are shown only once.
+// @accept json
+// @produce json
+// @param request body MfaCodeRequest true "Code from the authenticator app"
+// @success 200 {object} RecoveryCodesResponse "Recovery codes"
+// @failure 400 {object} errors.Error "Invalid code or no pending enrollment"
+// @failure 409 {object} errors.Error "Two-factor authentication is already enabled"
+// @router /users/mfa/confirm/ [post]
+func (r *Routes) confirmMfa(c echo.Context) error {
+	r.Log.Infof("Confirming two-factor authentication ...")
+	ctx := c.Request().Context()
+
+	req, err := r.bindMfaCode(c)
+	if err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+
+	codes, err := r.MfaService.Confirm(ctx, user, req.Code)
+	if err != nil {
+		r.Log.Errorln("failed to confirm two-factor authentication", err)
+
+		return mfaError(err)
+	}
+
+	return c.JSON(http.StatusOK, &RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// disableMfa turns two-factor authentication off.
+// @summary Disable Two-Factor Authentication
+// @tags MFA
+// @description Disables two-factor authentication. Requires a code from the authenticator app or a recovery code.
+// @accept json
+// @produce json
+// @param request body MfaCodeRequest true "One-time code"
+// @success 204 "Disabled"
+// @failure 400 {object} errors.Error "Invalid code or two-factor authentication is not enabled"
+// @router /users/mfa/disable/ [post]
+func (r *Routes) disableMfa(c echo.Context) error {
+	r.Log.Infof("Disabling two-factor authentication ...")
+	ctx := c.Request().Context()
+
+	req, err := r.bindMfaCode(c)
+	if err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+
+	err = r.MfaService.Disable(ctx, user, req.Code)
+	if err != nil {
+		r.Log.Errorln("failed to disable two-factor authentication", err)
+
+		return mfaError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// bindMfaCode parses and validates the one-time code of the request
+func (r *Routes) bindMfaCode(c echo.Context) (*MfaCodeRequest, error) {
+	req := new(MfaCodeRequest)
+	err := c.Bind(req)
+	if err != nil {
+		r.Log.Errorln("failed to parse request body", err)
+
+		return nil, errors.BadRequestErr
+	}
+
+	err = validation.Struct(req)
+	if err != nil {
+		r.Log.Errorln("failed to validate request body", err)
+
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// mfaError responds to an invalid code with 400, 401 is left for failed logins
+func mfaError(err error) error {
+	if goerrors.Is(err, errors.InvalidMfaCodeErr) {
+		return errors.InvalidMfaCodeErr.WithStatus(http.StatusBadRequest)
+	}
+
+	return err
+}
@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"ypeskov/go_hillel_9/internal/errors"
+)
+
+func (r *Routes) RegisterOidcRoutes(g *echo.Group) {
+	g.GET("/login/", r.startOidcLogin)
+	g.GET("/callback/", r.finishOidcLogin)
+}
+
+// startOidcLogin redirects the user to the identity provider.
+// @summary Single Sign-On Login
+// @tags Users
+// @description Starts the OpenID Connect authorization code flow with PKCE by redirecting to the identity provider.
+// @param device query string false "Name of the device, shown in the list of sessions"
+// @success 302 "Redirect to the identity provider"
+// @failure 404 {object} errors.Error "Single sign-on is not configured"
+// @router /users/oidc/login/ [get]
+func (r *Routes) startOidcLogin(c echo.Context) error {
+	r.Log.Infof("Starting single sign-on login ...")
+	ctx := c.Request().Context()
+
+	authUrl, err := r.OidcService.StartLogin(ctx, c.QueryParam("device"))
+	if err != nil {
+		r.Log.Errorln("failed to start single sign-on login", err)
+
+		return err
+	}
+
+	return c.Redirect(http.StatusFound, authUrl)
+}
+
+// finishOidcLogin handles the redirect back from the identity provider.
+// @summary Single Sign-On Callback
+// @tags Users
+// @description Exchanges the authorization code for an ID token, links or creates the local user
+// @description and returns tokens, or an MFA challenge token if the user enabled two-factor authentication.
+// @produce json
+// @param code query string true "Authorization code"
+// @param state query string true "State of the login"
+// @success 200 {object} LoginResponse "Access and refresh tokens or an MFA challenge token"
+// @failure 400 {object} errors.Error "Unknown or expired login"
+// @failure 401 {object} errors.Error "Identity provider didn't confirm the login"
+// @failure 409 {object} errors.Error "Account with an unverified email exists"
+// @router /users/oidc/callback/ [get]
+func (r *Routes) finishOidcLogin(c echo.Context) error {
+	r.Log.Infof("Finishing single sign-on login ...")
+	ctx := c.Request().Context()
+
+	if providerErr := c.QueryParam("error"); providerErr != "" {
+		r.Log.Errorln("identity provider returned an error", providerErr, c.QueryParam("error_description"))
+
+		return errors.OidcLoginFailedErr
+	}
+
+	result, err := r.OidcService.FinishLogin(ctx, c.QueryParam("state"), c.QueryParam("code"), sessionClient(c, ""))
+	if err != nil {
+		r.Log.Errorln("failed to finish single sign-on login", err)
+
+		return err
+	}
+
+	if result.MfaToken != "" {
+		return c.JSON(http.StatusOK, &LoginResponse{
+			MfaRequired: true,
+			MfaToken:    result.MfaToken,
+		})
+	}
+
+	return c.JSON(http.StatusOK, &LoginResponse{
+		AccessToken:           result.Tokens.AccessToken,
+		RefreshToken:          result.Tokens.RefreshToken,
+		MfaEnrollmentRequired: result.Tokens.MfaEnrollmentRequired,
+	})
+}
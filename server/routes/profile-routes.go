@@ -0,0 +1,127 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"ypeskov/go_hillel_9/internal/errors"
+)
+
+func (r *Routes) RegisterProfileRoutes(g *echo.Group) {
+	g.GET("/:id/profile", r.getUserProfile)
+	g.GET("/:id/items", r.getStorefront)
+	g.GET("/:id/reviews", r.getUserReviews)
+}
+
+// getUserProfile retrieves the public profile of a user.
+// @summary Get User Profile
+// @tags Profiles
+// @description Retrieves the public profile of a user: display name, member since, sales and rating.
+// @description Contact details are never shown. Banned users have no profile.
+// @produce json
+// @param id path int true "ID of the user"
+// @success 200 {object} models.UserProfile "Public profile"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "User not found"
+// @router /users/{id}/profile [get]
+func (r *Routes) getUserProfile(c echo.Context) error {
+	r.Log.Infof("Getting profile of user %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	profile, err := r.ProfileService.GetUserProfile(ctx, id)
+	if err != nil {
+		r.Log.Errorln("failed to get user profile", err)
+
+		return notFoundAs(err, errors.UserNotFoundErr)
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// getStorefront retrieves the unsold items of a seller.
+// @summary Get Storefront
+// @tags Profiles
+// @description Retrieves the public profile of a seller and one page of their unsold items.
+// @produce json
+// @param id path int true "ID of the seller"
+// @param page query int false "Page number, starting from 1"
+// @param perPage query int false "Items per page, at most 100"
+// @success 200 {object} services.Storefront "Seller and their items"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "User not found"
+// @router /users/{id}/items [get]
+func (r *Routes) getStorefront(c echo.Context) error {
+	r.Log.Infof("Getting storefront of user %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	// invalid numbers fall back to the defaults
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	perPage, _ := strconv.Atoi(c.QueryParam("perPage"))
+
+	storefront, err := r.ProfileService.GetStorefront(ctx, id, page, perPage)
+	if err != nil {
+		r.Log.Errorln("failed to get storefront", err)
+
+		return notFoundAs(err, errors.UserNotFoundErr)
+	}
+
+	return c.JSON(http.StatusOK, storefront)
+}
+
+// getUserReviews retrieves the reviews a user has received.
+// @summary Get User Reviews
+// @tags Profiles
+// @description Retrieves the rating summary and one page of reviews of a user, the newest first.
+// @produce json
+// @param id path int true "ID of the user"
+// @param page query int false "Page number, starting from 1"
+// @param perPage query int false "Reviews per page, at most 100"
+// @success 200 {object} services.ReviewsPage "Rating summary and reviews"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "User not found"
+// @router /users/{id}/reviews [get]
+func (r *Routes) getUserReviews(c echo.Context) error {
+	r.Log.Infof("Getting reviews of user %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	// reviews of banned users are hidden together with their profiles
+	_, err = r.ProfileService.GetUserProfile(ctx, id)
+	if err != nil {
+		r.Log.Errorln("failed to get user profile", err)
+
+		return notFoundAs(err, errors.UserNotFoundErr)
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	perPage, _ := strconv.Atoi(c.QueryParam("perPage"))
+
+	reviews, err := r.ReviewService.GetUserReviews(ctx, id, page, perPage)
+	if err != nil {
+		r.Log.Errorln("failed to get user reviews", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.JSON(http.StatusOK, reviews)
+}
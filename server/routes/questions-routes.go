@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type AskQuestionRequest struct {
+	Question string `json:"question" validate:"required,max=2000"`
+}
+
+type AnswerQuestionRequest struct {
+	Answer string `json:"answer" validate:"required,max=5000"`
+}
+
+func (r *Routes) registerQuestionsRoutes(g *echo.Group) {
+	g.GET("/:id/questions", r.getItemQuestions)
+	g.POST("/:id/questions", r.askQuestion)
+	g.POST("/:id/questions/:questionId/answer", r.answerQuestion)
+}
+
+// getItemQuestions retrieves the questions about an item.
+// @summary List Item Questions
+// @tags Items
+// @description Retrieves answered questions about the item. The asker also sees their unanswered questions,
+// @description the seller sees all of them.
+// @produce json
+// @param id path int true "ID of the item"
+// @success 200 {array} models.ItemQuestion "Questions"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "Item not found"
+// @router /items/{id}/questions [get]
+func (r *Routes) getItemQuestions(c echo.Context) error {
+	r.Log.Infof("Getting questions of item %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	user := c.Get("user").(*models.User)
+	questions, err := r.QuestionsService.GetQuestions(ctx, user, id)
+	if err != nil {
+		r.Log.Errorln("failed to get questions", err)
+
+		return err
+	}
+
+	return c.JSON(http.StatusOK, questions)
+}
+
+// askQuestion asks the seller a question about an item.
+// @summary Ask Question
+// @tags Items
+// @description Asks the seller a question about the item. It is public once the seller answers it.
+// @accept json
+// @produce json
+// @param id path int true "ID of the item"
+// @param request body AskQuestionRequest true "Question"
+// @success 201 {object} models.ItemQuestion "Created question"
+// @failure 400 {object} errors.Error "Bad Request or own item"
+// @failure 404 {object} errors.Error "Item not found"
+// @router /items/{id}/questions [post]
+func (r *Routes) askQuestion(c echo.Context) error {
+	r.Log.Infof("Asking question about item %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(AskQuestionRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+	question, err := r.QuestionsService.AskQuestion(ctx, user, id, req.Question)
+	if err != nil {
+		r.Log.Errorln("failed to ask question", err)
+
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, question)
+}
+
+// answerQuestion answers a question about an item.
+// @summary Answer Question
+// @tags Items
+// @description The seller answers a question about their item, once. The answered question becomes public.
+// @accept json
+// @produce json
+// @param id path int true "ID of the item"
+// @param questionId path int true "ID of the question"
+// @param request body AnswerQuestionRequest true "Answer"
+// @success 200 {object} models.ItemQuestion "Answered question"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 403 {object} errors.Error "Not the seller of the item"
+// @failure 404 {object} errors.Error "Item or question not found"
+// @failure 409 {object} errors.Error "The question is already answered"
+// @router /items/{id}/questions/{questionId}/answer [post]
+func (r *Routes) answerQuestion(c echo.Context) error {
+	r.Log.Infof("Answering question %s about item %s ...", c.Param("questionId"), c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+	questionId, err := strconv.Atoi(c.Param("questionId"))
+	if err != nil {
+		r.Log.Errorln("failed to convert question id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(AnswerQuestionRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+	question, err := r.QuestionsService.AnswerQuestion(ctx, user, id, questionId, req.Answer)
+	if err != nil {
+		r.Log.Errorln("failed to answer question", err)
+
+		return err
+	}
+
+	return c.JSON(http.StatusOK, question)
+}
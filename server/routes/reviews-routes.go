@@ -0,0 +1,215 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+type CreateReviewRequest struct {
+	ItemId int    `json:"itemId" validate:"required"`
+	Rating int    `json:"rating" validate:"min=1,max=5"`
+	Review string `json:"review" validate:"max=5000"`
+}
+
+type ReviewResponseRequest struct {
+	Response string `json:"response" validate:"required,max=5000"`
+}
+
+type DisputeReviewRequest struct {
+	Reason string `json:"reason" validate:"required,max=5000"`
+}
+
+type ResolveDisputeRequest struct {
+	Remove bool `json:"remove"`
+}
+
+func (r *Routes) RegisterReviewsRoutes(g *echo.Group) {
+	g.POST("/", r.createReview)
+	g.POST("/:id/response", r.respondToReview)
+	g.POST("/:id/dispute", r.disputeReview)
+}
+
+func (r *Routes) registerAdminReviewsRoutes(g *echo.Group) {
+	g.GET("/reviews/disputed", r.getDisputedReviews)
+	g.POST("/reviews/:id/resolve", r.resolveReviewDispute)
+}
+
+// createReview rates the other party of a sale.
+// @summary Create Review
+// @tags Reviews
+// @description The buyer and the seller of a sold item can each rate the other once, within the review window.
+// @accept json
+// @produce json
+// @param request body CreateReviewRequest true "Item, rating from 1 to 5 and review text"
+// @success 201 {object} models.Review "Created review"
+// @failure 400 {object} errors.Error "Bad Request or the review window is over"
+// @failure 403 {object} errors.Error "Not a party of the sale"
+// @failure 404 {object} errors.Error "Sold item not found"
+// @failure 409 {object} errors.Error "The sale is already reviewed"
+// @router /reviews/ [post]
+func (r *Routes) createReview(c echo.Context) error {
+	r.Log.Infof("Creating review ...")
+	ctx := c.Request().Context()
+
+	req := new(CreateReviewRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+	review, err := r.ReviewService.CreateReview(ctx, user, req.ItemId, req.Rating, req.Review)
+	if err != nil {
+		r.Log.Errorln("failed to create review", err)
+
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, review)
+}
+
+// respondToReview adds the answer of the reviewed user.
+// @summary Respond To Review
+// @tags Reviews
+// @description The reviewed user can publish one response to the review.
+// @accept json
+// @produce json
+// @param id path int true "ID of the review"
+// @param request body ReviewResponseRequest true "Response text"
+// @success 204 "Responded"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 403 {object} errors.Error "Not the reviewed user"
+// @failure 404 {object} errors.Error "Review not found"
+// @failure 409 {object} errors.Error "The review already has a response"
+// @router /reviews/{id}/response [post]
+func (r *Routes) respondToReview(c echo.Context) error {
+	r.Log.Infof("Responding to review %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(ReviewResponseRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+	err = r.ReviewService.RespondToReview(ctx, user, id, req.Response)
+	if err != nil {
+		r.Log.Errorln("failed to respond to review", err)
+
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// disputeReview asks administrators to check a review.
+// @summary Dispute Review
+// @tags Reviews
+// @description The reviewed user can dispute the review once. It stays visible until an administrator decides.
+// @accept json
+// @produce json
+// @param id path int true "ID of the review"
+// @param request body DisputeReviewRequest true "Why the review is unfair"
+// @success 204 "Disputed"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 403 {object} errors.Error "Not the reviewed user"
+// @failure 404 {object} errors.Error "Review not found"
+// @failure 409 {object} errors.Error "The review has already been disputed"
+// @router /reviews/{id}/dispute [post]
+func (r *Routes) disputeReview(c echo.Context) error {
+	r.Log.Infof("Disputing review %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(DisputeReviewRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(*models.User)
+	err = r.ReviewService.DisputeReview(ctx, user, id, req.Reason)
+	if err != nil {
+		r.Log.Errorln("failed to dispute review", err)
+
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getDisputedReviews retrieves the reviews waiting for a decision.
+// @summary List Disputed Reviews
+// @tags Admin
+// @description Retrieves disputed reviews, the oldest dispute first.
+// @produce json
+// @success 200 {array} models.Review "Disputed reviews"
+// @failure 403 {object} errors.Error "Forbidden"
+// @router /admin/reviews/disputed [get]
+func (r *Routes) getDisputedReviews(c echo.Context) error {
+	r.Log.Infof("Getting disputed reviews ...")
+	ctx := c.Request().Context()
+
+	reviews, err := r.ReviewService.GetDisputedReviews(ctx)
+	if err != nil {
+		r.Log.Errorln("failed to get disputed reviews", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.JSON(http.StatusOK, reviews)
+}
+
+// resolveReviewDispute removes a disputed review or publishes it again.
+// @summary Resolve Review Dispute
+// @tags Admin
+// @description Removes the disputed review, or publishes it again. Removed reviews don't count in ratings.
+// @accept json
+// @produce json
+// @param id path int true "ID of the review"
+// @param request body ResolveDisputeRequest true "Whether to remove the review"
+// @success 204 "Resolved"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 404 {object} errors.Error "Review not found"
+// @failure 409 {object} errors.Error "The review is not disputed"
+// @router /admin/reviews/{id}/resolve [post]
+func (r *Routes) resolveReviewDispute(c echo.Context) error {
+	r.Log.Infof("Resolving dispute of review %s ...", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	req := new(ResolveDisputeRequest)
+	if err := r.bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	admin := c.Get("user").(*models.User)
+	err = r.ReviewService.ResolveDispute(ctx, admin, id, req.Remove)
+	if err != nil {
+		r.Log.Errorln("failed to resolve review dispute", err)
+
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
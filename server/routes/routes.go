@@ -1,31 +1,132 @@
 package routes
 
 import (
+	goerrors "errors"
 	"ypeskov/go_hillel_9/internal/config"
 	"ypeskov/go_hillel_9/internal/database"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/jwtkeys"
 	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/mailer"
+	"ypeskov/go_hillel_9/internal/oidc"
+	"ypeskov/go_hillel_9/internal/password"
 	"ypeskov/go_hillel_9/repository/repositories"
+	"ypeskov/go_hillel_9/repository/repositories/memory"
 	"ypeskov/go_hillel_9/services"
 )
 
 type Routes struct {
-	Log             *log.Logger
-	cfg             *config.Config
-	UsersService    services.UsersServiceInterface
-	ItemsService    services.ItemsServiceInterface
-	UserTypeService services.UserTypeServiceInterface
+	Log                  *log.Logger
+	cfg                  *config.Config
+	UsersService         services.UsersServiceInterface
+	ItemsService         services.ItemsServiceInterface
+	ItemsTransferService services.ItemsTransferServiceInterface
+	IdempotencyService   services.IdempotencyServiceInterface
+	UserTypeService      services.UserTypeServiceInterface
+	SessionsService      services.SessionsServiceInterface
+	RevocationService    services.RevocationServiceInterface
+	LoginAttemptsService services.LoginAttemptsServiceInterface
+	MfaService           services.MfaServiceInterface
+	ApiKeysService       services.ApiKeysServiceInterface
+	OidcService          services.OidcServiceInterface
+	UserAdminService     services.UserAdminServiceInterface
+	ProfileService       services.ProfileServiceInterface
+	ReviewService        services.ReviewServiceInterface
+	MessagesService      services.MessagesServiceInterface
+	QuestionsService     services.QuestionsServiceInterface
+	Keys                 *jwtkeys.KeySet
 }
 
-func New(log *log.Logger, db database.Database, cfg *config.Config) *Routes {
+func New(log *log.Logger, db database.Database, cfg *config.Config, keys *jwtkeys.KeySet,
+	hasher *password.Hasher, policy *password.Policy) *Routes {
 	itemsRepo := repositories.GetItemRepository(log, db)
 	userRepo := repositories.GetUserRepository(log, db)
 	userTypeRepo := repositories.GetUserTypeRepository(log, db)
+	sessionRepo := repositories.GetSessionRepository(log, db)
+	revocationRepo := repositories.GetRevocationRepository(log, db)
+	loginAttemptRepo := repositories.GetLoginAttemptRepository(log, db)
+	mfaRepo := repositories.GetMfaRepository(log, db)
+	apiKeyRepo := repositories.GetApiKeyRepository(log, db)
+	userAdminRepo := repositories.GetUserAdminRepository(log, db)
+	profileRepo := repositories.GetProfileRepository(log, db)
+	reviewRepo := repositories.GetReviewRepository(log, db)
+	messageRepo := repositories.GetMessageRepository(log, db)
+	questionRepo := repositories.GetQuestionRepository(log, db)
+	idempotencyRepo := repositories.GetIdempotencyRepository(log, db)
+	unitOfWork := repositories.GetUnitOfWork(log, db)
+
+	// db is nil in this mode, so every repository is replaced with one sharing the in-memory store
+	if cfg.InMemoryStorage {
+		store := memory.NewStore()
+		itemsRepo = memory.GetItemRepository(log, store)
+		userRepo = memory.GetUserRepository(log, store)
+		userTypeRepo = memory.GetUserTypeRepository(log, store)
+		sessionRepo = memory.GetSessionRepository(log, store)
+		revocationRepo = memory.GetRevocationRepository(log, store)
+		loginAttemptRepo = memory.GetLoginAttemptRepository(log, store)
+		mfaRepo = memory.GetMfaRepository(log, store)
+		apiKeyRepo = memory.GetApiKeyRepository(log, store)
+		userAdminRepo = memory.GetUserAdminRepository(log, store)
+		profileRepo = memory.GetProfileRepository(log, store)
+		reviewRepo = memory.GetReviewRepository(log, store)
+		messageRepo = memory.GetMessageRepository(log, store)
+		questionRepo = memory.GetQuestionRepository(log, store)
+		idempotencyRepo = memory.GetIdempotencyRepository(log, store)
+		unitOfWork = memory.GetUnitOfWork(log, store)
+	}
+
+	revocationService := services.GetRevocationService(revocationRepo, sessionRepo, log, cfg)
+	loginAttemptsService := services.GetLoginAttemptsService(loginAttemptRepo, log, cfg)
+	mfaService := services.GetMfaService(mfaRepo, log, cfg)
+	sessionsService := services.GetSessionsService(userRepo, sessionRepo, revocationService,
+		loginAttemptsService, mfaService, keys, hasher, log, cfg)
+
+	var oidcClient services.OidcClient
+	if cfg.OidcIssuerUrl != "" {
+		oidcClient = oidc.NewClient(oidc.Config{
+			IssuerUrl:    cfg.OidcIssuerUrl,
+			ClientId:     cfg.OidcClientId,
+			ClientSecret: cfg.OidcClientSecret,
+			RedirectUrl:  cfg.OidcRedirectUrl,
+			Scopes:       cfg.OidcScopes,
+		})
+	}
+	oidcService := services.GetOidcService(oidcClient, userRepo, userTypeRepo, sessionsService, hasher,
+		log, cfg)
+
+	usersService := services.GetUserService(userRepo, userTypeRepo, mailer.New(cfg, log), hasher, policy,
+		log, cfg)
+	userAdminService := services.GetUserAdminService(userAdminRepo, itemsRepo, userTypeRepo, unitOfWork,
+		revocationService, log, cfg)
 
 	return &Routes{
-		Log:             log,
-		cfg:             cfg,
-		ItemsService:    services.GetItemService(itemsRepo, userTypeRepo, log, cfg),
-		UsersService:    services.GetUserService(userRepo, log, cfg),
-		UserTypeService: services.GetUserTypeService(userTypeRepo, log, cfg),
+		Log:                  log,
+		cfg:                  cfg,
+		ItemsService:         services.GetItemService(itemsRepo, userTypeRepo, userRepo, log, cfg),
+		ItemsTransferService: services.GetItemsTransferService(itemsRepo, userTypeRepo, unitOfWork, log, cfg),
+		IdempotencyService:   services.GetIdempotencyService(idempotencyRepo, log, cfg),
+		UsersService:         usersService,
+		UserTypeService:      services.GetUserTypeService(userTypeRepo, log, cfg),
+		SessionsService:      sessionsService,
+		RevocationService:    revocationService,
+		LoginAttemptsService: loginAttemptsService,
+		MfaService:           mfaService,
+		ApiKeysService:       services.GetApiKeysService(apiKeyRepo, userRepo, log, cfg),
+		OidcService:          oidcService,
+		UserAdminService:     userAdminService,
+		ProfileService:       services.GetProfileService(profileRepo, itemsRepo, reviewRepo, log, cfg),
+		ReviewService:        services.GetReviewService(reviewRepo, itemsRepo, log, cfg),
+		MessagesService:      services.GetMessagesService(messageRepo, userRepo, itemsRepo, unitOfWork, log, cfg),
+		QuestionsService:     services.GetQuestionsService(questionRepo, itemsRepo, log, cfg),
+		Keys:                 keys,
 	}
 }
+
+// notFoundAs replaces NotFoundErr of the repositories with a more specific error for the response
+func notFoundAs(err error, notFound errors.Error) error {
+	if goerrors.Is(err, errors.NotFoundErr) {
+		return notFound
+	}
+
+	return err
+}
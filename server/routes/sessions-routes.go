@@ -0,0 +1,103 @@
+package routes
+
+import (
+	goerrors "errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/repository/models"
+)
+
+func (r *Routes) RegisterSessionsRoutes(g *echo.Group) {
+	g.GET("/", r.getSessionsList)
+	g.DELETE("/others/", r.revokeOtherSessions)
+	g.DELETE("/:id", r.revokeSession)
+}
+
+// getSessionsList retrieves the active sessions of the current user.
+// @summary Get Sessions List
+// @tags Sessions
+// @description Retrieves the active sessions of the current user. The session of the request is marked as current.
+// @produce json
+// @success 200 {array} models.Session "List of sessions"
+// @failure 500 {object} errors.Error "Internal server error"
+// @router /users/sessions/ [get]
+func (r *Routes) getSessionsList(c echo.Context) error {
+	r.Log.Infof("Getting sessions list ...")
+	ctx := c.Request().Context()
+
+	user := c.Get("user").(*models.User)
+	sessionId := c.Get("sessionId").(int)
+
+	sessions, err := r.SessionsService.GetSessionsList(ctx, user.Id, sessionId)
+	if err != nil {
+		r.Log.Errorln("failed to get sessions from db", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.JSON(http.StatusOK, &sessions)
+}
+
+// revokeSession revokes one of the sessions of the current user.
+// @summary Revoke Session
+// @tags Sessions
+// @description Revokes a session by its ID. The refresh token of the session stops working.
+// @produce json
+// @param id path int true "ID of the session to revoke"
+// @success 204 "Session revoked"
+// @failure 400 {object} errors.Error "Bad Request: Invalid ID"
+// @failure 404 {object} errors.Error "Session not found"
+// @router /users/sessions/{id} [delete]
+func (r *Routes) revokeSession(c echo.Context) error {
+	r.Log.Infof("Revoke session with id: %s", c.Param("id"))
+	ctx := c.Request().Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		r.Log.Errorln("failed to convert id to int", err)
+
+		return errors.InvalidIdErr
+	}
+
+	user := c.Get("user").(*models.User)
+	err = r.SessionsService.RevokeSession(ctx, user.Id, id)
+	if err != nil {
+		if goerrors.Is(err, errors.NotFoundErr) {
+			r.Log.Errorln("session not found", err)
+
+			return errors.SessionNotFoundErr
+		}
+		r.Log.Errorln("failed to revoke session", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// revokeOtherSessions revokes all sessions of the current user except the one of the request.
+// @summary Revoke Other Sessions
+// @tags Sessions
+// @description Logs out all other devices of the current user.
+// @produce json
+// @success 204 "Sessions revoked"
+// @failure 500 {object} errors.Error "Internal server error"
+// @router /users/sessions/others/ [delete]
+func (r *Routes) revokeOtherSessions(c echo.Context) error {
+	r.Log.Infof("Revoking other sessions ...")
+	ctx := c.Request().Context()
+
+	user := c.Get("user").(*models.User)
+	sessionId := c.Get("sessionId").(int)
+
+	err := r.SessionsService.RevokeOtherSessions(ctx, user.Id, sessionId)
+	if err != nil {
+		r.Log.Errorln("failed to revoke other sessions", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
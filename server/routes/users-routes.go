@@ -2,11 +2,12 @@ package routes
 
 import (
 	goerrors "errors"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"math"
 	"net/http"
-	"time"
+	"strconv"
 	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/validation"
 	"ypeskov/go_hillel_9/repository/models"
 	"ypeskov/go_hillel_9/services"
 )
@@ -14,18 +15,43 @@ import (
 type Credentials struct {
 	Password string `json:"password"`
 	Email    string `json:"email"`
+	Device   string `json:"device"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MfaLoginRequest struct {
+	MfaToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+	Device   string `json:"device"`
 }
 
 type TokenResponse struct {
-	AccessToken  string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken"`
+	AccessToken           string `json:"accessToken"`
+	RefreshToken          string `json:"refreshToken"`
+	MfaEnrollmentRequired bool   `json:"mfaEnrollmentRequired,omitempty"`
 }
 
-func (r *Routes) RegisterUsersRoutes(g *echo.Group) {
+// LoginResponse holds tokens or, when two-factor authentication is enabled, a token for POST /users/login/mfa/
+type LoginResponse struct {
+	AccessToken           string `json:"accessToken,omitempty"`
+	RefreshToken          string `json:"refreshToken,omitempty"`
+	MfaEnrollmentRequired bool   `json:"mfaEnrollmentRequired,omitempty"`
+	MfaRequired           bool   `json:"mfaRequired,omitempty"`
+	MfaToken              string `json:"mfaToken,omitempty"`
+}
+
+func (r *Routes) RegisterUsersRoutes(g *echo.Group, mfaEnrollmentAuth echo.MiddlewareFunc) {
 	g.GET("/", r.getUsersList)
 	g.POST("/", r.createUser)
 	g.POST("/login/", r.LoginUser)
+	g.POST("/login/mfa/", r.loginMfa)
 	g.POST("/refresh/", r.getNewAccessToken)
+	g.POST("/logout/", r.logout, mfaEnrollmentAuth)
+	g.GET("/verify-email/", r.verifyEmail)
+	g.POST("/verify-email/resend/", r.resendVerificationEmail)
 }
 
 // getUsersList retrieves a list of users.
@@ -40,13 +66,13 @@ func (r *Routes) RegisterUsersRoutes(g *echo.Group) {
 // @router /users/ [get]
 func (r *Routes) getUsersList(c echo.Context) error {
 	r.Log.Infof("Getting users list ...")
+	ctx := c.Request().Context()
 
-	users, err := r.UsersService.GetUsersList()
+	users, err := r.UsersService.GetUsersList(ctx)
 	if err != nil {
 		r.Log.Error("failed to get users from db", err)
 
-		return c.JSON(http.StatusInternalServerError,
-			errors.NewError("INTERNAL_SERVER_ERROR", "Failed to get users from db"))
+		return errors.InternalServerErr
 	}
 
 	return c.JSON(http.StatusOK, &users)
@@ -61,10 +87,11 @@ func (r *Routes) getUsersList(c echo.Context) error {
 // @produce json
 // @param user body models.User true "User details"
 // @success 201 {object} models.User "User created successfully"
-// @failure 400 {object} errors.Error "Bad Request: Failed to parse request body or validation failed"
+// @failure 400 {object} errors.Error "Bad Request: Failed to parse request body, validation failed, unknown or not self-assignable user type or weak password"
 // @router /users/ [post]
 func (r *Routes) createUser(c echo.Context) error {
 	r.Log.Infof("Creating user ...")
+	ctx := c.Request().Context()
 
 	req := new(models.User)
 
@@ -72,23 +99,25 @@ func (r *Routes) createUser(c echo.Context) error {
 	if err != nil {
 		r.Log.Errorln("failed to parse request body", err)
 
-		return c.JSON(http.StatusBadRequest,
-			errors.NewError("BAD_REQUEST", "Failed to parse request body"))
+		return errors.BadRequestErr
 	}
 
 	err = req.Validate()
 	if err != nil {
 		r.Log.Errorln("failed to validate request body", err)
 
-		return c.JSON(http.StatusBadRequest, errors.NewError("VALIDATION_FAILED", err.Error()))
+		return err
 	}
 
-	newUser, err := r.UsersService.CreateUser(req)
+	newUser, err := r.UsersService.CreateUser(ctx, req)
 	if err != nil {
 		r.Log.Errorln("failed to create user", err)
+		if goerrors.Is(err, errors.WeakPasswordErr) {
+
+			return errors.WeakPasswordErr.WithMessage(err.Error())
+		}
 
-		return c.JSON(http.StatusInternalServerError,
-			errors.NewError("INTERNAL_SERVER_ERROR", "Failed to create user"))
+		return err
 	}
 	newUser.PasswordHash = ""
 
@@ -96,101 +125,235 @@ func (r *Routes) createUser(c echo.Context) error {
 }
 
 // LoginUser logs in a user based on the provided credentials.
-// It returns a JWT token if the login is successful or an error if it fails.
+// Every login opens a new session, so logging in on one device doesn't log out the others.
 // @summary Login User
 // @tags Users
 // @description Logs in a user based on the provided credentials.
 // @accept json
 // @produce json
 // @param user body Credentials true "User credentials"
-// @success 200 {object} string "JWT"
+// @success 200 {object} LoginResponse "Access and refresh tokens or an MFA challenge token"
 // @failure 400 {object} errors.Error "Bad Request"
 // @failure 401 {object} errors.Error "Unauthorized"
+// @failure 429 {object} errors.Error "Too many failed attempts, see the Retry-After header"
 // @router /users/login/ [post]
 func (r *Routes) LoginUser(c echo.Context) error {
 	r.Log.Infof("Logging in user ...")
+	ctx := c.Request().Context()
 
 	var creds Credentials
 	err := c.Bind(&creds)
 	if err != nil {
 		r.Log.Errorln("failed to parse request body", err)
 
-		return c.JSON(http.StatusBadRequest, errors.BadRequestErr)
+		return errors.BadRequestErr
+	}
+
+	result, err := r.SessionsService.Login(ctx, creds.Email, creds.Password, sessionClient(c, creds.Device))
+	if err != nil {
+		r.Log.Errorln("failed to login user", err)
+
+		return loginError(c, err)
+	}
+
+	if result.MfaToken != "" {
+		return c.JSON(http.StatusOK, &LoginResponse{
+			MfaRequired: true,
+			MfaToken:    result.MfaToken,
+		})
+	}
+
+	return c.JSON(http.StatusOK, &LoginResponse{
+		AccessToken:           result.Tokens.AccessToken,
+		RefreshToken:          result.Tokens.RefreshToken,
+		MfaEnrollmentRequired: result.Tokens.MfaEnrollmentRequired,
+	})
+}
+
+// loginMfa completes the login of a user with two-factor authentication.
+// @summary Login With One-Time Code
+// @tags Users
+// @description Exchanges the MFA token returned by the login and a code from the authenticator app
+// @description or a recovery code for access and refresh tokens.
+// @accept json
+// @produce json
+// @param request body MfaLoginRequest true "MFA token and one-time code"
+// @success 200 {object} TokenResponse "Access and refresh tokens"
+// @failure 400 {object} errors.Error "Bad Request"
+// @failure 401 {object} errors.Error "Invalid token or code"
+// @failure 429 {object} errors.Error "Too many failed attempts, see the Retry-After header"
+// @router /users/login/mfa/ [post]
+func (r *Routes) loginMfa(c echo.Context) error {
+	r.Log.Infof("Verifying one-time code ...")
+	ctx := c.Request().Context()
+
+	req := new(MfaLoginRequest)
+	err := c.Bind(req)
+	if err != nil {
+		r.Log.Errorln("failed to parse request body", err)
+
+		return errors.BadRequestErr
 	}
 
-	minutes := time.Duration(r.cfg.AccessTokenLifetimeMinutes)
-	token, err := r.UsersService.GetJWT(creds.Email, creds.Password, minutes, false)
+	err = validation.Struct(req)
 	if err != nil {
-		r.Log.Errorln("failed to get JWT", err)
+		r.Log.Errorln("failed to validate request body", err)
 
-		return c.JSON(http.StatusUnauthorized, errors.UnauthorizedErr)
+		return err
 	}
 
-	refreshToken, err := r.UsersService.GetRefreshToken(creds.Email, creds.Password, false)
+	tokens, err := r.SessionsService.VerifyMfa(ctx, req.MfaToken, req.Code, sessionClient(c, req.Device))
 	if err != nil {
-		r.Log.Errorln("failed to get refresh token", err)
+		r.Log.Errorln("failed to verify one-time code", err)
 
-		return c.JSON(http.StatusInternalServerError, errors.InternalServerErr)
+		return loginError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, &TokenResponse{
-		AccessToken:  token,
-		RefreshToken: refreshToken,
+		AccessToken:           tokens.AccessToken,
+		RefreshToken:          tokens.RefreshToken,
+		MfaEnrollmentRequired: tokens.MfaEnrollmentRequired,
 	})
 }
 
+// loginError sets the Retry-After header for throttled logins
+func loginError(c echo.Context, err error) error {
+	var throttled *services.LoginThrottledError
+	if goerrors.As(err, &throttled) {
+		retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+		return errors.TooManyLoginAttemptsErr
+	}
+
+	return err
+}
+
+// getNewAccessToken exchanges a refresh token for a new pair of tokens.
+// @summary Refresh Tokens
+// @tags Users
+// @description Issues new access and refresh tokens. The refresh token can be used only once.
+// @produce json
+// @param Refresh-Token header string true "Refresh token"
+// @success 200 {object} TokenResponse "Access and refresh tokens"
+// @failure 401 {object} errors.Error "Unauthorized"
+// @router /users/refresh/ [post]
 func (r *Routes) getNewAccessToken(c echo.Context) error {
 	r.Log.Infof("Refreshing access token ...")
+	ctx := c.Request().Context()
 	refreshToken := c.Request().Header.Get("Refresh-Token")
 
-	// Check if the refresh token is not expired
-	claims := &services.Claims{}
-	_, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (any, error) {
-		return []byte(r.cfg.SecretKey), nil
-	})
+	tokens, err := r.SessionsService.Refresh(ctx, refreshToken, sessionClient(c, ""))
 	if err != nil {
-		if goerrors.Is(err, jwt.ErrTokenExpired) {
-			r.Log.Errorln("token expired")
-
-			return c.JSON(http.StatusUnauthorized, errors.TokenExpiredErr)
-		}
-		r.Log.Errorln("failed to parse token", err)
+		r.Log.Errorln("failed to refresh tokens", err)
 
-		return c.JSON(http.StatusUnauthorized, errors.UnauthorizedErr)
+		return err
 	}
 
-	// then check if refresh token is valid and not used
-	user, err := r.UsersService.GetUserByRefreshToken(refreshToken)
+	return c.JSON(http.StatusOK, &TokenResponse{
+		AccessToken:           tokens.AccessToken,
+		RefreshToken:          tokens.RefreshToken,
+		MfaEnrollmentRequired: tokens.MfaEnrollmentRequired,
+	})
+}
+
+// logout closes the session the access token belongs to.
+// @summary Logout
+// @tags Users
+// @description Revokes the current session, its refresh token and the access token of the request.
+// @produce json
+// @success 204 "Logged out"
+// @failure 401 {object} errors.Error "Unauthorized"
+// @router /users/logout/ [post]
+func (r *Routes) logout(c echo.Context) error {
+	r.Log.Infof("Logging out user ...")
+	ctx := c.Request().Context()
+
+	claims := c.Get("claims").(*services.Claims)
+
+	err := r.SessionsService.Logout(ctx, claims)
 	if err != nil {
-		r.Log.Errorln("failed to get user by refresh accessToken", err)
+		r.Log.Errorln("failed to logout", err)
 
-		return c.JSON(http.StatusUnauthorized, errors.UnauthorizedErr)
+		return errors.InternalServerErr
 	}
 
-	// check if user in DB is the same as in token
-	if user.Email != claims.Email && user.Id != claims.Id {
-		r.Log.Errorln("user in token is not the same as in DB")
+	return c.NoContent(http.StatusNoContent)
+}
+
+func sessionClient(c echo.Context, device string) services.SessionClient {
+	return services.SessionClient{
+		Device:    device,
+		UserAgent: c.Request().UserAgent(),
+		Ip:        c.RealIP(),
+	}
+}
+
+// verifyEmail confirms the email address of a user with the token sent on sign-up.
+// @summary Verify Email
+// @tags Users
+// @description Marks the email of the user as verified if the token is valid and not expired.
+// @produce json
+// @param token query string true "Verification token from the email"
+// @success 204 "Email verified"
+// @failure 400 {object} errors.Error "Invalid or expired token"
+// @router /users/verify-email/ [get]
+func (r *Routes) verifyEmail(c echo.Context) error {
+	r.Log.Infof("Verifying email ...")
+	ctx := c.Request().Context()
+
+	token := c.QueryParam("token")
+	if token == "" {
+		r.Log.Errorln("verification token is empty")
+
+		return errors.InvalidVerificationTokenErr
+	}
 
-		return c.JSON(http.StatusUnauthorized, errors.UnauthorizedErr)
+	err := r.UsersService.VerifyEmail(ctx, token)
+	if err != nil {
+		r.Log.Errorln("failed to verify email", err)
+		return err
 	}
 
-	minutes := time.Duration(r.cfg.AccessTokenLifetimeMinutes)
-	accessToken, err := r.UsersService.GetJWT(user.Email, "", minutes, true)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// resendVerificationEmail sends a new verification email.
+// It responds the same way for unknown emails, so it can't be used to look up accounts.
+// @summary Resend Verification Email
+// @tags Users
+// @description Sends a new email verification link to the user.
+// @accept json
+// @produce json
+// @param request body ResendVerificationRequest true "Email of the user"
+// @success 204 "Email sent if the account exists and is not verified"
+// @failure 400 {object} errors.Error "Bad Request"
+// @router /users/verify-email/resend/ [post]
+func (r *Routes) resendVerificationEmail(c echo.Context) error {
+	r.Log.Infof("Resending verification email ...")
+	ctx := c.Request().Context()
+
+	req := new(ResendVerificationRequest)
+	err := c.Bind(req)
 	if err != nil {
-		r.Log.Errorln("failed to get JWT", err)
+		r.Log.Errorln("failed to parse request body", err)
 
-		return c.JSON(http.StatusUnauthorized, errors.UnauthorizedErr)
+		return errors.BadRequestErr
 	}
 
-	newRefreshToken, err := r.UsersService.GetRefreshToken(user.Email, "", true)
+	err = validation.Struct(req)
 	if err != nil {
-		r.Log.Errorln("failed to get refresh token", err)
+		r.Log.Errorln("failed to validate request body", err)
 
-		return c.JSON(http.StatusInternalServerError, errors.InternalServerErr)
+		return err
 	}
 
-	return c.JSON(http.StatusOK, &TokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
-	})
+	err = r.UsersService.ResendVerificationEmail(ctx, req.Email)
+	if err != nil && !goerrors.Is(err, errors.NotFoundErr) {
+		r.Log.Errorln("failed to resend verification email", err)
+
+		return errors.InternalServerErr
+	}
+
+	return c.NoContent(http.StatusNoContent)
 }
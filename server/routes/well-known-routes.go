@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+func (r *Routes) RegisterWellKnownRoutes(g *echo.Group) {
+	g.GET("/jwks.json", r.getJwks)
+}
+
+// getJwks publishes the public keys tokens are signed with.
+// @summary JSON Web Key Set
+// @tags Auth
+// @description Public keys other services can use to verify access tokens. Empty when tokens are signed with HS256.
+// @produce json
+// @success 200 {object} jwtkeys.JWKS "Key set"
+// @router /.well-known/jwks.json [get]
+func (r *Routes) getJwks(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+
+	return c.JSON(http.StatusOK, r.Keys.JWKS())
+}
@@ -2,10 +2,13 @@ package server
 
 import (
 	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
 	_ "ypeskov/go_hillel_9/docs"
 	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/i18n"
 	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
 	"ypeskov/go_hillel_9/server/middleware"
 	"ypeskov/go_hillel_9/server/routes"
 )
@@ -16,19 +19,64 @@ type Server struct {
 	log  *log.Logger
 }
 
-func New(cfg *config.Config, handlers *routes.Routes) *Server {
+func New(cfg *config.Config, handlers *routes.Routes, catalogue *i18n.Catalogue) *Server {
 	e := echo.New()
+	e.HTTPErrorHandler = errorHandler(handlers.Log, catalogue)
+	// a panicking handler gets the usual 500 response instead of a dropped connection
+	e.Use(echoMiddleware.Recover())
 
 	// e.Use(middleware.Logger())
 
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
+	wellKnownGroup := e.Group("/.well-known")
+	handlers.RegisterWellKnownRoutes(wellKnownGroup)
+
+	auth := middleware.AuthMiddleware(handlers.Log, handlers.Keys, handlers.UsersService, handlers.RevocationService,
+		handlers.ApiKeysService)
+	sessionAuth := middleware.SessionAuthMiddleware(handlers.Log, handlers.Keys, handlers.UsersService,
+		handlers.RevocationService)
+	mfaEnrollmentAuth := middleware.MfaEnrollmentAuthMiddleware(handlers.Log, handlers.Keys,
+		handlers.UsersService, handlers.RevocationService)
+
 	itemsGroup := e.Group("/items")
-	itemsGroup.Use(middleware.AuthMiddleware(handlers.Log, cfg, handlers.UsersService))
-	handlers.RegisterItemsRoutes(itemsGroup)
+	itemsGroup.Use(auth, middleware.ScopeMiddleware(handlers.Log, models.ScopeItemsRead, models.ScopeItemsWrite))
+	handlers.RegisterItemsRoutes(itemsGroup, middleware.IdempotencyMiddleware(handlers.Log, handlers.IdempotencyService))
 
 	usersGroup := e.Group("/users")
-	handlers.RegisterUsersRoutes(usersGroup)
+	handlers.RegisterUsersRoutes(usersGroup, mfaEnrollmentAuth)
+	handlers.RegisterProfileRoutes(usersGroup)
+
+	oidcGroup := e.Group("/users/oidc")
+	handlers.RegisterOidcRoutes(oidcGroup)
+
+	mfaGroup := e.Group("/users/mfa")
+	mfaGroup.Use(mfaEnrollmentAuth)
+	handlers.RegisterMfaRoutes(mfaGroup)
+
+	sessionsGroup := e.Group("/users/sessions")
+	sessionsGroup.Use(sessionAuth)
+	handlers.RegisterSessionsRoutes(sessionsGroup)
+
+	apiKeysGroup := e.Group("/users/api-keys")
+	apiKeysGroup.Use(sessionAuth)
+	handlers.RegisterApiKeysRoutes(apiKeysGroup)
+
+	reviewsGroup := e.Group("/reviews")
+	reviewsGroup.Use(sessionAuth)
+	handlers.RegisterReviewsRoutes(reviewsGroup)
+
+	conversationsGroup := e.Group("/conversations")
+	conversationsGroup.Use(sessionAuth)
+	handlers.RegisterConversationsRoutes(conversationsGroup)
+
+	blocksGroup := e.Group("/users/blocks")
+	blocksGroup.Use(sessionAuth)
+	handlers.RegisterBlocksRoutes(blocksGroup)
+
+	adminGroup := e.Group("/admin")
+	adminGroup.Use(sessionAuth, middleware.AdminMiddleware(handlers.Log, handlers.UsersService))
+	handlers.RegisterAdminRoutes(adminGroup)
 
 	return &Server{
 		e:    e,
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/i18n"
+	"ypeskov/go_hillel_9/internal/jwtkeys"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/password"
+	"ypeskov/go_hillel_9/server/middleware"
+	"ypeskov/go_hillel_9/server/routes"
+)
+
+// newInMemoryServer runs the whole API on the in-memory repositories
+func newInMemoryServer(t *testing.T) (*Server, *routes.Routes) {
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+	cfg.InMemoryStorage = true
+	cfg.Argon2MemoryKib = 1024
+	cfg.Argon2Iterations = 1
+
+	logger := log.New(cfg)
+	keys, err := jwtkeys.Load(cfg)
+	require.NoError(t, err)
+	hasher, err := password.NewHasher(cfg)
+	require.NoError(t, err)
+	policy, err := password.NewPolicy(cfg)
+	require.NoError(t, err)
+	catalogue, err := i18n.New(cfg)
+	require.NoError(t, err)
+
+	handlers := routes.New(logger, nil, cfg, keys, hasher, policy)
+
+	return New(cfg, handlers, catalogue), handlers
+}
+
+func request(s *Server, method string, path string, body string, token string) *httptest.ResponseRecorder {
+	return requestWithHeaders(s, method, path, body, token, nil)
+}
+
+func requestWithHeaders(s *Server, method string, path string, body string, token string,
+	headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Auth-Token", "Bearer "+token)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	s.e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestInMemoryStorage(t *testing.T) {
+	s, _ := newInMemoryServer(t)
+
+	rec := request(s, http.MethodPost, "/users/", `{"firstName": "Jane", "lastName": "Doe",
+		"email": "jane@example.com", "password": "correct horse battery", "userTypeId": 1}`, "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = request(s, http.MethodPost, "/users/", `{"firstName": "Jane", "lastName": "Doe",
+		"email": "jane@example.com", "password": "correct horse battery", "userTypeId": 1}`, "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = request(s, http.MethodPost, "/users/", `{"firstName": "Eve", "lastName": "Doe",
+		"email": "eve@example.com", "password": "correct horse battery", "userTypeId": 3}`, "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "USER_TYPE_NOT_SELF_ASSIGNABLE")
+
+	rec = request(s, http.MethodPost, "/users/login/",
+		`{"email": "jane@example.com", "password": "correct horse battery"}`, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var login routes.LoginResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &login))
+
+	rec = request(s, http.MethodGet, "/items/", "", login.AccessToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+
+	rec = request(s, http.MethodPost, "/items/", `{"title": "Bike", "initialPrice": 100}`, login.AccessToken)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = request(s, http.MethodPost, "/users/logout/", "", login.AccessToken)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = request(s, http.MethodGet, "/items/", "", login.AccessToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// signUp registers a verified user and returns an access token of the user
+func signUp(t *testing.T, s *Server, handlers *routes.Routes, email string, userTypeId int) string {
+	rec := request(s, http.MethodPost, "/users/", fmt.Sprintf(`{"firstName": "Jane", "lastName": "Doe",
+		"email": %q, "password": "correct horse battery", "userTypeId": %d}`, email, userTypeId), "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	user := handlers.UsersService.GetUserByEmail(context.Background(), email)
+	require.NotNil(t, user)
+	require.NoError(t, handlers.UsersService.SetEmailVerified(context.Background(), user.Id))
+
+	rec = request(s, http.MethodPost, "/users/login/",
+		fmt.Sprintf(`{"email": %q, "password": "correct horse battery"}`, email), "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var login routes.LoginResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &login))
+
+	return login.AccessToken
+}
+
+func TestInMemoryMarketplace(t *testing.T) {
+	s, handlers := newInMemoryServer(t)
+	seller := signUp(t, s, handlers, "seller@example.com", 1)
+	buyer := signUp(t, s, handlers, "buyer@example.com", 2)
+
+	rec := request(s, http.MethodPost, "/items/", `{"title": "Bike", "initialPrice": 100}`, seller)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = request(s, http.MethodPost, "/items/1/questions", `{"question": "Is it red?"}`, buyer)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = request(s, http.MethodPost, "/items/1/questions/1/answer", `{"answer": "Yes"}`, seller)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = request(s, http.MethodPost, "/conversations/", `{"recipientId": 1, "itemId": 1, "body": "Hi"}`, buyer)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = request(s, http.MethodGet, "/conversations/unread", "", seller)
+	assert.JSONEq(t, `{"unread": 1}`, rec.Body.String())
+
+	rec = request(s, http.MethodPost, "/items/1/sale", `{"buyerId": 2, "soldPrice": 90}`, seller)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = request(s, http.MethodPost, "/items/1/sale/confirm", "", buyer)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = request(s, http.MethodPost, "/reviews/", `{"itemId": 1, "rating": 4}`, buyer)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = request(s, http.MethodPost, "/reviews/", `{"itemId": 1, "rating": 5}`, buyer)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "REVIEW_EXISTS")
+
+	rec = request(s, http.MethodGet, "/users/1/profile", "", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var profile map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &profile))
+	assert.Equal(t, 4.0, profile["averageRating"])
+	assert.Equal(t, 1.0, profile["itemsSold"])
+
+	rec = request(s, http.MethodPost, "/users/api-keys/", `{"name": "cli", "scopes": ["items:read"]}`, seller)
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+}
+
+func TestItemsImportMaxBytes(t *testing.T) {
+	t.Setenv("ITEMS_IMPORT_MAX_BYTES", "64")
+	s, handlers := newInMemoryServer(t)
+	seller := signUp(t, s, handlers, "seller@example.com", 1)
+	headers := map[string]string{"Content-Type": "text/csv"}
+
+	rec := requestWithHeaders(s, http.MethodPost, "/items/import", "title,initial_price\nBike,100\n", seller, headers)
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	file := "title,initial_price\n" + strings.Repeat("Bike,100\n", 10)
+	rec = requestWithHeaders(s, http.MethodPost, "/items/import", file, seller, headers)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "IMPORT_TOO_LARGE")
+
+	var form strings.Builder
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("file", "items.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(file))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	rec = requestWithHeaders(s, http.MethodPost, "/items/import", form.String(), seller,
+		map[string]string{"Content-Type": writer.FormDataContentType()})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "IMPORT_TOO_LARGE")
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	s, handlers := newInMemoryServer(t)
+	token := signUp(t, s, handlers, "jane@example.com", 1)
+
+	key := map[string]string{middleware.IdempotencyKeyHeader: "create-bike"}
+	first := requestWithHeaders(s, http.MethodPost, "/items/", `{"title": "Bike", "initialPrice": 100}`,
+		token, key)
+	require.Equal(t, http.StatusCreated, first.Code, first.Body.String())
+	assert.Empty(t, first.Header().Get(middleware.IdempotentReplayedHeader))
+
+	retry := requestWithHeaders(s, http.MethodPost, "/items/", `{"title": "Bike", "initialPrice": 100}`,
+		token, key)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+
+	rec := requestWithHeaders(s, http.MethodPost, "/items/", `{"title": "Car", "initialPrice": 100}`,
+		token, key)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = requestWithHeaders(s, http.MethodPost, "/items/", `{"title": "Bike", "initialPrice": 100}`,
+		token, map[string]string{middleware.IdempotencyKeyHeader: strings.Repeat("k", 256)})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = request(s, http.MethodGet, "/items/", "", token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &items))
+	assert.Len(t, items, 1)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"slices"
+	"strings"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// ApiKeysService manages long-lived keys of scripts and other machine clients.
+// Only a hash of a key is stored, the key itself is returned once when it is created.
+type ApiKeysService struct {
+	log        *log.Logger
+	cfg        *config.Config
+	apiKeyRepo repositories.ApiKeyRepositoryInterface
+	userRepo   repositories.UserRepositoryInterface
+}
+
+type ApiKeysServiceInterface interface {
+	CreateApiKey(ctx context.Context, user *models.User, name string, scopes []string,
+		expiresAt *time.Time) (*models.ApiKey, string, error)
+	GetApiKeysList(ctx context.Context, userId int) ([]*models.ApiKey, error)
+	RevokeApiKey(ctx context.Context, userId int, id int) error
+	Authenticate(ctx context.Context, key string) (*models.User, *models.ApiKey, error)
+}
+
+const (
+	apiKeyPrefix = "ak_"
+	apiKeyBytes  = 32
+	// length of the part of a key that is kept in plain text to tell keys apart
+	apiKeyVisibleLength = len(apiKeyPrefix) + 8
+)
+
+func GetApiKeysService(apiKeyRepo repositories.ApiKeyRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface, log *log.Logger, cfg *config.Config) ApiKeysServiceInterface {
+
+	return &ApiKeysService{
+		log:        log,
+		cfg:        cfg,
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (as *ApiKeysService) CreateApiKey(ctx context.Context, user *models.User, name string, scopes []string,
+	expiresAt *time.Time) (*models.ApiKey, string, error) {
+	for _, scope := range scopes {
+		if !slices.Contains(models.ApiKeyScopes, scope) {
+			return nil, "", errors.InvalidScopeErr
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", errors.InvalidApiKeyExpiryErr
+	}
+
+	secret, err := generateToken(apiKeyBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	key := apiKeyPrefix + secret
+
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+
+	if expiresAt != nil {
+		utc := expiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	apiKey, err := as.apiKeyRepo.CreateApiKey(ctx, &models.ApiKey{
+		UserId:    user.Id,
+		Name:      name,
+		Prefix:    key[:apiKeyVisibleLength],
+		KeyHash:   hashToken(key),
+		Scopes:    strings.Join(slices.Compact(scopes), " "),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+func (as *ApiKeysService) GetApiKeysList(ctx context.Context, userId int) ([]*models.ApiKey, error) {
+	return as.apiKeyRepo.GetApiKeysList(ctx, userId)
+}
+
+func (as *ApiKeysService) RevokeApiKey(ctx context.Context, userId int, id int) error {
+	return as.apiKeyRepo.RevokeApiKey(ctx, id, userId)
+}
+
+// Authenticate returns the owner of an active key and records that the key was used
+func (as *ApiKeysService) Authenticate(ctx context.Context, key string) (*models.User, *models.ApiKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, nil, errors.InvalidApiKeyErr
+	}
+
+	apiKey, err := as.apiKeyRepo.GetApiKeyByHash(ctx, hashToken(key))
+	if err != nil {
+		if goErrors.Is(err, errors.NotFoundErr) {
+			return nil, nil, errors.InvalidApiKeyErr
+		}
+
+		return nil, nil, err
+	}
+
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now().UTC())) {
+		return nil, nil, errors.InvalidApiKeyErr
+	}
+
+	user := as.userRepo.GetUserById(ctx, apiKey.UserId)
+	if user == nil {
+		return nil, nil, errors.InvalidApiKeyErr
+	}
+
+	err = as.apiKeyRepo.TouchApiKey(ctx, apiKey.Id)
+	if err != nil {
+		as.log.Errorln("failed to update last use of api key", err)
+	}
+
+	return user, apiKey, nil
+}
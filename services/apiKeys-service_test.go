@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestCreateApiKey(t *testing.T) {
+	ctx := context.Background()
+	mockApiKeyRepo := new(mocks.ApiKeyRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetApiKeysService(mockApiKeyRepo, new(mocks.UserRepositoryInterface), mockLog, mockCfg)
+
+	user := &models.User{Id: 1}
+
+	t.Run("Unknown scope", func(t *testing.T) {
+		apiKey, key, err := service.CreateApiKey(ctx, user, "import", []string{"users:delete"}, nil)
+		assert.Nil(t, apiKey)
+		assert.Empty(t, key)
+		assert.ErrorIs(t, err, apperrors.InvalidScopeErr)
+	})
+
+	t.Run("Expiry in the past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		_, _, err := service.CreateApiKey(ctx, user, "import", []string{models.ScopeItemsRead}, &expiresAt)
+		assert.ErrorIs(t, err, apperrors.InvalidApiKeyExpiryErr)
+	})
+
+	t.Run("Stores only a hash of the key", func(t *testing.T) {
+		var stored *models.ApiKey
+		mockApiKeyRepo.On("CreateApiKey", mock.Anything, mock.AnythingOfType("*models.ApiKey")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(*models.ApiKey) }).
+			Return(&models.ApiKey{Id: 3}, nil)
+
+		scopes := []string{models.ScopeItemsWrite, models.ScopeItemsRead, models.ScopeItemsRead}
+		apiKey, key, err := service.CreateApiKey(ctx, user, "import", scopes, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, apiKey.Id)
+		assert.True(t, strings.HasPrefix(key, apiKeyPrefix))
+		assert.Equal(t, hashToken(key), stored.KeyHash)
+		assert.NotContains(t, stored.KeyHash, key)
+		assert.True(t, strings.HasPrefix(key, stored.Prefix))
+		assert.Equal(t, "items:read items:write", stored.Scopes)
+	})
+}
+
+func TestAuthenticateApiKey(t *testing.T) {
+	ctx := context.Background()
+	mockApiKeyRepo := new(mocks.ApiKeyRepositoryInterface)
+	mockUserRepo := new(mocks.UserRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetApiKeysService(mockApiKeyRepo, mockUserRepo, mockLog, mockCfg)
+
+	past := time.Now().UTC().Add(-time.Minute)
+	mockApiKeyRepo.On("GetApiKeyByHash", mock.Anything, hashToken("ak_unknown")).Return(nil, apperrors.NotFoundErr)
+	mockApiKeyRepo.On("GetApiKeyByHash", mock.Anything, hashToken("ak_expired")).
+		Return(&models.ApiKey{Id: 1, UserId: 1, ExpiresAt: &past}, nil)
+	mockApiKeyRepo.On("GetApiKeyByHash", mock.Anything, hashToken("ak_revoked")).
+		Return(&models.ApiKey{Id: 2, UserId: 1, RevokedAt: &past}, nil)
+	mockApiKeyRepo.On("GetApiKeyByHash", mock.Anything, hashToken("ak_valid")).
+		Return(&models.ApiKey{Id: 3, UserId: 1, Scopes: models.ScopeItemsRead}, nil)
+	mockApiKeyRepo.On("TouchApiKey", mock.Anything, 3).Return(nil)
+	mockUserRepo.On("GetUserById", mock.Anything, 1).Return(&models.User{Id: 1})
+
+	for _, key := range []string{"not-a-key", "ak_unknown", "ak_expired", "ak_revoked"} {
+		t.Run(key, func(t *testing.T) {
+			user, apiKey, err := service.Authenticate(ctx, key)
+			assert.Nil(t, user)
+			assert.Nil(t, apiKey)
+			assert.ErrorIs(t, err, apperrors.InvalidApiKeyErr)
+		})
+	}
+
+	t.Run("Valid key", func(t *testing.T) {
+		user, apiKey, err := service.Authenticate(ctx, "ak_valid")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, user.Id)
+		assert.True(t, apiKey.HasScope(models.ScopeItemsRead))
+		assert.False(t, apiKey.HasScope(models.ScopeItemsWrite))
+		mockApiKeyRepo.AssertCalled(t, "TouchApiKey", mock.Anything, 3)
+	})
+}
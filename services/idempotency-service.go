@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// IdempotencyLockTimeout is how long a request holds its key unless it extends it. If the instance handling
+// it dies a retry can take the key over after this time instead of waiting for the whole window.
+const IdempotencyLockTimeout = time.Minute
+
+// completeAttempts is how many times Complete tries to store a response. A response that isn't stored
+// would let a retry handle the request again once the lock times out.
+const completeAttempts = 3
+
+type IdempotencyService struct {
+	log             *log.Logger
+	cfg             *config.Config
+	idempotencyRepo repositories.IdempotencyRepositoryInterface
+	retryDelay      time.Duration
+}
+
+type IdempotencyServiceInterface interface {
+	Begin(ctx context.Context, userId int, key string, requestHash string) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, reservation *models.IdempotencyKey, status int, contentType string,
+		body []byte) error
+	Extend(ctx context.Context, reservation *models.IdempotencyKey) error
+	Release(ctx context.Context, reservation *models.IdempotencyKey) error
+}
+
+func GetIdempotencyService(idempotencyRepo repositories.IdempotencyRepositoryInterface, log *log.Logger,
+	cfg *config.Config) IdempotencyServiceInterface {
+
+	return &IdempotencyService{
+		log:             log,
+		cfg:             cfg,
+		idempotencyRepo: idempotencyRepo,
+		retryDelay:      100 * time.Millisecond,
+	}
+}
+
+// Begin reserves the key of the user for the request. It returns the reservation, which has no status
+// and holds the lock token of the request, if the request has to be handled and the stored response
+// if the request was handled already. A key used for another request is rejected with IdempotencyKeyReusedErr,
+// a key of a request still in progress with IdempotencyKeyInProgressErr.
+func (is *IdempotencyService) Begin(ctx context.Context, userId int, key string,
+	requestHash string) (*models.IdempotencyKey, error) {
+	lockToken, err := generateToken(16)
+	if err != nil {
+		is.log.Errorln("failed to generate idempotency lock token", err)
+
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	reservation := &models.IdempotencyKey{
+		UserId:      userId,
+		Key:         key,
+		RequestHash: requestHash,
+		LockToken:   lockToken,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(IdempotencyLockTimeout),
+	}
+	reserved, err := is.idempotencyRepo.ReserveIdempotencyKey(ctx, reservation)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return reservation, nil
+	}
+
+	existing, err := is.idempotencyRepo.GetIdempotencyKey(ctx, userId, key)
+	if err != nil {
+		// released by the request in progress in the meantime, the client can retry
+		if goErrors.Is(err, errors.NotFoundErr) {
+			return nil, errors.IdempotencyKeyInProgressErr
+		}
+
+		return nil, err
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, errors.IdempotencyKeyReusedErr
+	}
+	if existing.Status == nil {
+		return nil, errors.IdempotencyKeyInProgressErr
+	}
+
+	return existing, nil
+}
+
+// Complete stores the response of the request, retries within the window get it instead of being handled.
+// It returns NotFoundErr without trying again if a retry has taken the key over.
+func (is *IdempotencyService) Complete(ctx context.Context, reservation *models.IdempotencyKey, status int,
+	contentType string, body []byte) error {
+	window := time.Duration(is.cfg.IdempotencyWindowMinutes) * time.Minute
+
+	completed := *reservation
+	completed.Status = &status
+	completed.ContentType = &contentType
+	completed.ResponseBody = body
+	completed.ExpiresAt = time.Now().UTC().Add(window)
+
+	var err error
+	for attempt := 1; attempt <= completeAttempts; attempt++ {
+		err = is.idempotencyRepo.CompleteIdempotencyKey(ctx, &completed)
+		if err == nil || goErrors.Is(err, errors.NotFoundErr) {
+			return err
+		}
+		is.log.Warnf("attempt %d to store the response of idempotency key failed: %v", attempt, err)
+		if attempt < completeAttempts {
+			time.Sleep(time.Duration(attempt) * is.retryDelay)
+		}
+	}
+
+	return err
+}
+
+// Extend keeps the key of a request that runs longer than the lock timeout, so a retry doesn't take it over
+func (is *IdempotencyService) Extend(ctx context.Context, reservation *models.IdempotencyKey) error {
+	extended := *reservation
+	extended.ExpiresAt = time.Now().UTC().Add(IdempotencyLockTimeout)
+
+	return is.idempotencyRepo.ExtendIdempotencyKey(ctx, &extended)
+}
+
+// Release forgets the key of a request that failed, so a retry is handled again. A key taken over
+// by a retry is left alone.
+func (is *IdempotencyService) Release(ctx context.Context, reservation *models.IdempotencyKey) error {
+	return is.idempotencyRepo.DeleteIdempotencyKey(ctx, reservation)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestIdempotencyBegin(t *testing.T) {
+	ctx := context.Background()
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+
+	status := 201
+	contentType := "application/json"
+	completed := &models.IdempotencyKey{UserId: 1, Key: "key", RequestHash: "hash", Status: &status,
+		ContentType: &contentType, ResponseBody: []byte(`{"id":1}`)}
+
+	tests := []struct {
+		name        string
+		reserved    bool
+		existing    *models.IdempotencyKey
+		existingErr error
+		requestHash string
+		expectKey   *models.IdempotencyKey
+		expectErr   error
+	}{
+		{
+			name:        "New key",
+			reserved:    true,
+			requestHash: "hash",
+		},
+		{
+			name:        "Completed key is replayed",
+			existing:    completed,
+			requestHash: "hash",
+			expectKey:   completed,
+		},
+		{
+			name:        "Key used for another request",
+			existing:    completed,
+			requestHash: "other",
+			expectErr:   apperrors.IdempotencyKeyReusedErr,
+		},
+		{
+			name:        "Key in progress",
+			existing:    &models.IdempotencyKey{UserId: 1, Key: "key", RequestHash: "hash"},
+			requestHash: "hash",
+			expectErr:   apperrors.IdempotencyKeyInProgressErr,
+		},
+		{
+			name:        "Key released in the meantime",
+			existingErr: apperrors.NotFoundErr,
+			requestHash: "hash",
+			expectErr:   apperrors.IdempotencyKeyInProgressErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.IdempotencyRepositoryInterface)
+			mockRepo.On("ReserveIdempotencyKey", mock.Anything, mock.MatchedBy(func(k *models.IdempotencyKey) bool {
+				return k.UserId == 1 && k.Key == "key" && k.RequestHash == tt.requestHash && k.Status == nil &&
+					len(k.LockToken) == 32
+			})).Return(tt.reserved, nil)
+			if !tt.reserved {
+				mockRepo.On("GetIdempotencyKey", mock.Anything, 1, "key").Return(tt.existing, tt.existingErr)
+			}
+
+			service := GetIdempotencyService(mockRepo, mockLog, mockCfg)
+
+			key, err := service.Begin(ctx, 1, "key", tt.requestHash)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.reserved {
+				assert.Nil(t, key.Status)
+				assert.Len(t, key.LockToken, 32)
+			} else {
+				assert.Equal(t, tt.expectKey, key)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIdempotencyComplete(t *testing.T) {
+	ctx := context.Background()
+	mockCfg, _ := config.NewConfig()
+	mockCfg.IdempotencyWindowMinutes = 60
+	mockLog := log.New(mockCfg)
+	reservation := &models.IdempotencyKey{UserId: 1, Key: "key", RequestHash: "hash", LockToken: "token"}
+
+	dbErr := goErrors.New("db down")
+	tests := []struct {
+		name      string
+		results   []error
+		expectErr error
+	}{
+		{"Stored", []error{nil}, nil},
+		{"Stored after a failure", []error{dbErr, nil}, nil},
+		{"Key taken over", []error{apperrors.NotFoundErr}, apperrors.NotFoundErr},
+		{"Gives up", []error{dbErr, dbErr, dbErr}, dbErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.IdempotencyRepositoryInterface)
+			completed := mock.MatchedBy(func(k *models.IdempotencyKey) bool {
+				expiresIn := time.Until(k.ExpiresAt)
+
+				return k.UserId == 1 && k.Key == "key" && k.LockToken == "token" && *k.Status == 201 &&
+					*k.ContentType == "application/json" && string(k.ResponseBody) == `{"id":1}` &&
+					expiresIn > 59*time.Minute && expiresIn <= time.Hour
+			})
+			for _, result := range tt.results {
+				mockRepo.On("CompleteIdempotencyKey", mock.Anything, completed).Return(result).Once()
+			}
+
+			service := &IdempotencyService{log: mockLog, cfg: mockCfg, idempotencyRepo: mockRepo}
+
+			err := service.Complete(ctx, reservation, 201, "application/json", []byte(`{"id":1}`))
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+			assert.Nil(t, reservation.Status)
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"context"
+	goErrors "errors"
 	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
 	"ypeskov/go_hillel_9/internal/log"
 	"ypeskov/go_hillel_9/repository/models"
 	"ypeskov/go_hillel_9/repository/repositories"
@@ -12,20 +15,24 @@ type ItemService struct {
 	cfg          *config.Config
 	itemRepo     repositories.ItemRepositoryInterface
 	userTypeRepo repositories.UserTypeRepositoryInterface
+	userRepo     repositories.UserRepositoryInterface
 }
 
 type ItemsServiceInterface interface {
-	GetItemsList(userId int) ([]*models.Item, error)
-	CreateItem(srcItem *models.Item, user *models.User) (*models.Item, error)
-	GetItemById(id int, userId int) (*models.Item, error)
-	UpdateItem(id int, srcItem *models.Item, userId int) (*models.Item, error)
-	DeleteItem(id int, userid int) error
-	GetAllItems() ([]*models.Item, error)
-	CreateItemComment(comment *models.ItemComment) (*models.ItemComment, error)
+	GetItemsList(ctx context.Context, userId int) ([]*models.Item, error)
+	CreateItem(ctx context.Context, srcItem *models.Item, user *models.User) (*models.Item, error)
+	GetItemById(ctx context.Context, id int, userId int) (*models.Item, error)
+	UpdateItem(ctx context.Context, id int, srcItem *models.Item, userId int) (*models.Item, error)
+	DeleteItem(ctx context.Context, id int, userid int) error
+	GetAllItems(ctx context.Context) ([]*models.Item, error)
+	CreateItemComment(ctx context.Context, comment *models.ItemComment) (*models.ItemComment, error)
+	MarkItemSold(ctx context.Context, id int, sellerId int, buyerId int, soldPrice float64) (*models.Item, error)
+	ConfirmItemSale(ctx context.Context, id int, buyerId int) (*models.Item, error)
+	DeclineItemSale(ctx context.Context, id int, buyerId int) error
 }
 
 func GetItemService(itemRepo repositories.ItemRepositoryInterface,
-	userTypeRepo repositories.UserTypeRepositoryInterface,
+	userTypeRepo repositories.UserTypeRepositoryInterface, userRepo repositories.UserRepositoryInterface,
 	log *log.Logger, cfg *config.Config) ItemsServiceInterface {
 
 	return &ItemService{
@@ -33,48 +40,55 @@ func GetItemService(itemRepo repositories.ItemRepositoryInterface,
 		cfg:          cfg,
 		itemRepo:     itemRepo,
 		userTypeRepo: userTypeRepo,
+		userRepo:     userRepo,
 	}
 }
 
-func (is *ItemService) GetItemsList(userId int) ([]*models.Item, error) {
-	return is.itemRepo.GetItemsList(userId)
+func (is *ItemService) GetItemsList(ctx context.Context, userId int) ([]*models.Item, error) {
+	return is.itemRepo.GetItemsList(ctx, models.ItemsFilter{UserId: userId})
 }
 
-func (is *ItemService) GetAllItems() ([]*models.Item, error) {
-	return is.itemRepo.GetAllItems()
+func (is *ItemService) GetAllItems(ctx context.Context) ([]*models.Item, error) {
+	return is.itemRepo.GetAllItems(ctx)
 }
 
-func (is *ItemService) CreateItem(srcItem *models.Item, user *models.User) (*models.Item, error) {
-	userTypes, err := is.userTypeRepo.GetUserTypesList()
+func (is *ItemService) CreateItem(ctx context.Context, srcItem *models.Item, user *models.User) (*models.Item, error) {
+	if !user.EmailVerified {
+		is.log.Errorf("User email is not verified: %d\n", user.Id)
+
+		return nil, errors.EmailNotVerifiedErr
+	}
+
+	userTypes, err := is.userTypeRepo.GetUserTypesList(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	if !canUserAddItem(user, userTypes) {
 		is.log.Errorf("User type is not SELLER: %+v\n", srcItem)
-		return nil, IncorrectUserRoleErr
+		return nil, errors.IncorrectUserRoleErr
 
 	}
 
-	return is.itemRepo.CreateItem(srcItem)
+	return is.itemRepo.CreateItem(ctx, srcItem)
 }
 
-func (is *ItemService) GetItemById(id int, userId int) (*models.Item, error) {
-	return is.itemRepo.GetItemById(id, userId)
+func (is *ItemService) GetItemById(ctx context.Context, id int, userId int) (*models.Item, error) {
+	return is.itemRepo.GetItemById(ctx, id, userId)
 }
 
-func (is *ItemService) UpdateItem(id int, srcItem *models.Item, userId int) (*models.Item, error) {
-	return is.itemRepo.UpdateItem(id, srcItem, userId)
+func (is *ItemService) UpdateItem(ctx context.Context, id int, srcItem *models.Item, userId int) (*models.Item, error) {
+	return is.itemRepo.UpdateItem(ctx, id, srcItem, userId)
 }
 
-func (is *ItemService) DeleteItem(id int, userId int) error {
-	return is.itemRepo.DeleteItem(id, userId)
+func (is *ItemService) DeleteItem(ctx context.Context, id int, userId int) error {
+	return is.itemRepo.DeleteItem(ctx, id, userId)
 }
 
 func canUserAddItem(user *models.User, userTypes []*models.UserType) bool {
 	var sellerTypeId int32
 	for _, userType := range userTypes {
-		if userType.TypeCode == "SELLER" {
+		if userType.TypeCode == models.SellerTypeCode {
 			sellerTypeId = int32(userType.Id)
 		}
 	}
@@ -82,6 +96,62 @@ func canUserAddItem(user *models.User, userTypes []*models.UserType) bool {
 	return user.UserTypeId == sellerTypeId
 }
 
-func (is *ItemService) CreateItemComment(comment *models.ItemComment) (*models.ItemComment, error) {
-	return is.itemRepo.CreateItemComment(comment)
+func (is *ItemService) CreateItemComment(ctx context.Context,
+	comment *models.ItemComment) (*models.ItemComment, error) {
+	return is.itemRepo.CreateItemComment(ctx, comment)
+}
+
+// MarkItemSold records who bought the item of the seller and for how much.
+// The sale stays pending until the buyer confirms it, only then they can review each other.
+func (is *ItemService) MarkItemSold(ctx context.Context, id int, sellerId int, buyerId int,
+	soldPrice float64) (*models.Item, error) {
+	item, err := is.itemRepo.GetItemById(ctx, id, sellerId)
+	if err != nil {
+		return nil, errors.NotFoundErr
+	}
+	if item.SoldPrice != nil {
+		return nil, errors.ItemAlreadySoldErr
+	}
+
+	if buyerId == sellerId || is.userRepo.GetUserById(ctx, buyerId) == nil {
+		return nil, errors.InvalidBuyerErr
+	}
+
+	soldItem, err := is.itemRepo.MarkItemSold(ctx, id, sellerId, buyerId, soldPrice)
+	if err != nil {
+		// another request has sold it in the meantime
+		if goErrors.Is(err, errors.NotFoundErr) {
+			return nil, errors.ItemAlreadySoldErr
+		}
+
+		return nil, err
+	}
+
+	is.log.Infof("Item %d sold to user %d for %.2f, waiting for the buyer", id, buyerId, soldPrice)
+
+	return soldItem, nil
+}
+
+// ConfirmItemSale lets the buyer confirm the pending sale, so a seller can't make up sales to get reviews
+func (is *ItemService) ConfirmItemSale(ctx context.Context, id int, buyerId int) (*models.Item, error) {
+	item, err := is.itemRepo.ConfirmItemSale(ctx, id, buyerId)
+	if err != nil {
+		return nil, err
+	}
+
+	is.log.Infof("Sale of item %d confirmed by user %d", id, buyerId)
+
+	return item, nil
+}
+
+// DeclineItemSale lets the buyer reject the pending sale, the item is for sale again
+func (is *ItemService) DeclineItemSale(ctx context.Context, id int, buyerId int) error {
+	err := is.itemRepo.DeclineItemSale(ctx, id, buyerId)
+	if err != nil {
+		return err
+	}
+
+	is.log.Infof("Sale of item %d declined by user %d", id, buyerId)
+
+	return nil
 }
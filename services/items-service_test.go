@@ -1,22 +1,27 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"testing"
 	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
 	"ypeskov/go_hillel_9/internal/log"
 	"ypeskov/go_hillel_9/repository/models"
 	"ypeskov/go_hillel_9/repository/repositories/mocks"
 )
 
 func TestGetItemsList(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(mocks.ItemRepositoryInterface)
 	mockCfg, _ := config.NewConfig()
 	mockLog := log.New(mockCfg)
 
-	service := GetItemService(mockRepo, mockLog, mockCfg)
+	service := GetItemService(mockRepo, new(mocks.UserTypeRepositoryInterface), new(mocks.UserRepositoryInterface),
+		mockLog, mockCfg)
 
 	userId := 1
 	expectedItems := []*models.Item{
@@ -36,20 +41,28 @@ func TestGetItemsList(t *testing.T) {
 			Description:  nil},
 	}
 
-	mockRepo.On("GetItemsList", userId).Return(expectedItems, nil)
+	mockRepo.On("GetItemsList", mock.Anything, models.ItemsFilter{UserId: userId}).Return(expectedItems, nil)
 
-	items, err := service.GetItemsList(userId)
+	items, err := service.GetItemsList(ctx, userId)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedItems, items)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestCreateItem(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(mocks.ItemRepositoryInterface)
+	mockUserTypeRepo := new(mocks.UserTypeRepositoryInterface)
 	mockCfg, _ := config.NewConfig()
 	mockLog := log.New(mockCfg)
 
-	service := GetItemService(mockRepo, mockLog, mockCfg)
+	service := GetItemService(mockRepo, mockUserTypeRepo, new(mocks.UserRepositoryInterface), mockLog, mockCfg)
+
+	userTypes := []*models.UserType{
+		{Id: 1, TypeCode: "SELLER"},
+		{Id: 2, TypeCode: "BUYER"},
+	}
+	seller := &models.User{Id: 1, UserTypeId: 1, EmailVerified: true}
 
 	srcItem := &models.Item{
 		UserId:       1,
@@ -65,21 +78,40 @@ func TestCreateItem(t *testing.T) {
 		SoldPrice:    nil,
 		Description:  nil}
 
-	mockRepo.On("CreateItem", srcItem).Return(expectedItem, nil)
+	mockUserTypeRepo.On("GetUserTypesList", mock.Anything, mock.Anything).Return(userTypes, nil)
+	mockRepo.On("CreateItem", mock.Anything, srcItem).Return(expectedItem, nil)
 
-	item, err := service.CreateItem(srcItem)
+	item, err := service.CreateItem(ctx, srcItem, seller)
 	fmt.Printf("%+v\n", item)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedItem, item)
 	mockRepo.AssertExpectations(t)
+
+	t.Run("Email not verified", func(t *testing.T) {
+		unverified := &models.User{Id: 1, UserTypeId: 1, EmailVerified: false}
+
+		item, err := service.CreateItem(ctx, srcItem, unverified)
+		assert.Nil(t, item)
+		assert.ErrorIs(t, err, apperrors.EmailNotVerifiedErr)
+	})
+
+	t.Run("User is not a seller", func(t *testing.T) {
+		buyer := &models.User{Id: 2, UserTypeId: 2, EmailVerified: true}
+
+		item, err := service.CreateItem(ctx, srcItem, buyer)
+		assert.Nil(t, item)
+		assert.ErrorIs(t, err, apperrors.IncorrectUserRoleErr)
+	})
 }
 
 func TestGetItemById(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(mocks.ItemRepositoryInterface)
 	mockCfg, _ := config.NewConfig()
 	mockLog := log.New(mockCfg)
 
-	service := GetItemService(mockRepo, mockLog, mockCfg)
+	service := GetItemService(mockRepo, new(mocks.UserTypeRepositoryInterface), new(mocks.UserRepositoryInterface),
+		mockLog, mockCfg)
 
 	tests := []struct {
 		name         string
@@ -103,7 +135,7 @@ func TestGetItemById(t *testing.T) {
 			},
 			expectedErr: nil,
 			mockReturn: func() {
-				mockRepo.On("GetItemById", 1, 1).Return(&models.Item{
+				mockRepo.On("GetItemById", mock.Anything, 1, 1).Return(&models.Item{
 					Id:           1,
 					UserId:       1,
 					Title:        "Test Item",
@@ -120,7 +152,7 @@ func TestGetItemById(t *testing.T) {
 			expectedItem: nil,
 			expectedErr:  errors.New("item not found"),
 			mockReturn: func() {
-				mockRepo.On("GetItemById", 2, 1).Return(nil, errors.New("item not found"))
+				mockRepo.On("GetItemById", mock.Anything, 2, 1).Return(nil, errors.New("item not found"))
 			},
 		},
 		{
@@ -130,7 +162,7 @@ func TestGetItemById(t *testing.T) {
 			expectedItem: nil,
 			expectedErr:  errors.New("database error"),
 			mockReturn: func() {
-				mockRepo.On("GetItemById", 1, 2).Return(nil, errors.New("database error"))
+				mockRepo.On("GetItemById", mock.Anything, 1, 2).Return(nil, errors.New("database error"))
 			},
 		},
 	}
@@ -139,7 +171,7 @@ func TestGetItemById(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockReturn()
 
-			item, err := service.GetItemById(tt.itemID, tt.userID)
+			item, err := service.GetItemById(ctx, tt.itemID, tt.userID)
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectedErr, err)
@@ -153,11 +185,13 @@ func TestGetItemById(t *testing.T) {
 }
 
 func TestUpdateItem(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(mocks.ItemRepositoryInterface)
 	mockCfg, _ := config.NewConfig()
 	mockLog := log.New(mockCfg)
 
-	service := GetItemService(mockRepo, mockLog, mockCfg)
+	service := GetItemService(mockRepo, new(mocks.UserTypeRepositoryInterface), new(mocks.UserRepositoryInterface),
+		mockLog, mockCfg)
 
 	itemID := 1
 	userID := 1
@@ -178,27 +212,74 @@ func TestUpdateItem(t *testing.T) {
 		Description:  nil,
 	}
 
-	mockRepo.On("UpdateItem", itemID, srcItem, userID).Return(expectedItem, nil)
+	mockRepo.On("UpdateItem", mock.Anything, itemID, srcItem, userID).Return(expectedItem, nil)
 
-	item, err := service.UpdateItem(itemID, srcItem, userID)
+	item, err := service.UpdateItem(ctx, itemID, srcItem, userID)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedItem, item)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestDeleteItem(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(mocks.ItemRepositoryInterface)
 	mockCfg, _ := config.NewConfig()
 	mockLog := log.New(mockCfg)
 
-	service := GetItemService(mockRepo, mockLog, mockCfg)
+	service := GetItemService(mockRepo, new(mocks.UserTypeRepositoryInterface), new(mocks.UserRepositoryInterface),
+		mockLog, mockCfg)
 
 	itemID := 1
 	userID := 1
 
-	mockRepo.On("DeleteItem", itemID, userID).Return(nil)
+	mockRepo.On("DeleteItem", mock.Anything, itemID, userID).Return(nil)
 
-	err := service.DeleteItem(itemID, userID)
+	err := service.DeleteItem(ctx, itemID, userID)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestMarkItemSold(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.ItemRepositoryInterface)
+	mockUserRepo := new(mocks.UserRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+
+	service := GetItemService(mockRepo, new(mocks.UserTypeRepositoryInterface), mockUserRepo, mockLog, mockCfg)
+
+	price := 80.0
+	mockRepo.On("GetItemById", mock.Anything, 1, 1).Return(&models.Item{Id: 1, UserId: 1}, nil)
+	mockRepo.On("GetItemById", mock.Anything, 2, 1).Return(&models.Item{Id: 2, UserId: 1, SoldPrice: &price}, nil)
+	mockRepo.On("GetItemById", mock.Anything, 3, 1).Return(nil, errors.New("sql: no rows in result set"))
+	mockUserRepo.On("GetUserById", mock.Anything, 2).Return(&models.User{Id: 2})
+	mockUserRepo.On("GetUserById", mock.Anything, 9).Return(nil)
+	mockRepo.On("MarkItemSold", mock.Anything, 1, 1, 2, price).
+		Return(&models.Item{Id: 1, UserId: 1, SoldPrice: &price}, nil)
+
+	tests := []struct {
+		name        string
+		itemId      int
+		buyerId     int
+		expectedErr error
+	}{
+		{"Not an item of the seller", 3, 2, apperrors.NotFoundErr},
+		{"Already sold", 2, 2, apperrors.ItemAlreadySoldErr},
+		{"Seller buys from themselves", 1, 1, apperrors.InvalidBuyerErr},
+		{"Unknown buyer", 1, 9, apperrors.InvalidBuyerErr},
+		{"Sold", 1, 2, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := service.MarkItemSold(ctx, tt.itemId, 1, tt.buyerId, price)
+			if tt.expectedErr != nil {
+				assert.Nil(t, item)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, price, *item.SoldPrice)
+			}
+		})
+	}
+}
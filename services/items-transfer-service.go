@@ -0,0 +1,410 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	goErrors "errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+const (
+	FormatCsv    = "csv"
+	FormatNdjson = "ndjson"
+)
+
+const (
+	// importBatchSize keeps the inserts below the limit of query parameters of SQLite
+	importBatchSize = 100
+	// exportFlushRows is how often an export is flushed to the client
+	exportFlushRows = 100
+	// maxNdjsonLine is the longest line of an NDJSON import
+	maxNdjsonLine = 1024 * 1024
+)
+
+type ItemsTransferService struct {
+	log          *log.Logger
+	cfg          *config.Config
+	itemRepo     repositories.ItemRepositoryInterface
+	userTypeRepo repositories.UserTypeRepositoryInterface
+	unitOfWork   repositories.UnitOfWorkInterface
+}
+
+// ItemsImportReport lists the rows that failed, Row is the number of the item in the file starting from 1.
+// Imported is 0 in a dry run, Valid tells how many items would be imported.
+type ItemsImportReport struct {
+	DryRun   bool               `json:"dryRun"`
+	Rows     int                `json:"rows"`
+	Valid    int                `json:"valid"`
+	Imported int                `json:"imported"`
+	Errors   []ItemsImportError `json:"errors"`
+}
+
+// ItemsImportError holds the error the row would get if the item was created on its own
+type ItemsImportError struct {
+	Row   int   `json:"row"`
+	Error error `json:"error"`
+}
+
+type ItemsTransferServiceInterface interface {
+	ImportItems(ctx context.Context, user *models.User, format string, r io.Reader,
+		dryRun bool) (*ItemsImportReport, error)
+	ExportItems(ctx context.Context, userId int, format string, w io.Writer) error
+	ExportSales(ctx context.Context, sellerId int, format string, w io.Writer) error
+}
+
+func GetItemsTransferService(itemRepo repositories.ItemRepositoryInterface,
+	userTypeRepo repositories.UserTypeRepositoryInterface, unitOfWork repositories.UnitOfWorkInterface,
+	log *log.Logger, cfg *config.Config) ItemsTransferServiceInterface {
+
+	return &ItemsTransferService{
+		log:          log,
+		cfg:          cfg,
+		itemRepo:     itemRepo,
+		userTypeRepo: userTypeRepo,
+		unitOfWork:   unitOfWork,
+	}
+}
+
+// ImportItems creates the valid items of the file for the user, the invalid rows are reported and skipped.
+// The valid items are inserted in batches within one transaction, so a failure of the database imports none.
+func (its *ItemsTransferService) ImportItems(ctx context.Context, user *models.User, format string, r io.Reader,
+	dryRun bool) (*ItemsImportReport, error) {
+	if !user.EmailVerified {
+		return nil, errors.EmailNotVerifiedErr
+	}
+
+	userTypes, err := its.userTypeRepo.GetUserTypesList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !canUserAddItem(user, userTypes) {
+		return nil, errors.IncorrectUserRoleErr
+	}
+
+	rows, err := readImportRows(format, r, its.cfg.ItemsImportMaxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ItemsImportReport{DryRun: dryRun, Rows: len(rows), Errors: []ItemsImportError{}}
+	valid := make([]*models.Item, 0, len(rows))
+	for i, row := range rows {
+		if row.err == nil {
+			row.item.UserId = user.Id
+			row.err = row.item.Validate()
+		}
+		if row.err != nil {
+			report.Errors = append(report.Errors, ItemsImportError{Row: i + 1, Error: row.err})
+
+			continue
+		}
+		valid = append(valid, row.item)
+	}
+	report.Valid = len(valid)
+
+	if dryRun || len(valid) == 0 {
+		return report, nil
+	}
+
+	err = its.unitOfWork.InTransaction(ctx, func(ctx context.Context) error {
+		for start := 0; start < len(valid); start += importBatchSize {
+			_, err := its.itemRepo.CreateItems(ctx, valid[start:min(start+importBatchSize, len(valid))])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Imported = len(valid)
+
+	its.log.Infof("User %d imported %d items, %d rows failed", user.Id, report.Imported, len(report.Errors))
+
+	return report, nil
+}
+
+type importRow struct {
+	item *models.Item
+	err  error
+}
+
+// readImportRows parses every row of the file. A row that can't be parsed gets an error,
+// the file fails as a whole only if it is unreadable or has more than maxRows rows.
+func readImportRows(format string, r io.Reader, maxRows int) ([]importRow, error) {
+	switch format {
+	case FormatCsv:
+		return readCsvRows(r, maxRows)
+	case FormatNdjson:
+		return readNdjsonRows(r, maxRows)
+	default:
+		return nil, errors.UnsupportedFormatErr
+	}
+}
+
+// readCsvRows reads a file with a header row naming the columns title, initial_price and description,
+// other columns are ignored
+func readCsvRows(r io.Reader, maxRows int) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.InvalidImportFileErr.WithMessage("The file has no header row")
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.InvalidImportFileErr.WithMessage("The file has no title column")
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if goErrors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if len(rows) == maxRows {
+			return nil, errors.ImportTooLargeErr
+		}
+
+		var parseErr *csv.ParseError
+		if goErrors.As(err, &parseErr) {
+			rows = append(rows, importRow{err: errors.InvalidImportRowErr.WithMessage(parseErr.Err.Error())})
+
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, csvRow(columns, record))
+	}
+}
+
+func csvRow(columns map[string]int, record []string) importRow {
+	value := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	item := &models.Item{Title: value("title")}
+	if price := value("initial_price"); price != "" {
+		initialPrice, err := strconv.ParseFloat(price, 64)
+		if err != nil {
+			return importRow{err: errors.InvalidImportRowErr.WithMessage(
+				fmt.Sprintf("initial_price %q is not a number", price))}
+		}
+		item.InitialPrice = initialPrice
+	}
+	if description := value("description"); description != "" {
+		item.Description = &description
+	}
+
+	return importRow{item: item}
+}
+
+// readNdjsonRows reads one JSON object per line with the fields of the item in the API, blank lines are skipped
+func readNdjsonRows(r io.Reader, maxRows int) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNdjsonLine)
+
+	var rows []importRow
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if len(rows) == maxRows {
+			return nil, errors.ImportTooLargeErr
+		}
+
+		var item struct {
+			Title        string  `json:"title"`
+			InitialPrice float64 `json:"initialPrice"`
+			Description  *string `json:"description"`
+		}
+		err := json.Unmarshal([]byte(line), &item)
+		if err != nil {
+			rows = append(rows, importRow{err: errors.InvalidImportRowErr.WithMessage(err.Error())})
+
+			continue
+		}
+
+		rows = append(rows, importRow{item: &models.Item{
+			Title:        item.Title,
+			InitialPrice: item.InitialPrice,
+			Description:  item.Description,
+		}})
+	}
+
+	if goErrors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return nil, errors.InvalidImportFileErr.WithMessage("A line of the file is too long")
+	}
+
+	return rows, scanner.Err()
+}
+
+// ExportItems writes all items of the user as they are read from the database. Once the first rows
+// are written the response can't become an error anymore, a failure leaves the export cut short.
+func (its *ItemsTransferService) ExportItems(ctx context.Context, userId int, format string, w io.Writer) error {
+	writer, err := newExportWriter(format, w,
+		[]string{"id", "title", "initial_price", "description", "sold_price"})
+	if err != nil {
+		return err
+	}
+
+	err = its.itemRepo.StreamItems(ctx, models.ItemsFilter{UserId: userId}, func(item *models.Item) error {
+		return writer.write([]string{
+			strconv.Itoa(item.Id),
+			item.Title,
+			formatPrice(&item.InitialPrice),
+			stringValue(item.Description),
+			formatPrice(item.SoldPrice),
+		}, struct {
+			Id           int      `json:"id"`
+			Title        string   `json:"title"`
+			InitialPrice float64  `json:"initialPrice"`
+			Description  *string  `json:"description"`
+			SoldPrice    *float64 `json:"soldPrice"`
+		}{item.Id, item.Title, item.InitialPrice, item.Description, item.SoldPrice})
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.flush()
+}
+
+// ExportSales writes the sold items of the seller with their buyers and sold prices
+func (its *ItemsTransferService) ExportSales(ctx context.Context, sellerId int, format string, w io.Writer) error {
+	writer, err := newExportWriter(format, w,
+		[]string{"id", "title", "initial_price", "sold_price", "buyer_id", "sold_at"})
+	if err != nil {
+		return err
+	}
+
+	filter := models.ItemsFilter{UserId: sellerId, SoldOnly: true}
+	err = its.itemRepo.StreamItems(ctx, filter, func(item *models.Item) error {
+		buyerId, soldAt := "", ""
+		if item.BuyerId != nil {
+			buyerId = strconv.Itoa(*item.BuyerId)
+		}
+		if item.SoldAt != nil {
+			soldAt = item.SoldAt.UTC().Format(time.RFC3339)
+		}
+
+		return writer.write([]string{
+			strconv.Itoa(item.Id),
+			item.Title,
+			formatPrice(&item.InitialPrice),
+			formatPrice(item.SoldPrice),
+			buyerId,
+			soldAt,
+		}, struct {
+			Id           int        `json:"id"`
+			Title        string     `json:"title"`
+			InitialPrice float64    `json:"initialPrice"`
+			SoldPrice    *float64   `json:"soldPrice"`
+			BuyerId      *int       `json:"buyerId"`
+			SoldAt       *time.Time `json:"soldAt"`
+		}{item.Id, item.Title, item.InitialPrice, item.SoldPrice, item.BuyerId, item.SoldAt})
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.flush()
+}
+
+// exportWriter writes rows as CSV under a header row or as one JSON object per line
+type exportWriter struct {
+	w    io.Writer
+	csv  *csv.Writer
+	json *json.Encoder
+	rows int
+}
+
+func newExportWriter(format string, w io.Writer, header []string) (*exportWriter, error) {
+	switch format {
+	case FormatCsv:
+		writer := &exportWriter{w: w, csv: csv.NewWriter(w)}
+
+		return writer, writer.csv.Write(header)
+	case FormatNdjson:
+		return &exportWriter{w: w, json: json.NewEncoder(w)}, nil
+	default:
+		return nil, errors.UnsupportedFormatErr
+	}
+}
+
+// write takes the row both ways, record for CSV and object for NDJSON
+func (ew *exportWriter) write(record []string, object any) error {
+	var err error
+	if ew.csv != nil {
+		err = ew.csv.Write(record)
+	} else {
+		err = ew.json.Encode(object)
+	}
+	if err != nil {
+		return err
+	}
+
+	ew.rows++
+	if ew.rows%exportFlushRows == 0 {
+		return ew.flush()
+	}
+
+	return nil
+}
+
+// flush sends the buffered rows to the client if w is a response
+func (ew *exportWriter) flush() error {
+	if ew.csv != nil {
+		ew.csv.Flush()
+		if err := ew.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := ew.w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+func formatPrice(price *float64) string {
+	if price == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*price, 'f', -1, 64)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
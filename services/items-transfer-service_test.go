@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func newItemsTransferService(t *testing.T) (ItemsTransferServiceInterface, *mocks.ItemRepositoryInterface) {
+	mockRepo := new(mocks.ItemRepositoryInterface)
+	mockUserTypeRepo := new(mocks.UserTypeRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockCfg.ItemsImportMaxRows = 300
+	mockLog := log.New(mockCfg)
+
+	mockUserTypeRepo.On("GetUserTypesList", mock.Anything).Return([]*models.UserType{
+		{Id: 1, TypeCode: models.SellerTypeCode},
+		{Id: 2, TypeCode: models.BuyerTypeCode},
+	}, nil)
+
+	return GetItemsTransferService(mockRepo, mockUserTypeRepo, runningUnitOfWork(), mockLog, mockCfg), mockRepo
+}
+
+// assertErrorCode checks errors made with WithMessage, which ErrorIs doesn't match
+func assertErrorCode(t *testing.T, expected apperrors.Error, err error) {
+	var appErr apperrors.Error
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.Equal(t, expected.Code, appErr.Code)
+	}
+}
+
+func TestImportItems(t *testing.T) {
+	ctx := context.Background()
+	seller := &models.User{Id: 7, UserTypeId: 1, EmailVerified: true}
+
+	t.Run("Valid rows are imported and the others reported", func(t *testing.T) {
+		service, mockRepo := newItemsTransferService(t)
+		mockRepo.On("CreateItems", mock.Anything, mock.MatchedBy(func(items []*models.Item) bool {
+			return len(items) == 2 && items[0].Title == "Lamp" && items[0].UserId == 7 &&
+				*items[0].Description == "Brass, 1960s" && items[1].Title == "Sofa" && items[1].Description == nil
+		})).Return([]*models.Item{{Id: 1}, {Id: 2}}, nil)
+
+		file := "Title,Initial_Price,Description,Ignored\n" +
+			"Lamp,10.5,\"Brass, 1960s\",x\n" +
+			",5,No title\n" +
+			"Chair,cheap,\n" +
+			"Desk,-3\n" +
+			"Sofa,20\n"
+		report, err := service.ImportItems(ctx, seller, FormatCsv, strings.NewReader(file), false)
+		require.NoError(t, err)
+		assert.Equal(t, 5, report.Rows)
+		assert.Equal(t, 2, report.Valid)
+		assert.Equal(t, 2, report.Imported)
+		require.Len(t, report.Errors, 3)
+		assert.Equal(t, 2, report.Errors[0].Row)
+		assert.ErrorIs(t, report.Errors[0].Error, apperrors.ValidationFailedErr)
+		assert.Equal(t, 3, report.Errors[1].Row)
+		assertErrorCode(t, apperrors.InvalidImportRowErr, report.Errors[1].Error)
+		assert.Equal(t, 4, report.Errors[2].Row)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Dry run creates nothing", func(t *testing.T) {
+		service, mockRepo := newItemsTransferService(t)
+
+		file := "{\"title\":\"Lamp\",\"initialPrice\":10}\n\n{\"title\":\n{\"initialPrice\":5}\n"
+		report, err := service.ImportItems(ctx, seller, FormatNdjson, strings.NewReader(file), true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 3, report.Rows)
+		assert.Equal(t, 1, report.Valid)
+		assert.Equal(t, 0, report.Imported)
+		require.Len(t, report.Errors, 2)
+		assertErrorCode(t, apperrors.InvalidImportRowErr, report.Errors[0].Error)
+		assert.ErrorIs(t, report.Errors[1].Error, apperrors.ValidationFailedErr)
+		mockRepo.AssertNotCalled(t, "CreateItems", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Items are inserted in batches", func(t *testing.T) {
+		service, mockRepo := newItemsTransferService(t)
+		mockRepo.On("CreateItems", mock.Anything, mock.Anything).Return([]*models.Item{}, nil)
+
+		var file strings.Builder
+		file.WriteString("title,initial_price\n")
+		for i := 0; i < 250; i++ {
+			file.WriteString(fmt.Sprintf("Item %d,%d\n", i, i))
+		}
+		report, err := service.ImportItems(ctx, seller, FormatCsv, strings.NewReader(file.String()), false)
+		require.NoError(t, err)
+		assert.Equal(t, 250, report.Imported)
+		mockRepo.AssertNumberOfCalls(t, "CreateItems", 3)
+		assert.Len(t, mockRepo.Calls[2].Arguments.Get(1), 50)
+	})
+
+	t.Run("Failed batch fails the import", func(t *testing.T) {
+		service, mockRepo := newItemsTransferService(t)
+		mockRepo.On("CreateItems", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("connection lost"))
+
+		_, err := service.ImportItems(ctx, seller, FormatCsv, strings.NewReader("title\nLamp\n"), false)
+		assert.Error(t, err)
+	})
+
+	t.Run("Rejected files", func(t *testing.T) {
+		service, _ := newItemsTransferService(t)
+
+		_, err := service.ImportItems(ctx, seller, "xml", strings.NewReader("<items/>"), false)
+		assert.ErrorIs(t, err, apperrors.UnsupportedFormatErr)
+
+		_, err = service.ImportItems(ctx, seller, FormatCsv, strings.NewReader("name,price\nLamp,1\n"), false)
+		assertErrorCode(t, apperrors.InvalidImportFileErr, err)
+
+		file := "title\n" + strings.Repeat("Lamp\n", 301)
+		_, err = service.ImportItems(ctx, seller, FormatCsv, strings.NewReader(file), false)
+		assert.ErrorIs(t, err, apperrors.ImportTooLargeErr)
+
+		buyer := &models.User{Id: 8, UserTypeId: 2, EmailVerified: true}
+		_, err = service.ImportItems(ctx, buyer, FormatCsv, strings.NewReader("title\nLamp\n"), false)
+		assert.ErrorIs(t, err, apperrors.IncorrectUserRoleErr)
+
+		unverified := &models.User{Id: 9, UserTypeId: 1}
+		_, err = service.ImportItems(ctx, unverified, FormatCsv, strings.NewReader("title\nLamp\n"), false)
+		assert.ErrorIs(t, err, apperrors.EmailNotVerifiedErr)
+	})
+}
+
+func TestExportItems(t *testing.T) {
+	ctx := context.Background()
+	description := "Brass, 1960s"
+	soldPrice := 12.5
+	buyerId := 3
+	soldAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	items := []*models.Item{
+		{Id: 1, UserId: 7, Title: "Lamp", InitialPrice: 10, Description: &description},
+		{Id: 2, UserId: 7, Title: "Sofa", InitialPrice: 10, SoldPrice: &soldPrice, BuyerId: &buyerId, SoldAt: &soldAt},
+	}
+	stream := func(items []*models.Item) func(ctx context.Context, filter models.ItemsFilter,
+		fn func(item *models.Item) error) error {
+		return func(ctx context.Context, filter models.ItemsFilter, fn func(item *models.Item) error) error {
+			for _, item := range items {
+				if err := fn(item); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	t.Run("Items as CSV", func(t *testing.T) {
+		service, mockRepo := newItemsTransferService(t)
+		mockRepo.On("StreamItems", mock.Anything, models.ItemsFilter{UserId: 7}, mock.Anything).Return(stream(items))
+
+		var out bytes.Buffer
+		require.NoError(t, service.ExportItems(ctx, 7, FormatCsv, &out))
+		assert.Equal(t, "id,title,initial_price,description,sold_price\n"+
+			"1,Lamp,10,\"Brass, 1960s\",\n"+
+			"2,Sofa,10,,12.5\n", out.String())
+	})
+
+	t.Run("Sales as NDJSON", func(t *testing.T) {
+		service, mockRepo := newItemsTransferService(t)
+		mockRepo.On("StreamItems", mock.Anything, models.ItemsFilter{UserId: 7, SoldOnly: true}, mock.Anything).
+			Return(stream(items[1:]))
+
+		var out bytes.Buffer
+		require.NoError(t, service.ExportSales(ctx, 7, FormatNdjson, &out))
+		assert.Equal(t, `{"id":2,"title":"Sofa","initialPrice":10,"soldPrice":12.5,"buyerId":3,`+
+			`"soldAt":"2024-05-01T12:00:00Z"}`+"\n", out.String())
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		service, _ := newItemsTransferService(t)
+		assert.ErrorIs(t, service.ExportSales(ctx, 7, "xlsx", &bytes.Buffer{}), apperrors.UnsupportedFormatErr)
+	})
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"strings"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// LoginAttemptsService throttles password guessing. Attempts are tracked by the email
+// that was typed, whether an account exists or not, so throttling doesn't reveal accounts.
+type LoginAttemptsService struct {
+	log              *log.Logger
+	cfg              *config.Config
+	loginAttemptRepo repositories.LoginAttemptRepositoryInterface
+}
+
+type LoginAttemptsServiceInterface interface {
+	CheckAllowed(ctx context.Context, email string, ip string) error
+	RecordFailure(ctx context.Context, email string, ip string) error
+	RecordSuccess(ctx context.Context, email string) error
+	Unlock(ctx context.Context, subjectType string, subject string, adminId int) error
+}
+
+// LoginThrottledError tells the client when it may try to log in again
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s. Retry after %s", errors.TooManyLoginAttemptsErr.Error(), e.RetryAfter)
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return errors.TooManyLoginAttemptsErr
+}
+
+func GetLoginAttemptsService(loginAttemptRepo repositories.LoginAttemptRepositoryInterface,
+	log *log.Logger, cfg *config.Config) LoginAttemptsServiceInterface {
+
+	return &LoginAttemptsService{
+		log:              log,
+		cfg:              cfg,
+		loginAttemptRepo: loginAttemptRepo,
+	}
+}
+
+func (ls *LoginAttemptsService) CheckAllowed(ctx context.Context, email string, ip string) error {
+	now := time.Now().UTC()
+
+	accountFailure, err := ls.getLoginFailure(ctx, models.LoginSubjectAccount, normalizeEmail(email))
+	if err != nil {
+		return err
+	}
+	if wait := ls.waitFor(accountFailure, now, true); wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+
+	ipFailure, err := ls.getLoginFailure(ctx, models.LoginSubjectIp, ip)
+	if err != nil {
+		return err
+	}
+	if wait := ls.waitFor(ipFailure, now, false); wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+func (ls *LoginAttemptsService) RecordFailure(ctx context.Context, email string, ip string) error {
+	err := ls.addFailure(ctx, models.LoginSubjectAccount, normalizeEmail(email), ip, ls.cfg.LoginMaxFailures)
+	if err != nil {
+		return err
+	}
+
+	return ls.addFailure(ctx, models.LoginSubjectIp, ip, ip, ls.cfg.LoginMaxFailuresPerIp)
+}
+
+// RecordSuccess forgets failures of the account. IP failures are kept,
+// otherwise one known password would let an attacker reset the IP limit.
+func (ls *LoginAttemptsService) RecordSuccess(ctx context.Context, email string) error {
+	return ls.loginAttemptRepo.ResetLoginFailures(ctx, models.LoginSubjectAccount, normalizeEmail(email))
+}
+
+func (ls *LoginAttemptsService) Unlock(ctx context.Context, subjectType string, subject string, adminId int) error {
+	if subjectType == models.LoginSubjectAccount {
+		subject = normalizeEmail(subject)
+	}
+
+	err := ls.loginAttemptRepo.ResetLoginFailures(ctx, subjectType, subject)
+	if err != nil {
+		return err
+	}
+
+	ls.log.Infof("Login of %s %s unlocked by admin %d", subjectType, subject, adminId)
+
+	return ls.loginAttemptRepo.AddLockoutEvent(ctx, &models.LoginLockoutEvent{
+		SubjectType: subjectType,
+		Subject:     subject,
+		Event:       models.LoginEventUnlocked,
+		AdminId:     &adminId,
+	})
+}
+
+func (ls *LoginAttemptsService) addFailure(ctx context.Context, subjectType string, subject string, ip string,
+	maxFailures int) error {
+	windowStart := time.Now().UTC().Add(-time.Duration(ls.cfg.LoginFailureWindowMinutes) * time.Minute)
+
+	failure, err := ls.loginAttemptRepo.AddLoginFailure(ctx, subjectType, subject, windowStart)
+	if err != nil {
+		return err
+	}
+
+	if failure.Failures < maxFailures {
+		return nil
+	}
+
+	lockedUntil := time.Now().UTC().Add(time.Duration(ls.cfg.LoginLockoutMinutes) * time.Minute)
+	err = ls.loginAttemptRepo.LockLogin(ctx, subjectType, subject, lockedUntil)
+	if err != nil {
+		return err
+	}
+
+	ls.log.Warnf("Login of %s %s locked until %s after %d failures", subjectType, subject,
+		lockedUntil.Format(time.RFC3339), failure.Failures)
+
+	return ls.loginAttemptRepo.AddLockoutEvent(ctx, &models.LoginLockoutEvent{
+		SubjectType: subjectType,
+		Subject:     subject,
+		Event:       models.LoginEventLocked,
+		Ip:          ip,
+	})
+}
+
+func (ls *LoginAttemptsService) getLoginFailure(ctx context.Context, subjectType string,
+	subject string) (*models.LoginFailure, error) {
+	failure, err := ls.loginAttemptRepo.GetLoginFailure(ctx, subjectType, subject)
+	if err != nil {
+		if goErrors.Is(err, errors.NotFoundErr) {
+			return &models.LoginFailure{SubjectType: subjectType, Subject: subject}, nil
+		}
+
+		return nil, err
+	}
+
+	return failure, nil
+}
+
+// waitFor returns how long the subject has to wait before the next attempt
+func (ls *LoginAttemptsService) waitFor(failure *models.LoginFailure, now time.Time, progressive bool) time.Duration {
+	if failure.LockedUntil != nil && failure.LockedUntil.After(now) {
+		return failure.LockedUntil.Sub(now)
+	}
+
+	if !progressive || failure.Failures == 0 {
+		return 0
+	}
+
+	windowStart := now.Add(-time.Duration(ls.cfg.LoginFailureWindowMinutes) * time.Minute)
+	if failure.LastFailureAt.Before(windowStart) {
+		return 0
+	}
+
+	return max(failure.LastFailureAt.Add(ls.progressiveDelay(failure.Failures)).Sub(now), 0)
+}
+
+func (ls *LoginAttemptsService) progressiveDelay(failures int) time.Duration {
+	maxDelay := time.Duration(ls.cfg.LoginDelayMaxMilliseconds) * time.Millisecond
+	delay := time.Duration(ls.cfg.LoginDelayBaseMilliseconds) * time.Millisecond
+
+	for i := 1; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxDelay)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestCheckAllowed(t *testing.T) {
+	ctx := context.Background()
+	mockCfg, _ := config.NewConfig()
+	mockCfg.LoginDelayBaseMilliseconds = 1000
+	mockCfg.LoginDelayMaxMilliseconds = 8000
+	mockLog := log.New(mockCfg)
+
+	now := time.Now().UTC()
+	lockedUntil := now.Add(10 * time.Minute)
+
+	tests := []struct {
+		name            string
+		accountFailure  *models.LoginFailure
+		ipFailure       *models.LoginFailure
+		expectThrottled bool
+	}{
+		{
+			name:            "No failures",
+			expectThrottled: false,
+		},
+		{
+			name:            "Account locked",
+			accountFailure:  &models.LoginFailure{Failures: 5, LastFailureAt: now, LockedUntil: &lockedUntil},
+			expectThrottled: true,
+		},
+		{
+			name:            "Account must wait after recent failures",
+			accountFailure:  &models.LoginFailure{Failures: 3, LastFailureAt: now.Add(-time.Second)},
+			expectThrottled: true,
+		},
+		{
+			name:            "Account waited long enough",
+			accountFailure:  &models.LoginFailure{Failures: 3, LastFailureAt: now.Add(-5 * time.Second)},
+			expectThrottled: false,
+		},
+		{
+			name:            "IP locked",
+			ipFailure:       &models.LoginFailure{Failures: 50, LastFailureAt: now, LockedUntil: &lockedUntil},
+			expectThrottled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.LoginAttemptRepositoryInterface)
+			returnFailure := func(subjectType string, subject string, failure *models.LoginFailure) {
+				if failure == nil {
+					mockRepo.On("GetLoginFailure", mock.Anything, subjectType, subject).Return(nil,
+						apperrors.NotFoundErr)
+				} else {
+					mockRepo.On("GetLoginFailure", mock.Anything, subjectType, subject).Return(failure, nil)
+				}
+			}
+			returnFailure(models.LoginSubjectAccount, "example@example.com", tt.accountFailure)
+			returnFailure(models.LoginSubjectIp, "10.0.0.1", tt.ipFailure)
+
+			service := GetLoginAttemptsService(mockRepo, mockLog, mockCfg)
+
+			err := service.CheckAllowed(ctx, " Example@Example.com", "10.0.0.1")
+			if tt.expectThrottled {
+				var throttled *LoginThrottledError
+				assert.ErrorAs(t, err, &throttled)
+				assert.ErrorIs(t, err, apperrors.TooManyLoginAttemptsErr)
+				assert.Greater(t, throttled.RetryAfter, time.Duration(0))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRecordFailureLocksAccount(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.LoginAttemptRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockCfg.LoginMaxFailures = 5
+	mockCfg.LoginMaxFailuresPerIp = 50
+	mockLog := log.New(mockCfg)
+
+	service := GetLoginAttemptsService(mockRepo, mockLog, mockCfg)
+
+	mockRepo.On("AddLoginFailure", mock.Anything, models.LoginSubjectAccount, "example@example.com", mock.Anything).
+		Return(&models.LoginFailure{Failures: 5}, nil)
+	mockRepo.On("AddLoginFailure", mock.Anything, models.LoginSubjectIp, "10.0.0.1", mock.Anything).
+		Return(&models.LoginFailure{Failures: 5}, nil)
+	mockRepo.On("LockLogin", mock.Anything, models.LoginSubjectAccount, "example@example.com",
+		mock.Anything).Return(nil)
+	mockRepo.On("AddLockoutEvent", mock.Anything, mock.MatchedBy(func(e *models.LoginLockoutEvent) bool {
+		return e.Event == models.LoginEventLocked && e.Subject == "example@example.com"
+	})).Return(nil)
+
+	err := service.RecordFailure(ctx, "example@example.com", "10.0.0.1")
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "LockLogin", mock.Anything, models.LoginSubjectIp, "10.0.0.1", mock.Anything)
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// MessagesService keeps private conversations between two users. Users who are not participants
+// get NotFoundErr for a conversation, so they can't even learn that it exists.
+type MessagesService struct {
+	log         *log.Logger
+	cfg         *config.Config
+	messageRepo repositories.MessageRepositoryInterface
+	userRepo    repositories.UserRepositoryInterface
+	itemRepo    repositories.ItemRepositoryInterface
+	unitOfWork  repositories.UnitOfWorkInterface
+}
+
+type MessagesServiceInterface interface {
+	StartConversation(ctx context.Context, sender *models.User, recipientId int, itemId *int,
+		body string) (*models.Conversation, *models.Message, error)
+	SendMessage(ctx context.Context, sender *models.User, conversationId int, body string) (*models.Message, error)
+	GetConversations(ctx context.Context, user *models.User) ([]*models.ConversationSummary, error)
+	GetMessages(ctx context.Context, user *models.User, conversationId int, beforeId int,
+		limit int) ([]*models.Message, error)
+	MarkRead(ctx context.Context, user *models.User, conversationId int) (int, error)
+	GetUnreadCount(ctx context.Context, user *models.User) (int, error)
+	BlockUser(ctx context.Context, user *models.User, blockedId int) error
+	UnblockUser(ctx context.Context, user *models.User, blockedId int) error
+	GetBlockedUsers(ctx context.Context, user *models.User) ([]*models.UserBlock, error)
+}
+
+const (
+	defaultMessagesLimit = 50
+	maxMessagesLimit     = 100
+)
+
+func GetMessagesService(messageRepo repositories.MessageRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface, itemRepo repositories.ItemRepositoryInterface,
+	unitOfWork repositories.UnitOfWorkInterface, log *log.Logger, cfg *config.Config) MessagesServiceInterface {
+
+	return &MessagesService{
+		log:         log,
+		cfg:         cfg,
+		messageRepo: messageRepo,
+		userRepo:    userRepo,
+		itemRepo:    itemRepo,
+		unitOfWork:  unitOfWork,
+	}
+}
+
+// StartConversation sends the first message to the recipient, or the next one
+// if the users already talk about the same item
+func (ms *MessagesService) StartConversation(ctx context.Context, sender *models.User, recipientId int, itemId *int,
+	body string) (*models.Conversation, *models.Message, error) {
+	if sender.Id == recipientId {
+		return nil, nil, errors.CannotMessageSelfErr
+	}
+
+	recipient := ms.userRepo.GetUserById(ctx, recipientId)
+	if recipient == nil || recipient.IsBanned() {
+		return nil, nil, errors.InvalidRecipientErr
+	}
+
+	if itemId != nil && !ms.isItemOf(ctx, *itemId, sender.Id, recipientId) {
+		return nil, nil, errors.InvalidConversationItemErr
+	}
+
+	err := ms.checkNotBlocked(ctx, recipientId, sender.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userAId, userBId := min(sender.Id, recipientId), max(sender.Id, recipientId)
+
+	var conversation *models.Conversation
+	var message *models.Message
+	// a new conversation is kept only together with its first message
+	err = ms.unitOfWork.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		conversation, err = ms.messageRepo.FindConversation(ctx, userAId, userBId, itemId)
+		if goErrors.Is(err, errors.NotFoundErr) {
+			conversation, err = ms.messageRepo.CreateConversation(ctx, &models.Conversation{
+				UserAId: userAId,
+				UserBId: userBId,
+				ItemId:  itemId,
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		message, err = ms.messageRepo.CreateMessage(ctx, &models.Message{
+			ConversationId: conversation.Id,
+			SenderId:       sender.Id,
+			Body:           body,
+		})
+
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conversation, message, nil
+}
+
+func (ms *MessagesService) SendMessage(ctx context.Context, sender *models.User, conversationId int,
+	body string) (*models.Message, error) {
+	conversation, err := ms.getConversationOf(ctx, sender, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ms.checkNotBlocked(ctx, conversation.OtherParticipant(sender.Id), sender.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ms.messageRepo.CreateMessage(ctx, &models.Message{
+		ConversationId: conversation.Id,
+		SenderId:       sender.Id,
+		Body:           body,
+	})
+}
+
+func (ms *MessagesService) GetConversations(ctx context.Context,
+	user *models.User) ([]*models.ConversationSummary, error) {
+	conversations, err := ms.messageRepo.GetUserConversations(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, conversation := range conversations {
+		conversation.OtherUserId = conversation.OtherParticipant(user.Id)
+	}
+
+	return conversations, nil
+}
+
+func (ms *MessagesService) GetMessages(ctx context.Context, user *models.User, conversationId int, beforeId int,
+	limit int) ([]*models.Message, error) {
+	if limit < 1 {
+		limit = defaultMessagesLimit
+	}
+	limit = min(limit, maxMessagesLimit)
+
+	_, err := ms.getConversationOf(ctx, user, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	return ms.messageRepo.GetMessages(ctx, conversationId, beforeId, limit)
+}
+
+func (ms *MessagesService) MarkRead(ctx context.Context, user *models.User, conversationId int) (int, error) {
+	_, err := ms.getConversationOf(ctx, user, conversationId)
+	if err != nil {
+		return 0, err
+	}
+
+	return ms.messageRepo.MarkRead(ctx, conversationId, user.Id)
+}
+
+func (ms *MessagesService) GetUnreadCount(ctx context.Context, user *models.User) (int, error) {
+	return ms.messageRepo.GetUnreadCount(ctx, user.Id)
+}
+
+// BlockUser stops the blocked user from sending messages to the user, existing conversations stay readable
+func (ms *MessagesService) BlockUser(ctx context.Context, user *models.User, blockedId int) error {
+	if user.Id == blockedId {
+		return errors.CannotBlockSelfErr
+	}
+	if ms.userRepo.GetUserById(ctx, blockedId) == nil {
+		return errors.NotFoundErr
+	}
+
+	return ms.messageRepo.BlockUser(ctx, user.Id, blockedId)
+}
+
+func (ms *MessagesService) UnblockUser(ctx context.Context, user *models.User, blockedId int) error {
+	return ms.messageRepo.UnblockUser(ctx, user.Id, blockedId)
+}
+
+func (ms *MessagesService) GetBlockedUsers(ctx context.Context, user *models.User) ([]*models.UserBlock, error) {
+	return ms.messageRepo.GetBlockedUsers(ctx, user.Id)
+}
+
+func (ms *MessagesService) getConversationOf(ctx context.Context, user *models.User,
+	conversationId int) (*models.Conversation, error) {
+	conversation, err := ms.messageRepo.GetConversation(ctx, conversationId)
+	if err != nil {
+		return nil, err
+	}
+	if !conversation.HasParticipant(user.Id) {
+		return nil, errors.NotFoundErr
+	}
+
+	return conversation, nil
+}
+
+func (ms *MessagesService) checkNotBlocked(ctx context.Context, recipientId int, senderId int) error {
+	blocked, err := ms.messageRepo.IsBlocked(ctx, recipientId, senderId)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return errors.BlockedByUserErr
+	}
+
+	return nil
+}
+
+// isItemOf tells whether the item belongs to one of the users
+func (ms *MessagesService) isItemOf(ctx context.Context, itemId int, userIds ...int) bool {
+	for _, userId := range userIds {
+		item, err := ms.itemRepo.GetItemById(ctx, itemId, userId)
+		if err == nil && item != nil {
+			return true
+		}
+	}
+
+	return false
+}
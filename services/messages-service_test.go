@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestStartConversation(t *testing.T) {
+	ctx := context.Background()
+	mockMessageRepo := new(mocks.MessageRepositoryInterface)
+	mockUserRepo := new(mocks.UserRepositoryInterface)
+	mockItemRepo := new(mocks.ItemRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetMessagesService(mockMessageRepo, mockUserRepo, mockItemRepo, runningUnitOfWork(), mockLog,
+		mockCfg)
+
+	buyer := &models.User{Id: 5}
+	sellerItemId := 10
+	otherItemId := 11
+
+	mockUserRepo.On("GetUserById", mock.Anything, 2).Return(&models.User{Id: 2, Status: models.UserStatusActive})
+	mockUserRepo.On("GetUserById", mock.Anything, 3).Return(&models.User{Id: 3, Status: models.UserStatusBanned})
+	mockUserRepo.On("GetUserById", mock.Anything, 4).Return(&models.User{Id: 4, Status: models.UserStatusActive})
+	mockItemRepo.On("GetItemById", mock.Anything, sellerItemId, 5).Return(nil, sql.ErrNoRows)
+	mockItemRepo.On("GetItemById", mock.Anything, sellerItemId, 2).
+		Return(&models.Item{Id: sellerItemId, UserId: 2}, nil)
+	mockItemRepo.On("GetItemById", mock.Anything, otherItemId, mock.Anything).Return(nil, sql.ErrNoRows)
+	mockMessageRepo.On("IsBlocked", mock.Anything, 2, 5).Return(false, nil)
+	mockMessageRepo.On("IsBlocked", mock.Anything, 4, 5).Return(true, nil)
+	mockMessageRepo.On("FindConversation", mock.Anything, 2, 5, &sellerItemId).Return(nil, apperrors.NotFoundErr)
+	mockMessageRepo.On("CreateConversation", mock.Anything, mock.MatchedBy(func(c *models.Conversation) bool {
+		return c.UserAId == 2 && c.UserBId == 5 && *c.ItemId == sellerItemId
+	})).Return(&models.Conversation{Id: 7, UserAId: 2, UserBId: 5, ItemId: &sellerItemId}, nil)
+	mockMessageRepo.On("CreateMessage", mock.Anything, mock.MatchedBy(func(m *models.Message) bool {
+		return m.ConversationId == 7 && m.SenderId == 5
+	})).Return(&models.Message{Id: 1, ConversationId: 7, SenderId: 5, Body: "Is it available?"}, nil)
+
+	tests := []struct {
+		name        string
+		recipientId int
+		itemId      *int
+		expectedErr error
+	}{
+		{"Self", 5, nil, apperrors.CannotMessageSelfErr},
+		{"Banned recipient", 3, nil, apperrors.InvalidRecipientErr},
+		{"Item of somebody else", 2, &otherItemId, apperrors.InvalidConversationItemErr},
+		{"Blocked by the recipient", 4, nil, apperrors.BlockedByUserErr},
+		{"About an item of the recipient", 2, &sellerItemId, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversation, message, err := service.StartConversation(ctx, buyer, tt.recipientId, tt.itemId,
+				"Is it available?")
+			if tt.expectedErr != nil {
+				assert.Nil(t, conversation)
+				assert.Nil(t, message)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 7, conversation.Id)
+				assert.Equal(t, 7, message.ConversationId)
+			}
+		})
+	}
+}
+
+func TestConversationAccess(t *testing.T) {
+	ctx := context.Background()
+	mockMessageRepo := new(mocks.MessageRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetMessagesService(mockMessageRepo, new(mocks.UserRepositoryInterface),
+		new(mocks.ItemRepositoryInterface), nil, mockLog, mockCfg)
+
+	participant := &models.User{Id: 2}
+	stranger := &models.User{Id: 9}
+
+	mockMessageRepo.On("GetConversation", mock.Anything, 7).
+		Return(&models.Conversation{Id: 7, UserAId: 2, UserBId: 5}, nil)
+	mockMessageRepo.On("GetMessages", mock.Anything, 7, 0, maxMessagesLimit).Return([]*models.Message{{Id: 1}}, nil)
+	mockMessageRepo.On("MarkRead", mock.Anything, 7, 2).Return(3, nil)
+	mockMessageRepo.On("IsBlocked", mock.Anything, 5, 2).Return(true, nil)
+
+	t.Run("Stranger can't see the conversation", func(t *testing.T) {
+		_, err := service.GetMessages(ctx, stranger, 7, 0, 10)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+		_, err = service.MarkRead(ctx, stranger, 7)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+		_, err = service.SendMessage(ctx, stranger, 7, "Hi")
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+	})
+
+	t.Run("Participant reads the conversation", func(t *testing.T) {
+		messages, err := service.GetMessages(ctx, participant, 7, 0, 1000)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+
+		marked, err := service.MarkRead(ctx, participant, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, marked)
+	})
+
+	t.Run("Blocked participant can't send", func(t *testing.T) {
+		_, err := service.SendMessage(ctx, participant, 7, "Hi")
+		assert.ErrorIs(t, err, apperrors.BlockedByUserErr)
+		mockMessageRepo.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/totp"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type MfaService struct {
+	log     *log.Logger
+	cfg     *config.Config
+	mfaRepo repositories.MfaRepositoryInterface
+}
+
+type MfaEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauthUri"`
+}
+
+type MfaServiceInterface interface {
+	Enroll(ctx context.Context, user *models.User) (*MfaEnrollment, error)
+	Confirm(ctx context.Context, user *models.User, code string) ([]string, error)
+	Disable(ctx context.Context, user *models.User, code string) error
+	VerifyCode(ctx context.Context, user *models.User, code string) (bool, error)
+}
+
+const (
+	recoveryCodesCount = 10
+	recoveryCodeBytes  = 8
+	// accept codes of the previous and the next time step to allow for clock drift
+	totpSkewSteps = 1
+)
+
+func GetMfaService(mfaRepo repositories.MfaRepositoryInterface,
+	log *log.Logger, cfg *config.Config) MfaServiceInterface {
+
+	return &MfaService{
+		log:     log,
+		cfg:     cfg,
+		mfaRepo: mfaRepo,
+	}
+}
+
+// Enroll creates a new secret. Two-factor authentication is enabled only after Confirm.
+func (ms *MfaService) Enroll(ctx context.Context, user *models.User) (*MfaEnrollment, error) {
+	if user.MfaEnabled {
+		return nil, errors.MfaAlreadyEnabledErr
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = ms.mfaRepo.SetMfaSecret(ctx, user.Id, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MfaEnrollment{
+		Secret:     secret,
+		OtpauthUri: totp.URI(ms.cfg.MfaIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication and returns recovery codes, they are shown only once
+func (ms *MfaService) Confirm(ctx context.Context, user *models.User, code string) ([]string, error) {
+	if user.MfaEnabled {
+		return nil, errors.MfaAlreadyEnabledErr
+	}
+	if user.MfaSecret == nil {
+		return nil, errors.MfaNotEnabledErr
+	}
+
+	step, ok := totp.Validate(*user.MfaSecret, normalizeMfaCode(code), time.Now(), totpSkewSteps)
+	if !ok {
+		return nil, errors.InvalidMfaCodeErr
+	}
+
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+	for range recoveryCodesCount {
+		raw, err := generateToken(recoveryCodeBytes)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, formatRecoveryCode(raw))
+		hashes = append(hashes, hashToken(raw))
+	}
+
+	err := ms.mfaRepo.EnableMfa(ctx, user.Id, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (ms *MfaService) Disable(ctx context.Context, user *models.User, code string) error {
+	if !user.MfaEnabled {
+		return errors.MfaNotEnabledErr
+	}
+
+	ok, err := ms.VerifyCode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.InvalidMfaCodeErr
+	}
+
+	return ms.mfaRepo.DisableMfa(ctx, user.Id)
+}
+
+// VerifyCode accepts a code from the authenticator app or an unused recovery code.
+// Every code can be used only once.
+func (ms *MfaService) VerifyCode(ctx context.Context, user *models.User, code string) (bool, error) {
+	if !user.MfaEnabled || user.MfaSecret == nil {
+		return false, nil
+	}
+
+	code = normalizeMfaCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(*user.MfaSecret, code, time.Now(), totpSkewSteps)
+		if !ok {
+			return false, nil
+		}
+
+		return ms.mfaRepo.UseMfaStep(ctx, user.Id, step)
+	}
+
+	return ms.mfaRepo.UseRecoveryCode(ctx, user.Id, hashToken(code))
+}
+
+func normalizeMfaCode(code string) string {
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+
+	return strings.ToLower(code)
+}
+
+// formatRecoveryCode splits a hex code into groups of four for readability
+func formatRecoveryCode(code string) string {
+	var parts []string
+	for i := 0; i < len(code); i += 4 {
+		parts = append(parts, code[i:min(i+4, len(code))])
+	}
+
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/totp"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestConfirmMfa(t *testing.T) {
+	ctx := context.Background()
+	mockMfaRepo := new(mocks.MfaRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetMfaService(mockMfaRepo, mockLog, mockCfg)
+
+	secret, _ := totp.GenerateSecret()
+	user := &models.User{Id: 1, Email: "example@example.com", MfaSecret: &secret}
+
+	t.Run("Wrong code", func(t *testing.T) {
+		codes, err := service.Confirm(ctx, user, "abcdef")
+		assert.Nil(t, codes)
+		assert.ErrorIs(t, err, apperrors.InvalidMfaCodeErr)
+	})
+
+	t.Run("Returns recovery codes", func(t *testing.T) {
+		mockMfaRepo.On("EnableMfa", mock.Anything, 1, totp.Step(time.Now()), mock.MatchedBy(func(hashes []string) bool {
+			return len(hashes) == recoveryCodesCount
+		})).Return(nil)
+
+		code, _ := totp.Code(secret, time.Now())
+		codes, err := service.Confirm(ctx, user, code)
+		assert.NoError(t, err)
+		assert.Len(t, codes, recoveryCodesCount)
+		assert.Equal(t, "xxxx-xxxx-xxxx-xxxx", formatRecoveryCode("xxxxxxxxxxxxxxxx"))
+		mockMfaRepo.AssertExpectations(t)
+	})
+}
+
+func TestVerifyMfaCode(t *testing.T) {
+	ctx := context.Background()
+	mockMfaRepo := new(mocks.MfaRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetMfaService(mockMfaRepo, mockLog, mockCfg)
+
+	secret, _ := totp.GenerateSecret()
+	user := &models.User{Id: 1, MfaEnabled: true, MfaSecret: &secret}
+	code, _ := totp.Code(secret, time.Now())
+
+	t.Run("Code can't be replayed", func(t *testing.T) {
+		mockMfaRepo.On("UseMfaStep", mock.Anything, 1, totp.Step(time.Now())).Return(true, nil).Once()
+		mockMfaRepo.On("UseMfaStep", mock.Anything, 1, totp.Step(time.Now())).Return(false, nil).Once()
+
+		ok, err := service.VerifyCode(ctx, user, code)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = service.VerifyCode(ctx, user, code)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Recovery code", func(t *testing.T) {
+		mockMfaRepo.On("UseRecoveryCode", mock.Anything, 1, hashToken("0123456789abcdef")).Return(true, nil)
+
+		ok, err := service.VerifyCode(ctx, user, "0123-4567-89AB-CDEF")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Mfa is not enabled", func(t *testing.T) {
+		ok, err := service.VerifyCode(ctx, &models.User{Id: 2}, code)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
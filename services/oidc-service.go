@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+	"ypeskov/go_hillel_9/internal/cache"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/oidc"
+	"ypeskov/go_hillel_9/internal/password"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// OidcClient is the part of oidc.Client the login needs
+type OidcClient interface {
+	AuthCodeURL(state string, nonce string, codeChallenge string) (string, error)
+	Exchange(code string, codeVerifier string, nonce string) (*oidc.IdTokenClaims, error)
+}
+
+// pendingOidcLogin is kept between the redirect to the provider and the callback
+type pendingOidcLogin struct {
+	codeVerifier string
+	nonce        string
+	device       string
+}
+
+// OidcService logs in users with an external OpenID Connect provider
+// and then issues our own tokens, as a password login does
+type OidcService struct {
+	log          *log.Logger
+	cfg          *config.Config
+	client       OidcClient
+	userRepo     repositories.UserRepositoryInterface
+	userTypeRepo repositories.UserTypeRepositoryInterface
+	sessions     SessionsServiceInterface
+	hasher       *password.Hasher
+	// pending logins are kept in memory, the callback must reach the instance that started the login
+	pending *cache.TTLCache[string, pendingOidcLogin]
+}
+
+type OidcServiceInterface interface {
+	StartLogin(ctx context.Context, device string) (string, error)
+	FinishLogin(ctx context.Context, state string, code string, client SessionClient) (*LoginResult, error)
+}
+
+const oidcStateBytes = 32
+
+// GetOidcService returns a service that rejects all logins when client is nil, i.e. OIDC is not configured
+func GetOidcService(client OidcClient, userRepo repositories.UserRepositoryInterface,
+	userTypeRepo repositories.UserTypeRepositoryInterface, sessions SessionsServiceInterface,
+	hasher *password.Hasher, log *log.Logger, cfg *config.Config) OidcServiceInterface {
+
+	return &OidcService{
+		log:          log,
+		cfg:          cfg,
+		client:       client,
+		userRepo:     userRepo,
+		userTypeRepo: userTypeRepo,
+		sessions:     sessions,
+		hasher:       hasher,
+		pending: cache.New[string, pendingOidcLogin](
+			time.Duration(cfg.OidcLoginLifetimeMinutes) * time.Minute),
+	}
+}
+
+// StartLogin returns the URL of the provider the user has to be redirected to
+func (oss *OidcService) StartLogin(ctx context.Context, device string) (string, error) {
+	if oss.client == nil {
+		return "", errors.OidcDisabledErr
+	}
+
+	state, err := oidc.RandomString(oidcStateBytes)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString(oidcStateBytes)
+	if err != nil {
+		return "", err
+	}
+	codeVerifier, codeChallenge, err := oidc.NewPkce()
+	if err != nil {
+		return "", err
+	}
+
+	authUrl, err := oss.client.AuthCodeURL(state, nonce, codeChallenge)
+	if err != nil {
+		oss.log.Errorln("failed to build authorization url", err)
+
+		return "", errors.OidcLoginFailedErr
+	}
+
+	oss.pending.Set(state, pendingOidcLogin{codeVerifier: codeVerifier, nonce: nonce, device: device})
+
+	return authUrl, nil
+}
+
+// FinishLogin handles the redirect back from the provider. The user is found by the provider
+// account, then by a verified email, and is created if neither matches.
+func (oss *OidcService) FinishLogin(ctx context.Context, state string, code string,
+	client SessionClient) (*LoginResult, error) {
+	if oss.client == nil {
+		return nil, errors.OidcDisabledErr
+	}
+
+	pending, ok := oss.pending.Get(state)
+	if !ok {
+		return nil, errors.InvalidOidcStateErr
+	}
+	oss.pending.Delete(state)
+
+	claims, err := oss.client.Exchange(code, pending.codeVerifier, pending.nonce)
+	if err != nil {
+		oss.log.Errorln("failed to exchange authorization code", err)
+
+		return nil, errors.OidcLoginFailedErr
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errors.OidcEmailNotVerifiedErr
+	}
+
+	user, err := oss.findOrCreateUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	client.Device = pending.device
+
+	return oss.sessions.LoginUser(ctx, user, client)
+}
+
+func (oss *OidcService) findOrCreateUser(ctx context.Context, claims *oidc.IdTokenClaims) (*models.User, error) {
+	user := oss.userRepo.GetUserByIdentity(ctx, claims.Issuer, claims.Subject)
+	if user != nil {
+		return user, nil
+	}
+
+	user = oss.userRepo.GetUserByEmail(ctx, claims.Email)
+	if user == nil {
+		var err error
+		user, err = oss.createUser(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	} else if !user.EmailVerified {
+		// linking an unverified account would let whoever registered the email first into the account
+		return nil, errors.OidcAccountConflictErr
+	}
+
+	err := oss.userRepo.AddUserIdentity(ctx, user.Id, claims.Issuer, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	oss.log.Infof("User %d linked to %s account %s", user.Id, claims.Issuer, claims.Subject)
+
+	return user, nil
+}
+
+func (oss *OidcService) createUser(ctx context.Context, claims *oidc.IdTokenClaims) (*models.User, error) {
+	userType, err := oss.userTypeRepo.GetUserTypeByCode(ctx, oss.cfg.OidcDefaultUserTypeCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// the account has no usable password until the user sets one
+	randomPassword, err := generateToken(tokenIdBytes)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := oss.hasher.Hash(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" {
+		firstName = strings.Split(claims.Email, "@")[0]
+	}
+
+	user, err := oss.userRepo.CreateUser(ctx, &models.User{
+		FirstName:    firstName,
+		LastName:     lastName,
+		Email:        claims.Email,
+		PasswordHash: hash,
+		UserTypeId:   int32(userType.Id),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = oss.userRepo.SetEmailVerified(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	user.EmailVerified = true
+
+	oss.log.Infof("User %d created on first login with %s", user.Id, claims.Issuer)
+
+	return user, nil
+}
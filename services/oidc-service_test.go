@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/jwtkeys"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/oidc"
+	"ypeskov/go_hillel_9/internal/oidc/oidctest"
+	"ypeskov/go_hillel_9/internal/password"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestOidcLogin(t *testing.T) {
+	ctx := context.Background()
+	provider, err := oidctest.NewProvider("auction", "client-secret")
+	require.NoError(t, err)
+	defer provider.Close()
+
+	mockUserRepo := new(mocks.UserRepositoryInterface)
+	mockUserTypeRepo := new(mocks.UserTypeRepositoryInterface)
+	mockSessionRepo := new(mocks.SessionRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	keys, _ := jwtkeys.Load(mockCfg)
+	hasher, _ := password.NewHasher(mockCfg)
+
+	mockLoginAttemptRepo := new(mocks.LoginAttemptRepositoryInterface)
+	mockLoginAttemptRepo.On("ResetLoginFailures", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	sessions := GetSessionsService(mockUserRepo, mockSessionRepo,
+		GetRevocationService(new(mocks.RevocationRepositoryInterface), mockSessionRepo, mockLog, mockCfg),
+		GetLoginAttemptsService(mockLoginAttemptRepo, mockLog, mockCfg),
+		GetMfaService(new(mocks.MfaRepositoryInterface), mockLog, mockCfg),
+		keys, hasher, mockLog, mockCfg)
+
+	client := oidc.NewClient(oidc.Config{
+		IssuerUrl:    provider.Issuer(),
+		ClientId:     "auction",
+		ClientSecret: "client-secret",
+		RedirectUrl:  "http://localhost:3000/users/oidc/callback/",
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+	service := GetOidcService(client, mockUserRepo, mockUserTypeRepo, sessions, hasher, mockLog, mockCfg)
+
+	mockUserRepo.On("GetUserType", mock.Anything, mock.Anything).Return(&models.UserType{}, nil)
+	mockSessionRepo.On("CreateSession", mock.Anything, mock.Anything).Return(&models.Session{Id: 10}, nil)
+	mockSessionRepo.On("AddRefreshToken", mock.Anything, 10, mock.Anything, mock.Anything).Return(nil)
+
+	login := func(t *testing.T, user oidctest.User) (*LoginResult, error) {
+		provider.SetUser(user)
+
+		authUrl, err := service.StartLogin(ctx, "laptop")
+		require.NoError(t, err)
+		parsed, _ := url.Parse(authUrl)
+		assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+
+		code, state, err := provider.Authorize(authUrl)
+		require.NoError(t, err)
+
+		return service.FinishLogin(ctx, state, code, SessionClient{Ip: "127.0.0.1"})
+	}
+
+	t.Run("Unknown state", func(t *testing.T) {
+		result, err := service.FinishLogin(ctx, "unknown", "code", SessionClient{})
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, apperrors.InvalidOidcStateErr)
+	})
+
+	t.Run("Email not verified by the provider", func(t *testing.T) {
+		result, err := login(t, oidctest.User{Subject: "1", Email: "new@example.com"})
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, apperrors.OidcEmailNotVerifiedErr)
+	})
+
+	t.Run("Creates a new user", func(t *testing.T) {
+		mockUserRepo.On("GetUserByIdentity", mock.Anything, provider.Issuer(), "2").Return(nil).Once()
+		mockUserRepo.On("GetUserByEmail", mock.Anything, "new@example.com").Return(nil).Once()
+		mockUserTypeRepo.On("GetUserTypeByCode", mock.Anything, models.BuyerTypeCode).
+			Return(&models.UserType{Id: 2}, nil)
+		mockUserRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+			return u.Email == "new@example.com" && u.FirstName == "New" && u.UserTypeId == 2
+		})).Return(&models.User{Id: 5, Email: "new@example.com"}, nil)
+		mockUserRepo.On("SetEmailVerified", mock.Anything, 5).Return(nil)
+		mockUserRepo.On("AddUserIdentity", mock.Anything, 5, provider.Issuer(), "2").Return(nil)
+
+		result, err := login(t, oidctest.User{Subject: "2", Email: "new@example.com", EmailVerified: true,
+			GivenName: "New", FamilyName: "User"})
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.Tokens.AccessToken)
+		mockUserRepo.AssertCalled(t, "AddUserIdentity", mock.Anything, 5, provider.Issuer(), "2")
+	})
+
+	t.Run("Doesn't link an unverified local account", func(t *testing.T) {
+		mockUserRepo.On("GetUserByIdentity", mock.Anything, provider.Issuer(), "3").Return(nil)
+		mockUserRepo.On("GetUserByEmail", mock.Anything, "taken@example.com").
+			Return(&models.User{Id: 6, Email: "taken@example.com"})
+
+		result, err := login(t, oidctest.User{Subject: "3", Email: "taken@example.com", EmailVerified: true})
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, apperrors.OidcAccountConflictErr)
+	})
+
+	t.Run("Linked user with mfa gets a challenge", func(t *testing.T) {
+		mockUserRepo.On("GetUserByIdentity", mock.Anything, provider.Issuer(), "4").
+			Return(&models.User{Id: 7, Email: "mfa@example.com", MfaEnabled: true})
+
+		result, err := login(t, oidctest.User{Subject: "4", Email: "mfa@example.com", EmailVerified: true})
+		require.NoError(t, err)
+		assert.Nil(t, result.Tokens)
+		assert.NotEmpty(t, result.MfaToken)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		disabled := GetOidcService(nil, mockUserRepo, mockUserTypeRepo, sessions, hasher, mockLog, mockCfg)
+		_, err := disabled.StartLogin(ctx, "")
+		assert.ErrorIs(t, err, apperrors.OidcDisabledErr)
+	})
+}
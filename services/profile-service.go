@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// ProfileService serves the public pages of sellers, anyone can see them without logging in
+type ProfileService struct {
+	log         *log.Logger
+	cfg         *config.Config
+	profileRepo repositories.ProfileRepositoryInterface
+	itemRepo    repositories.ItemRepositoryInterface
+	reviewRepo  repositories.ReviewRepositoryInterface
+}
+
+type Storefront struct {
+	Seller  *models.UserProfile `json:"seller"`
+	Items   []*models.Item      `json:"items"`
+	Total   int                 `json:"total"`
+	Page    int                 `json:"page"`
+	PerPage int                 `json:"perPage"`
+}
+
+type ProfileServiceInterface interface {
+	GetUserProfile(ctx context.Context, userId int) (*models.UserProfile, error)
+	GetStorefront(ctx context.Context, sellerId int, page int, perPage int) (*Storefront, error)
+}
+
+const (
+	defaultItemsPerPage = 20
+	maxItemsPerPage     = 100
+)
+
+func GetProfileService(profileRepo repositories.ProfileRepositoryInterface,
+	itemRepo repositories.ItemRepositoryInterface, reviewRepo repositories.ReviewRepositoryInterface,
+	log *log.Logger, cfg *config.Config) ProfileServiceInterface {
+
+	return &ProfileService{
+		log:         log,
+		cfg:         cfg,
+		profileRepo: profileRepo,
+		itemRepo:    itemRepo,
+		reviewRepo:  reviewRepo,
+	}
+}
+
+func (ps *ProfileService) GetUserProfile(ctx context.Context, userId int) (*models.UserProfile, error) {
+	profile, err := ps.profileRepo.GetUserProfile(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := ps.reviewRepo.GetRatingSummary(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	profile.AverageRating = summary.AverageRating
+	profile.RatingsCount = summary.RatingsCount
+
+	return profile, nil
+}
+
+// GetStorefront returns the profile of the seller and one page of their unsold items
+func (ps *ProfileService) GetStorefront(ctx context.Context, sellerId int, page int, perPage int) (*Storefront, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultItemsPerPage
+	}
+	perPage = min(perPage, maxItemsPerPage)
+
+	seller, err := ps.GetUserProfile(ctx, sellerId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := ps.itemRepo.GetItemsList(ctx, models.ItemsFilter{
+		UserId:     sellerId,
+		ActiveOnly: true,
+		Limit:      perPage,
+		Offset:     (page - 1) * perPage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Storefront{
+		Seller:  seller,
+		Items:   items,
+		Total:   seller.ActiveListings,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestGetStorefront(t *testing.T) {
+	ctx := context.Background()
+	mockProfileRepo := new(mocks.ProfileRepositoryInterface)
+	mockItemRepo := new(mocks.ItemRepositoryInterface)
+	mockReviewRepo := new(mocks.ReviewRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetProfileService(mockProfileRepo, mockItemRepo, mockReviewRepo, mockLog, mockCfg)
+
+	seller := &models.UserProfile{Id: 1, DisplayName: "John D.", ActiveListings: 150}
+	mockProfileRepo.On("GetUserProfile", mock.Anything, 1).Return(seller, nil)
+	mockProfileRepo.On("GetUserProfile", mock.Anything, 2).Return(nil, apperrors.NotFoundErr)
+	rating := 4.5
+	mockReviewRepo.On("GetRatingSummary", mock.Anything, 1).Return(&models.RatingSummary{AverageRating: &rating,
+		RatingsCount: 2}, nil)
+
+	t.Run("Unknown or banned seller", func(t *testing.T) {
+		storefront, err := service.GetStorefront(ctx, 2, 1, 10)
+		assert.Nil(t, storefront)
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+		mockItemRepo.AssertNotCalled(t, "GetItemsList", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Only unsold items of the page", func(t *testing.T) {
+		items := []*models.Item{{Id: 101, UserId: 1}}
+		mockItemRepo.On("GetItemsList", mock.Anything, models.ItemsFilter{UserId: 1, ActiveOnly: true, Limit: 100,
+			Offset: 100}).
+			Return(items, nil)
+
+		storefront, err := service.GetStorefront(ctx, 1, 2, 1000)
+		assert.NoError(t, err)
+		assert.Equal(t, seller, storefront.Seller)
+		assert.Equal(t, 4.5, *storefront.Seller.AverageRating)
+		assert.Equal(t, 2, storefront.Seller.RatingsCount)
+		assert.Equal(t, items, storefront.Items)
+		assert.Equal(t, 150, storefront.Total)
+		assert.Equal(t, maxItemsPerPage, storefront.PerPage)
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		mockItemRepo.On("GetItemsList", mock.Anything, models.ItemsFilter{UserId: 1, ActiveOnly: true,
+			Limit: defaultItemsPerPage}).
+			Return([]*models.Item{}, nil)
+
+		storefront, err := service.GetStorefront(ctx, 1, 0, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, storefront.Page)
+		assert.Equal(t, defaultItemsPerPage, storefront.PerPage)
+	})
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// QuestionsService lets buyers ask the seller about an item. Answered questions are shown to everybody,
+// unanswered ones only to the asker and the seller.
+type QuestionsService struct {
+	log          *log.Logger
+	cfg          *config.Config
+	questionRepo repositories.QuestionRepositoryInterface
+	itemRepo     repositories.ItemRepositoryInterface
+}
+
+type QuestionsServiceInterface interface {
+	AskQuestion(ctx context.Context, user *models.User, itemId int, text string) (*models.ItemQuestion, error)
+	GetQuestions(ctx context.Context, user *models.User, itemId int) ([]*models.ItemQuestion, error)
+	AnswerQuestion(ctx context.Context, user *models.User, itemId int, questionId int,
+		answer string) (*models.ItemQuestion, error)
+}
+
+func GetQuestionsService(questionRepo repositories.QuestionRepositoryInterface,
+	itemRepo repositories.ItemRepositoryInterface, log *log.Logger, cfg *config.Config) QuestionsServiceInterface {
+
+	return &QuestionsService{
+		log:          log,
+		cfg:          cfg,
+		questionRepo: questionRepo,
+		itemRepo:     itemRepo,
+	}
+}
+
+func (qs *QuestionsService) AskQuestion(ctx context.Context, user *models.User, itemId int,
+	text string) (*models.ItemQuestion, error) {
+	item, err := qs.itemRepo.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserId == user.Id {
+		return nil, errors.CannotAskOwnItemErr
+	}
+
+	return qs.questionRepo.CreateQuestion(ctx, &models.ItemQuestion{
+		ItemId:   itemId,
+		AskerId:  user.Id,
+		Question: text,
+	})
+}
+
+func (qs *QuestionsService) GetQuestions(ctx context.Context, user *models.User,
+	itemId int) ([]*models.ItemQuestion, error) {
+	item, err := qs.itemRepo.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	return qs.questionRepo.GetItemQuestions(ctx, itemId, user.Id, item.UserId == user.Id)
+}
+
+func (qs *QuestionsService) AnswerQuestion(ctx context.Context, user *models.User, itemId int, questionId int,
+	answer string) (*models.ItemQuestion, error) {
+	item, err := qs.itemRepo.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := qs.questionRepo.GetQuestionById(ctx, questionId)
+	if err != nil {
+		return nil, err
+	}
+	// a question of another item is reported as missing, not as foreign
+	if question.ItemId != itemId {
+		return nil, errors.NotFoundErr
+	}
+
+	if item.UserId != user.Id {
+		return nil, errors.NotItemOwnerErr
+	}
+	if question.Answer != nil {
+		return nil, errors.QuestionAlreadyAnsweredErr
+	}
+
+	answered, err := qs.questionRepo.AnswerQuestion(ctx, questionId, answer)
+	if goErrors.Is(err, errors.NotFoundErr) {
+		return nil, errors.QuestionAlreadyAnsweredErr
+	}
+
+	return answered, err
+}
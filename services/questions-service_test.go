@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestItemQuestions(t *testing.T) {
+	ctx := context.Background()
+	mockQuestionRepo := new(mocks.QuestionRepositoryInterface)
+	mockItemRepo := new(mocks.ItemRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetQuestionsService(mockQuestionRepo, mockItemRepo, mockLog, mockCfg)
+
+	seller := &models.User{Id: 1}
+	buyer := &models.User{Id: 2}
+	answer := "Yes"
+
+	mockItemRepo.On("GetItem", mock.Anything, 10).Return(&models.Item{Id: 10, UserId: seller.Id}, nil)
+	mockItemRepo.On("GetItem", mock.Anything, 11).Return(nil, apperrors.NotFoundErr)
+	mockQuestionRepo.On("GetQuestionById", mock.Anything, 1).
+		Return(&models.ItemQuestion{Id: 1, ItemId: 10, AskerId: 2}, nil)
+	mockQuestionRepo.On("GetQuestionById", mock.Anything, 2).
+		Return(&models.ItemQuestion{Id: 2, ItemId: 10, AskerId: 2, Answer: &answer}, nil)
+	mockQuestionRepo.On("GetQuestionById", mock.Anything, 3).
+		Return(&models.ItemQuestion{Id: 3, ItemId: 12, AskerId: 2}, nil)
+	mockQuestionRepo.On("AnswerQuestion", mock.Anything, 1, "Yes").
+		Return(&models.ItemQuestion{Id: 1, ItemId: 10, AskerId: 2, Answer: &answer}, nil)
+	mockQuestionRepo.On("GetItemQuestions", mock.Anything, 10, seller.Id, true).Return([]*models.ItemQuestion{}, nil)
+	mockQuestionRepo.On("GetItemQuestions", mock.Anything, 10, buyer.Id, false).Return([]*models.ItemQuestion{}, nil)
+
+	t.Run("Seller can't ask about their item", func(t *testing.T) {
+		_, err := service.AskQuestion(ctx, seller, 10, "Is it new?")
+		assert.ErrorIs(t, err, apperrors.CannotAskOwnItemErr)
+	})
+
+	t.Run("Unknown item", func(t *testing.T) {
+		_, err := service.AskQuestion(ctx, buyer, 11, "Is it new?")
+		assert.ErrorIs(t, err, apperrors.NotFoundErr)
+	})
+
+	t.Run("Only the seller sees unanswered questions of others", func(t *testing.T) {
+		_, err := service.GetQuestions(ctx, seller, 10)
+		assert.NoError(t, err)
+		_, err = service.GetQuestions(ctx, buyer, 10)
+		assert.NoError(t, err)
+		mockQuestionRepo.AssertCalled(t, "GetItemQuestions", mock.Anything, 10, seller.Id, true)
+		mockQuestionRepo.AssertCalled(t, "GetItemQuestions", mock.Anything, 10, buyer.Id, false)
+	})
+
+	tests := []struct {
+		name        string
+		user        *models.User
+		questionId  int
+		expectedErr error
+	}{
+		{"Question of another item", seller, 3, apperrors.NotFoundErr},
+		{"Not the seller", buyer, 1, apperrors.NotItemOwnerErr},
+		{"Already answered", seller, 2, apperrors.QuestionAlreadyAnsweredErr},
+		{"Seller answers", seller, 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question, err := service.AnswerQuestion(ctx, tt.user, 10, tt.questionId, "Yes")
+			if tt.expectedErr != nil {
+				assert.Nil(t, question)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "Yes", *question.Answer)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// ReviewService lets the buyer and the seller of an item rate each other once per sale
+type ReviewService struct {
+	log        *log.Logger
+	cfg        *config.Config
+	reviewRepo repositories.ReviewRepositoryInterface
+	itemRepo   repositories.ItemRepositoryInterface
+}
+
+type ReviewsPage struct {
+	Summary *models.RatingSummary `json:"summary"`
+	Reviews []*models.Review      `json:"reviews"`
+	Page    int                   `json:"page"`
+	PerPage int                   `json:"perPage"`
+}
+
+type ReviewServiceInterface interface {
+	CreateReview(ctx context.Context, reviewer *models.User, itemId int, rating int,
+		text string) (*models.Review, error)
+	GetUserReviews(ctx context.Context, userId int, page int, perPage int) (*ReviewsPage, error)
+	RespondToReview(ctx context.Context, user *models.User, reviewId int, response string) error
+	DisputeReview(ctx context.Context, user *models.User, reviewId int, reason string) error
+	GetDisputedReviews(ctx context.Context) ([]*models.Review, error)
+	ResolveDispute(ctx context.Context, admin *models.User, reviewId int, remove bool) error
+}
+
+func GetReviewService(reviewRepo repositories.ReviewRepositoryInterface,
+	itemRepo repositories.ItemRepositoryInterface, log *log.Logger, cfg *config.Config) ReviewServiceInterface {
+
+	return &ReviewService{
+		log:        log,
+		cfg:        cfg,
+		reviewRepo: reviewRepo,
+		itemRepo:   itemRepo,
+	}
+}
+
+// CreateReview rates the other party of the sale of the item
+func (rs *ReviewService) CreateReview(ctx context.Context, reviewer *models.User, itemId int, rating int,
+	text string) (*models.Review, error) {
+	item, err := rs.itemRepo.GetSoldItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	var revieweeId int
+	switch {
+	case reviewer.Id == item.UserId && item.BuyerId != nil:
+		revieweeId = *item.BuyerId
+	case item.BuyerId != nil && reviewer.Id == *item.BuyerId:
+		revieweeId = item.UserId
+	default:
+		return nil, errors.NotSalePartyErr
+	}
+
+	window := time.Duration(rs.cfg.ReviewWindowDays) * 24 * time.Hour
+	if time.Since(*item.SoldAt) > window {
+		return nil, errors.ReviewWindowClosedErr
+	}
+
+	// the repository returns ReviewExistsErr as well if another request creates the review in the meantime
+	exists, err := rs.reviewRepo.HasReview(ctx, itemId, reviewer.Id)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.ReviewExistsErr
+	}
+
+	return rs.reviewRepo.CreateReview(ctx, &models.Review{
+		ItemId:     &itemId,
+		ReviewerId: reviewer.Id,
+		RevieweeId: revieweeId,
+		Rating:     rating,
+		Review:     text,
+	})
+}
+
+func (rs *ReviewService) GetUserReviews(ctx context.Context, userId int, page int, perPage int) (*ReviewsPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultItemsPerPage
+	}
+	perPage = min(perPage, maxItemsPerPage)
+
+	summary, err := rs.reviewRepo.GetRatingSummary(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := rs.reviewRepo.GetUserReviews(ctx, userId, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReviewsPage{
+		Summary: summary,
+		Reviews: reviews,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+// RespondToReview adds the one public answer the reviewed user can give
+func (rs *ReviewService) RespondToReview(ctx context.Context, user *models.User, reviewId int, response string) error {
+	review, err := rs.getReviewOf(ctx, user, reviewId)
+	if err != nil {
+		return err
+	}
+	if review.Response != nil {
+		return errors.ReviewAlreadyAnsweredErr
+	}
+
+	err = rs.reviewRepo.AddResponse(ctx, reviewId, response)
+	if goErrors.Is(err, errors.NotFoundErr) {
+		return errors.ReviewAlreadyAnsweredErr
+	}
+
+	return err
+}
+
+// DisputeReview asks administrators to check the review, it stays visible until they decide
+func (rs *ReviewService) DisputeReview(ctx context.Context, user *models.User, reviewId int, reason string) error {
+	review, err := rs.getReviewOf(ctx, user, reviewId)
+	if err != nil {
+		return err
+	}
+	if review.Status != models.ReviewStatusPublished || review.ResolvedAt != nil {
+		return errors.ReviewDisputeClosedErr
+	}
+
+	err = rs.reviewRepo.DisputeReview(ctx, reviewId, reason)
+	if goErrors.Is(err, errors.NotFoundErr) {
+		return errors.ReviewDisputeClosedErr
+	}
+	if err != nil {
+		return err
+	}
+
+	rs.log.Infof("Review %d disputed by user %d", reviewId, user.Id)
+
+	return nil
+}
+
+func (rs *ReviewService) GetDisputedReviews(ctx context.Context) ([]*models.Review, error) {
+	return rs.reviewRepo.GetDisputedReviews(ctx)
+}
+
+// ResolveDispute either removes the disputed review or publishes it again, it can't be disputed a second time
+func (rs *ReviewService) ResolveDispute(ctx context.Context, admin *models.User, reviewId int, remove bool) error {
+	status := models.ReviewStatusPublished
+	if remove {
+		status = models.ReviewStatusRemoved
+	}
+
+	review, err := rs.reviewRepo.GetReviewById(ctx, reviewId)
+	if err != nil {
+		return err
+	}
+	if review.Status != models.ReviewStatusDisputed {
+		return errors.ReviewNotDisputedErr
+	}
+
+	err = rs.reviewRepo.ResolveDispute(ctx, reviewId, admin.Id, status)
+	if goErrors.Is(err, errors.NotFoundErr) {
+		return errors.ReviewNotDisputedErr
+	}
+	if err != nil {
+		return err
+	}
+
+	rs.log.Infof("Dispute of review %d resolved by admin %d, status %s", reviewId, admin.Id, status)
+
+	return nil
+}
+
+// getReviewOf returns a visible review about the user
+func (rs *ReviewService) getReviewOf(ctx context.Context, user *models.User, reviewId int) (*models.Review, error) {
+	review, err := rs.reviewRepo.GetReviewById(ctx, reviewId)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status == models.ReviewStatusRemoved {
+		return nil, errors.NotFoundErr
+	}
+	if review.RevieweeId != user.Id {
+		return nil, errors.NotRevieweeErr
+	}
+
+	return review, nil
+}
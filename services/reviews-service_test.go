@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestCreateReview(t *testing.T) {
+	ctx := context.Background()
+	mockReviewRepo := new(mocks.ReviewRepositoryInterface)
+	mockItemRepo := new(mocks.ItemRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockCfg.ReviewWindowDays = 30
+	mockLog := log.New(mockCfg)
+	service := GetReviewService(mockReviewRepo, mockItemRepo, mockLog, mockCfg)
+
+	seller := &models.User{Id: 1}
+	buyer := &models.User{Id: 2}
+	buyerId := buyer.Id
+	recently := time.Now().Add(-time.Hour)
+	longAgo := time.Now().Add(-31 * 24 * time.Hour)
+
+	mockItemRepo.On("GetSoldItem", mock.Anything, 10).
+		Return(&models.Item{Id: 10, UserId: 1, BuyerId: &buyerId, SoldAt: &recently}, nil)
+	mockItemRepo.On("GetSoldItem", mock.Anything, 11).Return(&models.Item{Id: 11, UserId: 1, BuyerId: &buyerId,
+		SoldAt: &longAgo}, nil)
+	mockItemRepo.On("GetSoldItem", mock.Anything, 12).Return(nil, apperrors.NotFoundErr)
+	mockReviewRepo.On("HasReview", mock.Anything, 10, seller.Id).Return(true, nil)
+	mockReviewRepo.On("HasReview", mock.Anything, 10, buyer.Id).Return(false, nil)
+	mockReviewRepo.On("CreateReview", mock.Anything, mock.MatchedBy(func(review *models.Review) bool {
+		return review.ReviewerId == buyer.Id && review.RevieweeId == seller.Id && *review.ItemId == 10
+	})).Return(&models.Review{Id: 1, ReviewerId: buyer.Id, RevieweeId: seller.Id, Rating: 5}, nil)
+
+	tests := []struct {
+		name        string
+		reviewer    *models.User
+		itemId      int
+		expectedErr error
+	}{
+		{"Item not sold", buyer, 12, apperrors.NotFoundErr},
+		{"Stranger", &models.User{Id: 3}, 10, apperrors.NotSalePartyErr},
+		{"Window is over", buyer, 11, apperrors.ReviewWindowClosedErr},
+		{"Second review", seller, 10, apperrors.ReviewExistsErr},
+		{"Buyer reviews the seller", buyer, 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review, err := service.CreateReview(ctx, tt.reviewer, tt.itemId, 5, "Great")
+			if tt.expectedErr != nil {
+				assert.Nil(t, review)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, seller.Id, review.RevieweeId)
+			}
+		})
+	}
+}
+
+func TestReviewDispute(t *testing.T) {
+	ctx := context.Background()
+	mockReviewRepo := new(mocks.ReviewRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetReviewService(mockReviewRepo, new(mocks.ItemRepositoryInterface), mockLog, mockCfg)
+
+	reviewee := &models.User{Id: 1}
+	admin := &models.User{Id: 9}
+	response := "Thanks"
+	resolvedAt := time.Now()
+
+	mockReviewRepo.On("GetReviewById", mock.Anything, 1).
+		Return(&models.Review{Id: 1, RevieweeId: 1, Status: models.ReviewStatusPublished}, nil)
+	mockReviewRepo.On("GetReviewById", mock.Anything, 2).
+		Return(&models.Review{Id: 2, RevieweeId: 1, Status: models.ReviewStatusPublished, Response: &response,
+			ResolvedAt: &resolvedAt}, nil)
+	mockReviewRepo.On("GetReviewById", mock.Anything, 3).
+		Return(&models.Review{Id: 3, RevieweeId: 1, Status: models.ReviewStatusDisputed}, nil)
+	mockReviewRepo.On("GetReviewById", mock.Anything, 4).
+		Return(&models.Review{Id: 4, RevieweeId: 1, Status: models.ReviewStatusRemoved}, nil)
+	mockReviewRepo.On("AddResponse", mock.Anything, 1, "Thanks").Return(nil)
+	mockReviewRepo.On("DisputeReview", mock.Anything, 1, "Unfair").Return(nil)
+	mockReviewRepo.On("ResolveDispute", mock.Anything, 3, admin.Id, models.ReviewStatusRemoved).Return(nil)
+
+	t.Run("Only the reviewee responds", func(t *testing.T) {
+		err := service.RespondToReview(ctx, &models.User{Id: 2}, 1, "Thanks")
+		assert.ErrorIs(t, err, apperrors.NotRevieweeErr)
+	})
+
+	t.Run("One response", func(t *testing.T) {
+		assert.NoError(t, service.RespondToReview(ctx, reviewee, 1, "Thanks"))
+		assert.ErrorIs(t, service.RespondToReview(ctx, reviewee, 2, "Thanks"), apperrors.ReviewAlreadyAnsweredErr)
+	})
+
+	t.Run("Removed review is hidden", func(t *testing.T) {
+		assert.ErrorIs(t, service.DisputeReview(ctx, reviewee, 4, "Unfair"), apperrors.NotFoundErr)
+	})
+
+	t.Run("One dispute", func(t *testing.T) {
+		assert.NoError(t, service.DisputeReview(ctx, reviewee, 1, "Unfair"))
+		assert.ErrorIs(t, service.DisputeReview(ctx, reviewee, 2, "Unfair"), apperrors.ReviewDisputeClosedErr)
+		assert.ErrorIs(t, service.DisputeReview(ctx, reviewee, 3, "Unfair"), apperrors.ReviewDisputeClosedErr)
+	})
+
+	t.Run("Resolve", func(t *testing.T) {
+		assert.ErrorIs(t, service.ResolveDispute(ctx, admin, 1, true), apperrors.ReviewNotDisputedErr)
+		assert.NoError(t, service.ResolveDispute(ctx, admin, 3, true))
+	})
+}
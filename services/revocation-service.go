@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"fmt"
+	"time"
+	"ypeskov/go_hillel_9/internal/cache"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// RevocationService decides if a signed and not expired token may still be used.
+// Lookups are cached: a revocation is seen at once by the instance that made it
+// and by the other instances after at most RevocationCacheTtlSeconds.
+type RevocationService struct {
+	log            *log.Logger
+	cfg            *config.Config
+	revocationRepo repositories.RevocationRepositoryInterface
+	sessionRepo    repositories.SessionRepositoryInterface
+	revoked        *cache.TTLCache[string, bool]
+}
+
+type RevocationServiceInterface interface {
+	IsRevoked(ctx context.Context, claims *Claims, user *models.User) (bool, error)
+	RevokeToken(ctx context.Context, claims *Claims) error
+	RevokeAllUserTokens(ctx context.Context, userId int) error
+	SessionRevoked(ctx context.Context, sessionId int)
+}
+
+func GetRevocationService(revocationRepo repositories.RevocationRepositoryInterface,
+	sessionRepo repositories.SessionRepositoryInterface,
+	log *log.Logger, cfg *config.Config) RevocationServiceInterface {
+
+	ttl := time.Duration(cfg.RevocationCacheTtlSeconds) * time.Second
+
+	return &RevocationService{
+		log:            log,
+		cfg:            cfg,
+		revocationRepo: revocationRepo,
+		sessionRepo:    sessionRepo,
+		revoked:        cache.New[string, bool](ttl),
+	}
+}
+
+func (rs *RevocationService) IsRevoked(ctx context.Context, claims *Claims, user *models.User) (bool, error) {
+	if user.TokensValidAfter != nil &&
+		(claims.IssuedAt == nil || claims.IssuedAt.Time.Before(*user.TokensValidAfter)) {
+		return true, nil
+	}
+
+	revoked, err := rs.isTokenRevoked(ctx, claims.ID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	return rs.isSessionRevoked(ctx, claims.SessionId)
+}
+
+func (rs *RevocationService) RevokeToken(ctx context.Context, claims *Claims) error {
+	expiresAt := time.Now().UTC()
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	err := rs.revocationRepo.AddRevokedToken(ctx, claims.ID, claims.Id, expiresAt)
+	if err != nil {
+		return err
+	}
+	// revocation is permanent, so the entry may live until the token expires
+	rs.revoked.SetWithTTL(jtiKey(claims.ID), true, time.Until(expiresAt))
+
+	err = rs.revocationRepo.DeleteExpiredRevokedTokens(ctx)
+	if err != nil {
+		rs.log.Errorln("failed to delete expired revoked tokens", err)
+	}
+
+	return nil
+}
+
+// RevokeAllUserTokens rejects every token issued to the user so far, e.g. when the user is banned
+func (rs *RevocationService) RevokeAllUserTokens(ctx context.Context, userId int) error {
+	// iat has a precision of one second, tokens issued during the current second are rejected too
+	validAfter := time.Now().UTC().Truncate(time.Second).Add(time.Second)
+
+	err := rs.revocationRepo.SetTokensValidAfter(ctx, userId, validAfter)
+	if err != nil {
+		return err
+	}
+
+	return rs.sessionRepo.RevokeAllSessions(ctx, userId)
+}
+
+// SessionRevoked must be called after a session is revoked so its access tokens stop working at once
+func (rs *RevocationService) SessionRevoked(ctx context.Context, sessionId int) {
+	lifetime := time.Duration(rs.cfg.AccessTokenLifetimeMinutes) * time.Minute
+	rs.revoked.SetWithTTL(sessionKey(sessionId), true, lifetime)
+}
+
+func (rs *RevocationService) isTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	if revoked, ok := rs.revoked.Get(jtiKey(jti)); ok {
+		return revoked, nil
+	}
+
+	revoked, err := rs.revocationRepo.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+	rs.revoked.Set(jtiKey(jti), revoked)
+
+	return revoked, nil
+}
+
+func (rs *RevocationService) isSessionRevoked(ctx context.Context, sessionId int) (bool, error) {
+	if revoked, ok := rs.revoked.Get(sessionKey(sessionId)); ok {
+		return revoked, nil
+	}
+
+	revoked := false
+	session, err := rs.sessionRepo.GetSessionById(ctx, sessionId)
+	if err != nil {
+		if !goErrors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+		revoked = true
+	} else {
+		revoked = session.RevokedAt != nil
+	}
+	rs.revoked.Set(sessionKey(sessionId), revoked)
+
+	return revoked, nil
+}
+
+func jtiKey(jti string) string {
+	return "jti:" + jti
+}
+
+func sessionKey(sessionId int) string {
+	return fmt.Sprintf("sid:%d", sessionId)
+}
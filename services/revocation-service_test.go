@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestIsRevoked(t *testing.T) {
+	ctx := context.Background()
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+
+	issuedAt := time.Now().Add(-time.Minute)
+	claimsFor := func(jti string, sessionId int) *Claims {
+		return &Claims{
+			Id:        1,
+			SessionId: sessionId,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:       jti,
+				IssuedAt: jwt.NewNumericDate(issuedAt),
+			},
+		}
+	}
+	validAfter := time.Now()
+
+	tests := []struct {
+		name       string
+		claims     *Claims
+		user       *models.User
+		expected   bool
+		mockReturn func(*mocks.RevocationRepositoryInterface, *mocks.SessionRepositoryInterface)
+	}{
+		{
+			name:     "Active token",
+			claims:   claimsFor("active", 1),
+			user:     &models.User{Id: 1},
+			expected: false,
+			mockReturn: func(rr *mocks.RevocationRepositoryInterface, sr *mocks.SessionRepositoryInterface) {
+				rr.On("IsTokenRevoked", mock.Anything, "active").Return(false, nil)
+				sr.On("GetSessionById", mock.Anything, 1).Return(&models.Session{Id: 1}, nil)
+			},
+		},
+		{
+			name:     "Token in revocation list",
+			claims:   claimsFor("revoked", 1),
+			user:     &models.User{Id: 1},
+			expected: true,
+			mockReturn: func(rr *mocks.RevocationRepositoryInterface, sr *mocks.SessionRepositoryInterface) {
+				rr.On("IsTokenRevoked", mock.Anything, "revoked").Return(true, nil)
+			},
+		},
+		{
+			name:     "Session revoked",
+			claims:   claimsFor("active", 2),
+			user:     &models.User{Id: 1},
+			expected: true,
+			mockReturn: func(rr *mocks.RevocationRepositoryInterface, sr *mocks.SessionRepositoryInterface) {
+				rr.On("IsTokenRevoked", mock.Anything, "active").Return(false, nil)
+				sr.On("GetSessionById", mock.Anything, 2).Return(&models.Session{Id: 2, RevokedAt: &validAfter}, nil)
+			},
+		},
+		{
+			name:     "Session deleted",
+			claims:   claimsFor("active", 3),
+			user:     &models.User{Id: 1},
+			expected: true,
+			mockReturn: func(rr *mocks.RevocationRepositoryInterface, sr *mocks.SessionRepositoryInterface) {
+				rr.On("IsTokenRevoked", mock.Anything, "active").Return(false, nil)
+				sr.On("GetSessionById", mock.Anything, 3).Return(nil, sql.ErrNoRows)
+			},
+		},
+		{
+			name:       "Issued before all user tokens were revoked",
+			claims:     claimsFor("active", 1),
+			user:       &models.User{Id: 1, TokensValidAfter: &validAfter},
+			expected:   true,
+			mockReturn: func(rr *mocks.RevocationRepositoryInterface, sr *mocks.SessionRepositoryInterface) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRevocationRepo := new(mocks.RevocationRepositoryInterface)
+			mockSessionRepo := new(mocks.SessionRepositoryInterface)
+			tt.mockReturn(mockRevocationRepo, mockSessionRepo)
+
+			service := GetRevocationService(mockRevocationRepo, mockSessionRepo, mockLog, mockCfg)
+
+			revoked, err := service.IsRevoked(ctx, tt.claims, tt.user)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, revoked)
+		})
+	}
+}
+
+func TestIsRevokedIsCached(t *testing.T) {
+	ctx := context.Background()
+	mockRevocationRepo := new(mocks.RevocationRepositoryInterface)
+	mockSessionRepo := new(mocks.SessionRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+
+	service := GetRevocationService(mockRevocationRepo, mockSessionRepo, mockLog, mockCfg)
+
+	mockRevocationRepo.On("IsTokenRevoked", mock.Anything, "jti").Return(false, nil).Once()
+	mockSessionRepo.On("GetSessionById", mock.Anything, 1).Return(&models.Session{Id: 1}, nil).Once()
+
+	claims := &Claims{Id: 1, SessionId: 1, RegisteredClaims: jwt.RegisteredClaims{ID: "jti"}}
+	for range 3 {
+		revoked, err := service.IsRevoked(ctx, claims, &models.User{Id: 1})
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	}
+	mockRevocationRepo.AssertExpectations(t)
+	mockSessionRepo.AssertExpectations(t)
+
+	mockRevocationRepo.On("AddRevokedToken", mock.Anything, "jti", 1, mock.Anything).Return(nil)
+	mockRevocationRepo.On("DeleteExpiredRevokedTokens", mock.Anything, mock.Anything).Return(nil)
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute))
+
+	assert.NoError(t, service.RevokeToken(ctx, claims))
+
+	revoked, err := service.IsRevoked(ctx, claims, &models.User{Id: 1})
+	assert.NoError(t, err)
+	assert.True(t, revoked, "revocation must be seen at once despite the cached result")
+}
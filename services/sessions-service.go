@@ -0,0 +1,458 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/jwtkeys"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/password"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+type SessionsService struct {
+	log           *log.Logger
+	cfg           *config.Config
+	userRepo      repositories.UserRepositoryInterface
+	sessionRepo   repositories.SessionRepositoryInterface
+	revocation    RevocationServiceInterface
+	loginAttempts LoginAttemptsServiceInterface
+	mfa           MfaServiceInterface
+	keys          *jwtkeys.KeySet
+	hasher        *password.Hasher
+	// unknown emails are checked against this hash so they take as long as wrong passwords
+	dummyPasswordHash string
+}
+
+type Claims struct {
+	Id        int    `json:"id"`
+	Email     string `json:"email"`
+	SessionId int    `json:"sid"`
+	TokenUse  string `json:"token_use"`
+	// set when the role of the user requires two-factor authentication that is not enabled yet
+	MfaEnrollmentRequired bool `json:"mfa_enroll,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// values of the token_use claim, a refresh token must not be accepted as an access token and vice versa
+const (
+	AccessTokenUse       = "access"
+	RefreshTokenUse      = "refresh"
+	MfaChallengeTokenUse = "mfa"
+)
+
+// SessionClient describes the device a session was opened from
+type SessionClient struct {
+	Device    string
+	UserAgent string
+	Ip        string
+}
+
+type TokenPair struct {
+	AccessToken           string
+	RefreshToken          string
+	MfaEnrollmentRequired bool
+}
+
+// LoginResult holds either tokens or, for accounts with two-factor authentication,
+// a challenge token to be exchanged for tokens together with a one-time code
+type LoginResult struct {
+	Tokens   *TokenPair
+	MfaToken string
+}
+
+type SessionsServiceInterface interface {
+	Login(ctx context.Context, email string, password string, client SessionClient) (*LoginResult, error)
+	LoginUser(ctx context.Context, user *models.User, client SessionClient) (*LoginResult, error)
+	VerifyMfa(ctx context.Context, mfaToken string, code string, client SessionClient) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string, client SessionClient) (*TokenPair, error)
+	GetSessionsList(ctx context.Context, userId int, currentSessionId int) ([]*models.Session, error)
+	RevokeSession(ctx context.Context, userId int, sessionId int) error
+	RevokeOtherSessions(ctx context.Context, userId int, currentSessionId int) error
+	Logout(ctx context.Context, claims *Claims) error
+}
+
+const tokenIdBytes = 16
+
+func GetSessionsService(userRepo repositories.UserRepositoryInterface,
+	sessionRepo repositories.SessionRepositoryInterface,
+	revocation RevocationServiceInterface, loginAttempts LoginAttemptsServiceInterface,
+	mfa MfaServiceInterface, keys *jwtkeys.KeySet, hasher *password.Hasher,
+	log *log.Logger, cfg *config.Config) SessionsServiceInterface {
+
+	dummyPasswordHash, err := hasher.Hash("dummy password")
+	if err != nil {
+		log.Errorln("failed to hash dummy password", err)
+	}
+
+	return &SessionsService{
+		log:           log,
+		cfg:           cfg,
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		revocation:    revocation,
+		loginAttempts: loginAttempts,
+		mfa:           mfa,
+		keys:          keys,
+		hasher:        hasher,
+
+		dummyPasswordHash: dummyPasswordHash,
+	}
+}
+
+func (ss *SessionsService) Login(ctx context.Context, email string, plainPassword string,
+	client SessionClient) (*LoginResult, error) {
+	err := ss.loginAttempts.CheckAllowed(ctx, email, client.Ip)
+	if err != nil {
+		return nil, err
+	}
+
+	user := ss.userRepo.GetUserByEmail(ctx, email)
+
+	passwordHash := ss.dummyPasswordHash
+	if user != nil {
+		passwordHash = user.PasswordHash
+	}
+	match, needsRehash := ss.hasher.Verify(plainPassword, passwordHash)
+	if !match || user == nil {
+		err = ss.loginAttempts.RecordFailure(ctx, email, client.Ip)
+		if err != nil {
+			ss.log.Errorln("failed to record login failure", err)
+		}
+
+		return nil, errors.UnauthorizedErr
+	}
+
+	if needsRehash {
+		ss.rehashPassword(ctx, user, plainPassword)
+	}
+
+	return ss.LoginUser(ctx, user, client)
+}
+
+// LoginUser logs in a user who was already authenticated, by a password or by an identity provider.
+// Two-factor authentication is still required if the user enabled it.
+func (ss *SessionsService) LoginUser(ctx context.Context, user *models.User,
+	client SessionClient) (*LoginResult, error) {
+	err := CheckAccountStatus(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MfaEnabled {
+		lifetime := time.Duration(ss.cfg.MfaChallengeLifetimeMinutes) * time.Minute
+		mfaToken, err := ss.signToken(user, 0, MfaChallengeTokenUse, false, lifetime)
+		if err != nil {
+			return nil, err
+		}
+
+		return &LoginResult{MfaToken: mfaToken}, nil
+	}
+
+	tokens, err := ss.openSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// VerifyMfa is the second step of the login of accounts with two-factor authentication.
+// Wrong codes count as failed logins, so they are throttled the same way as passwords.
+func (ss *SessionsService) VerifyMfa(ctx context.Context, mfaToken string, code string,
+	client SessionClient) (*TokenPair, error) {
+	claims, err := ss.parseToken(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != MfaChallengeTokenUse {
+		ss.log.Errorln("token is not an mfa challenge token")
+
+		return nil, errors.InvalidTokenTypeErr
+	}
+
+	err = ss.loginAttempts.CheckAllowed(ctx, claims.Email, client.Ip)
+	if err != nil {
+		return nil, err
+	}
+
+	user := ss.userRepo.GetUserByEmail(ctx, claims.Email)
+	if user == nil || user.Id != claims.Id {
+		return nil, errors.UnauthorizedErr
+	}
+
+	err = CheckAccountStatus(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := ss.mfa.VerifyCode(ctx, user, code)
+	if err != nil {
+		return nil, errors.InternalServerErr
+	}
+	if !ok {
+		err = ss.loginAttempts.RecordFailure(ctx, claims.Email, client.Ip)
+		if err != nil {
+			ss.log.Errorln("failed to record login failure", err)
+		}
+
+		return nil, errors.InvalidMfaCodeErr
+	}
+
+	return ss.openSession(ctx, user, client)
+}
+
+// Refresh rotates the refresh token of a session. Every refresh token can be used once,
+// presenting a used one means it was stolen, so the whole session is revoked.
+func (ss *SessionsService) Refresh(ctx context.Context, refreshToken string, client SessionClient) (*TokenPair, error) {
+	claims, err := ss.parseToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != RefreshTokenUse {
+		ss.log.Errorln("token is not a refresh token")
+
+		return nil, errors.InvalidTokenTypeErr
+	}
+
+	storedToken, err := ss.sessionRepo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, errors.UnauthorizedErr
+	}
+
+	session, err := ss.sessionRepo.GetSessionById(ctx, storedToken.SessionId)
+	if err != nil || session.RevokedAt != nil || session.UserId != claims.Id {
+		ss.log.Errorln("session of refresh token is revoked or does not match the token")
+
+		return nil, errors.UnauthorizedErr
+	}
+
+	fresh, err := ss.sessionRepo.MarkRefreshTokenUsed(ctx, storedToken.Id)
+	if err != nil {
+		return nil, errors.InternalServerErr
+	}
+	if !fresh {
+		ss.log.Warnf("Refresh token reuse detected, revoking session %d of user %d", session.Id, session.UserId)
+		err = ss.RevokeSession(ctx, session.UserId, session.Id)
+		if err != nil {
+			ss.log.Errorln("failed to revoke session", err)
+		}
+
+		return nil, errors.UnauthorizedErr
+	}
+
+	user := ss.userRepo.GetUserByEmail(ctx, claims.Email)
+	if user == nil || user.Id != session.UserId {
+		ss.log.Errorln("user in token is not the same as in DB")
+
+		return nil, errors.UnauthorizedErr
+	}
+
+	err = ss.sessionRepo.TouchSession(ctx, session.Id, client.Ip, client.UserAgent)
+	if err != nil {
+		return nil, errors.InternalServerErr
+	}
+
+	return ss.issueTokens(ctx, user, session.Id)
+}
+
+func (ss *SessionsService) GetSessionsList(ctx context.Context, userId int,
+	currentSessionId int) ([]*models.Session, error) {
+	sessions, err := ss.sessionRepo.GetActiveSessionsList(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = session.Id == currentSessionId
+	}
+
+	return sessions, nil
+}
+
+func (ss *SessionsService) RevokeSession(ctx context.Context, userId int, sessionId int) error {
+	err := ss.sessionRepo.RevokeSession(ctx, sessionId, userId)
+	if err != nil {
+		return err
+	}
+	ss.revocation.SessionRevoked(ctx, sessionId)
+
+	return nil
+}
+
+func (ss *SessionsService) RevokeOtherSessions(ctx context.Context, userId int, currentSessionId int) error {
+	sessions, err := ss.sessionRepo.GetActiveSessionsList(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	err = ss.sessionRepo.RevokeOtherSessions(ctx, userId, currentSessionId)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.Id != currentSessionId {
+			ss.revocation.SessionRevoked(ctx, session.Id)
+		}
+	}
+
+	return nil
+}
+
+// Logout revokes the session of the access token and the access token itself
+func (ss *SessionsService) Logout(ctx context.Context, claims *Claims) error {
+	err := ss.RevokeSession(ctx, claims.Id, claims.SessionId)
+	if err != nil && !goErrors.Is(err, errors.NotFoundErr) {
+		return err
+	}
+
+	return ss.revocation.RevokeToken(ctx, claims)
+}
+
+// rehashPassword upgrades bcrypt hashes and Argon2id hashes made with old parameters.
+// The login succeeds even if the new hash can't be saved, it is tried again next time.
+func (ss *SessionsService) rehashPassword(ctx context.Context, user *models.User, plainPassword string) {
+	hash, err := ss.hasher.Hash(plainPassword)
+	if err != nil {
+		ss.log.Errorln("failed to rehash password", err)
+
+		return
+	}
+
+	err = ss.userRepo.UpdatePasswordHash(ctx, user.Id, hash)
+	if err != nil {
+		ss.log.Errorln("failed to save rehashed password", err)
+
+		return
+	}
+	user.PasswordHash = hash
+
+	ss.log.Infof("Password hash of user %d upgraded", user.Id)
+}
+
+func (ss *SessionsService) openSession(ctx context.Context, user *models.User,
+	client SessionClient) (*TokenPair, error) {
+	err := ss.loginAttempts.RecordSuccess(ctx, user.Email)
+	if err != nil {
+		ss.log.Errorln("failed to reset login failures", err)
+	}
+
+	session, err := ss.sessionRepo.CreateSession(ctx, &models.Session{
+		UserId:    user.Id,
+		Device:    client.Device,
+		UserAgent: client.UserAgent,
+		Ip:        client.Ip,
+	})
+	if err != nil {
+		ss.log.Errorln("failed to create session", err)
+
+		return nil, errors.InternalServerErr
+	}
+
+	return ss.issueTokens(ctx, user, session.Id)
+}
+
+func (ss *SessionsService) issueTokens(ctx context.Context, user *models.User, sessionId int) (*TokenPair, error) {
+	// checked again here, a refresh must not outlive a suspension
+	err := CheckAccountStatus(user)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollmentRequired, err := ss.mfaEnrollmentRequired(ctx, user)
+	if err != nil {
+		return nil, errors.InternalServerErr
+	}
+
+	accessLifetime := time.Duration(ss.cfg.AccessTokenLifetimeMinutes) * time.Minute
+	accessToken, err := ss.signToken(user, sessionId, AccessTokenUse, enrollmentRequired, accessLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshLifetime := time.Duration(ss.cfg.RefreshTokenLifetimeMinutes) * time.Minute
+	refreshToken, err := ss.signToken(user, sessionId, RefreshTokenUse, enrollmentRequired, refreshLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ss.sessionRepo.AddRefreshToken(ctx, sessionId, hashToken(refreshToken), time.Now().UTC().Add(refreshLifetime))
+	if err != nil {
+		ss.log.Errorln("failed to add refresh token", err)
+
+		return nil, errors.InternalServerErr
+	}
+
+	return &TokenPair{
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		MfaEnrollmentRequired: enrollmentRequired,
+	}, nil
+}
+
+func (ss *SessionsService) mfaEnrollmentRequired(ctx context.Context, user *models.User) (bool, error) {
+	if user.MfaEnabled {
+		return false, nil
+	}
+
+	userType, err := ss.userRepo.GetUserType(ctx, user)
+	if err != nil {
+		ss.log.Errorln("failed to get user type", err)
+
+		return false, err
+	}
+
+	return userType.MfaRequired, nil
+}
+
+func (ss *SessionsService) signToken(user *models.User, sessionId int, tokenUse string,
+	mfaEnrollmentRequired bool, lifetime time.Duration) (string, error) {
+	tokenId, err := generateToken(tokenIdBytes)
+	if err != nil {
+		return "", errors.InternalServerErr
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		Id:        user.Id,
+		Email:     user.Email,
+		SessionId: sessionId,
+		TokenUse:  tokenUse,
+
+		MfaEnrollmentRequired: mfaEnrollmentRequired,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(lifetime)),
+		},
+	}
+
+	tokenString, err := ss.keys.Sign(claims)
+	if err != nil {
+		ss.log.Errorln("failed to sign token: ", err)
+
+		return "", errors.InternalServerErr
+	}
+
+	return tokenString, nil
+}
+
+func (ss *SessionsService) parseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := ss.keys.ParseWithClaims(tokenString, claims)
+	if err != nil {
+		if goErrors.Is(err, jwt.ErrTokenExpired) {
+			return nil, errors.TokenExpiredErr
+		}
+		ss.log.Errorln("failed to parse token", err)
+
+		return nil, errors.UnauthorizedErr
+	}
+
+	return claims, nil
+}
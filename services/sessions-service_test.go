@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/jwtkeys"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/password"
+	"ypeskov/go_hillel_9/internal/totp"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	mockUserRepo := new(mocks.UserRepositoryInterface)
+	mockSessionRepo := new(mocks.SessionRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	keys, _ := jwtkeys.Load(mockCfg)
+	hasher, _ := password.NewHasher(mockCfg)
+
+	revocation := GetRevocationService(new(mocks.RevocationRepositoryInterface), mockSessionRepo, mockLog, mockCfg)
+	mockLoginAttemptRepo := new(mocks.LoginAttemptRepositoryInterface)
+	mockLoginAttemptRepo.On("GetLoginFailure", mock.Anything, mock.Anything, mock.Anything).Return(nil,
+		apperrors.NotFoundErr)
+	mockLoginAttemptRepo.On("AddLoginFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.LoginFailure{Failures: 1}, nil)
+	mockLoginAttemptRepo.On("ResetLoginFailures", mock.Anything, models.LoginSubjectAccount,
+		"example@example.com").Return(nil)
+	loginAttempts := GetLoginAttemptsService(mockLoginAttemptRepo, mockLog, mockCfg)
+	mockMfaRepo := new(mocks.MfaRepositoryInterface)
+	mfa := GetMfaService(mockMfaRepo, mockLog, mockCfg)
+	service := GetSessionsService(mockUserRepo, mockSessionRepo, revocation, loginAttempts, mfa,
+		keys, hasher, mockLog, mockCfg)
+
+	hash, _ := hasher.Hash("password")
+	user := &models.User{Id: 1, Email: "example@example.com", PasswordHash: hash}
+	client := SessionClient{Device: "phone", UserAgent: "test", Ip: "127.0.0.1"}
+
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "example@example.com").Return(user)
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "unknown@example.com").Return(nil)
+	mockUserRepo.On("GetUserType", mock.Anything, user).Return(&models.UserType{MfaRequired: true}, nil)
+
+	t.Run("Wrong password", func(t *testing.T) {
+		result, err := service.Login(ctx, "example@example.com", "wrong", client)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, apperrors.UnauthorizedErr)
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		result, err := service.Login(ctx, "unknown@example.com", "password", client)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, apperrors.UnauthorizedErr)
+		mockLoginAttemptRepo.AssertCalled(t, "AddLoginFailure",
+			mock.Anything, models.LoginSubjectAccount, "unknown@example.com", mock.Anything)
+	})
+
+	t.Run("Opens a new session", func(t *testing.T) {
+		mockSessionRepo.On("CreateSession", mock.Anything, mock.MatchedBy(func(s *models.Session) bool {
+			return s.UserId == 1 && s.Device == "phone" && s.Ip == "127.0.0.1"
+		})).Return(&models.Session{Id: 10, UserId: 1}, nil)
+		mockSessionRepo.On("AddRefreshToken", mock.Anything, 10, mock.Anything, mock.Anything).Return(nil)
+
+		result, err := service.Login(ctx, "example@example.com", "password", client)
+		assert.NoError(t, err)
+		assert.Empty(t, result.MfaToken)
+		assert.NotEmpty(t, result.Tokens.AccessToken)
+		assert.NotEmpty(t, result.Tokens.RefreshToken)
+		assert.NotEqual(t, result.Tokens.AccessToken, result.Tokens.RefreshToken)
+		assert.True(t, result.Tokens.MfaEnrollmentRequired)
+		mockSessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Upgrades a bcrypt hash", func(t *testing.T) {
+		bcryptHash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+		oldUser := &models.User{Id: 3, Email: "old@example.com", PasswordHash: string(bcryptHash)}
+		mockUserRepo.On("GetUserByEmail", mock.Anything, "old@example.com").Return(oldUser)
+		mockUserRepo.On("GetUserType", mock.Anything, oldUser).Return(&models.UserType{}, nil)
+		mockLoginAttemptRepo.On("ResetLoginFailures", mock.Anything, models.LoginSubjectAccount,
+			"old@example.com").Return(nil)
+		mockSessionRepo.On("CreateSession", mock.Anything, mock.MatchedBy(func(s *models.Session) bool {
+			return s.UserId == 3
+		})).Return(&models.Session{Id: 12, UserId: 3}, nil)
+		mockSessionRepo.On("AddRefreshToken", mock.Anything, 12, mock.Anything, mock.Anything).Return(nil)
+
+		var newHash string
+		mockUserRepo.On("UpdatePasswordHash", mock.Anything, 3, mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { newHash = args.String(2) }).Return(nil)
+
+		_, err := service.Login(ctx, "old@example.com", "password", client)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(newHash, "$argon2id$"))
+
+		match, needsRehash := hasher.Verify("password", newHash)
+		assert.True(t, match)
+		assert.False(t, needsRehash)
+	})
+
+	t.Run("Requires a one-time code when mfa is enabled", func(t *testing.T) {
+		secret, _ := totp.GenerateSecret()
+		mfaUser := &models.User{Id: 2, Email: "mfa@example.com", PasswordHash: hash, MfaEnabled: true,
+			MfaSecret: &secret}
+		mockUserRepo.On("GetUserByEmail", mock.Anything, "mfa@example.com").Return(mfaUser)
+		mockUserRepo.On("GetUserType", mock.Anything, mfaUser).Return(&models.UserType{MfaRequired: true}, nil)
+		mockLoginAttemptRepo.On("ResetLoginFailures", mock.Anything, models.LoginSubjectAccount,
+			"mfa@example.com").Return(nil)
+
+		result, err := service.Login(ctx, "mfa@example.com", "password", client)
+		assert.NoError(t, err)
+		assert.Nil(t, result.Tokens)
+		assert.NotEmpty(t, result.MfaToken)
+
+		mockMfaRepo.On("UseRecoveryCode", mock.Anything, 2, mock.Anything).Return(false, nil)
+		tokens, err := service.VerifyMfa(ctx, result.MfaToken, "0000-0000", client)
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, apperrors.InvalidMfaCodeErr)
+
+		mockMfaRepo.On("UseMfaStep", mock.Anything, 2, mock.Anything).Return(true, nil)
+		mockSessionRepo.On("CreateSession", mock.Anything, mock.MatchedBy(func(s *models.Session) bool {
+			return s.UserId == 2
+		})).Return(&models.Session{Id: 11, UserId: 2}, nil)
+		mockSessionRepo.On("AddRefreshToken", mock.Anything, 11, mock.Anything, mock.Anything).Return(nil)
+
+		code, _ := totp.Code(secret, time.Now())
+		tokens, err = service.VerifyMfa(ctx, result.MfaToken, code, client)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.False(t, tokens.MfaEnrollmentRequired)
+
+		_, err = service.Refresh(ctx, result.MfaToken, client)
+		assert.ErrorIs(t, err, apperrors.InvalidTokenTypeErr)
+	})
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	ctx := context.Background()
+	mockUserRepo := new(mocks.UserRepositoryInterface)
+	mockSessionRepo := new(mocks.SessionRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	keys, _ := jwtkeys.Load(mockCfg)
+	hasher, _ := password.NewHasher(mockCfg)
+
+	mockRevocationRepo := new(mocks.RevocationRepositoryInterface)
+	mockRevocationRepo.On("IsTokenRevoked", mock.Anything, mock.Anything).Return(false, nil)
+	revocation := GetRevocationService(mockRevocationRepo, mockSessionRepo, mockLog, mockCfg)
+	service := GetSessionsService(mockUserRepo, mockSessionRepo, revocation,
+		GetLoginAttemptsService(new(mocks.LoginAttemptRepositoryInterface), mockLog, mockCfg),
+		GetMfaService(new(mocks.MfaRepositoryInterface), mockLog, mockCfg),
+		keys, hasher, mockLog, mockCfg).(*SessionsService)
+
+	user := &models.User{Id: 1, Email: "example@example.com"}
+	refreshToken, err := service.signToken(user, 10, RefreshTokenUse, false, time.Hour)
+	assert.NoError(t, err)
+
+	t.Run("Access token can't be used to refresh", func(t *testing.T) {
+		accessToken, err := service.signToken(user, 10, AccessTokenUse, false, time.Hour)
+		assert.NoError(t, err)
+
+		tokens, err := service.Refresh(ctx, accessToken, SessionClient{})
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, apperrors.InvalidTokenTypeErr)
+	})
+
+	mockSessionRepo.On("GetRefreshToken", mock.Anything, hashToken(refreshToken)).
+		Return(&models.RefreshToken{Id: 5, SessionId: 10}, nil)
+	mockSessionRepo.On("GetSessionById", mock.Anything, 10).Return(&models.Session{Id: 10, UserId: 1}, nil)
+	mockSessionRepo.On("MarkRefreshTokenUsed", mock.Anything, 5).Return(false, nil)
+	mockSessionRepo.On("RevokeSession", mock.Anything, 10, 1).Return(nil)
+
+	tokens, err := service.Refresh(ctx, refreshToken, SessionClient{})
+	assert.Nil(t, tokens)
+	assert.ErrorIs(t, err, apperrors.UnauthorizedErr)
+	mockSessionRepo.AssertCalled(t, "RevokeSession", mock.Anything, 10, 1)
+
+	revoked, err := revocation.IsRevoked(ctx, &Claims{Id: 1, SessionId: 10}, user)
+	assert.NoError(t, err)
+	assert.True(t, revoked, "access tokens of the revoked session must stop working")
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories"
+)
+
+// UserAdminService lets administrators find users and suspend, ban or promote them.
+// Every change is written to the moderation log.
+type UserAdminService struct {
+	log          *log.Logger
+	cfg          *config.Config
+	adminRepo    repositories.UserAdminRepositoryInterface
+	itemRepo     repositories.ItemRepositoryInterface
+	userTypeRepo repositories.UserTypeRepositoryInterface
+	unitOfWork   repositories.UnitOfWorkInterface
+	revocation   RevocationServiceInterface
+}
+
+type UsersPage struct {
+	Users   []*models.User `json:"users"`
+	Total   int            `json:"total"`
+	Page    int            `json:"page"`
+	PerPage int            `json:"perPage"`
+}
+
+type UserDetails struct {
+	User             *models.User                  `json:"user"`
+	Items            []*models.Item                `json:"items"`
+	Activity         *models.UserActivity          `json:"activity"`
+	ModerationEvents []*models.UserModerationEvent `json:"moderationEvents"`
+}
+
+type UserAdminServiceInterface interface {
+	SearchUsers(ctx context.Context, query string, page int, perPage int) (*UsersPage, error)
+	GetUserDetails(ctx context.Context, id int) (*UserDetails, error)
+	SuspendUser(ctx context.Context, admin *models.User, userId int, until time.Time, reason string) error
+	BanUser(ctx context.Context, admin *models.User, userId int, reason string) error
+	ReactivateUser(ctx context.Context, admin *models.User, userId int, reason string) error
+	ChangeUserType(ctx context.Context, admin *models.User, userId int, typeCode string) error
+}
+
+const (
+	defaultUsersPerPage = 20
+	maxUsersPerPage     = 100
+)
+
+func GetUserAdminService(adminRepo repositories.UserAdminRepositoryInterface,
+	itemRepo repositories.ItemRepositoryInterface, userTypeRepo repositories.UserTypeRepositoryInterface,
+	unitOfWork repositories.UnitOfWorkInterface, revocation RevocationServiceInterface, log *log.Logger,
+	cfg *config.Config) UserAdminServiceInterface {
+
+	return &UserAdminService{
+		log:          log,
+		cfg:          cfg,
+		adminRepo:    adminRepo,
+		itemRepo:     itemRepo,
+		userTypeRepo: userTypeRepo,
+		unitOfWork:   unitOfWork,
+		revocation:   revocation,
+	}
+}
+
+func (uas *UserAdminService) SearchUsers(ctx context.Context, query string, page int, perPage int) (*UsersPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultUsersPerPage
+	}
+	perPage = min(perPage, maxUsersPerPage)
+
+	users, total, err := uas.adminRepo.SearchUsers(ctx, query, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		user.PasswordHash = ""
+	}
+
+	return &UsersPage{
+		Users:   users,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+func (uas *UserAdminService) GetUserDetails(ctx context.Context, id int) (*UserDetails, error) {
+	user, err := uas.adminRepo.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = ""
+
+	items, err := uas.itemRepo.GetItemsList(ctx, models.ItemsFilter{UserId: id})
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := uas.adminRepo.GetUserActivity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := uas.adminRepo.GetModerationEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserDetails{
+		User:             user,
+		Items:            items,
+		Activity:         activity,
+		ModerationEvents: events,
+	}, nil
+}
+
+// SuspendUser blocks the user until the given time, the user's tokens stop working right away
+func (uas *UserAdminService) SuspendUser(ctx context.Context, admin *models.User, userId int, until time.Time,
+	reason string) error {
+	if !until.After(time.Now()) {
+		return errors.InvalidSuspensionErr
+	}
+	until = until.UTC()
+
+	return uas.setStatus(ctx, admin, userId, models.UserStatusSuspended, &until, reason,
+		models.ModerationActionSuspend, "until "+until.Format(time.RFC3339))
+}
+
+func (uas *UserAdminService) BanUser(ctx context.Context, admin *models.User, userId int, reason string) error {
+	return uas.setStatus(ctx, admin, userId, models.UserStatusBanned, nil, reason, models.ModerationActionBan, "")
+}
+
+func (uas *UserAdminService) ReactivateUser(ctx context.Context, admin *models.User, userId int, reason string) error {
+	return uas.setStatus(ctx, admin, userId, models.UserStatusActive, nil, reason, models.ModerationActionReactivate,
+		"")
+}
+
+// ChangeUserType gives the user another type. admin is nil when the change is made from the command line.
+func (uas *UserAdminService) ChangeUserType(ctx context.Context, admin *models.User, userId int,
+	typeCode string) error {
+	var adminId *int
+	changedBy := "the command line"
+	if admin != nil {
+		if admin.Id == userId {
+			return errors.CannotModerateSelfErr
+		}
+		adminId = &admin.Id
+		changedBy = fmt.Sprintf("admin %d", admin.Id)
+	}
+
+	userType, err := uas.userTypeRepo.GetUserTypeByCode(ctx, typeCode)
+	if err != nil {
+		return err
+	}
+	if userType.RetiredAt != nil {
+		return errors.UserTypeRetiredErr
+	}
+
+	err = uas.unitOfWork.InTransaction(ctx, func(ctx context.Context) error {
+		err := uas.adminRepo.SetUserType(ctx, userId, userType.Id)
+		if err != nil {
+			return err
+		}
+
+		return uas.adminRepo.AddModerationEvent(ctx, &models.UserModerationEvent{
+			UserId:  userId,
+			AdminId: adminId,
+			Action:  models.ModerationActionChangeRole,
+			Details: fmt.Sprintf("user type %s", typeCode),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	uas.log.Infof("User type of user %d changed to %s by %s", userId, typeCode, changedBy)
+
+	return nil
+}
+
+// setStatus changes the status and writes the moderation event in one transaction,
+// so the log never misses a change
+func (uas *UserAdminService) setStatus(ctx context.Context, admin *models.User, userId int, status string,
+	until *time.Time, reason string, action string, details string) error {
+	if admin.Id == userId {
+		return errors.CannotModerateSelfErr
+	}
+
+	err := uas.unitOfWork.InTransaction(ctx, func(ctx context.Context) error {
+		err := uas.adminRepo.SetUserStatus(ctx, userId, status, until, reason)
+		if err != nil {
+			return err
+		}
+
+		// AuthMiddleware rejects blocked users anyway, revoking also ends their sessions for good
+		if status != models.UserStatusActive {
+			err = uas.revocation.RevokeAllUserTokens(ctx, userId)
+			if err != nil {
+				return err
+			}
+		}
+
+		return uas.adminRepo.AddModerationEvent(ctx, &models.UserModerationEvent{
+			UserId:  userId,
+			AdminId: &admin.Id,
+			Action:  action,
+			Reason:  reason,
+			Details: details,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	uas.log.Infof("Status of user %d set to %s by admin %d", userId, status, admin.Id)
+
+	return nil
+}
+
+// CheckAccountStatus returns an error if the user is banned or currently suspended
+func CheckAccountStatus(user *models.User) error {
+	if user.IsBanned() {
+		return errors.AccountBannedErr
+	}
+	if user.IsSuspended(time.Now()) {
+		return errors.AccountSuspendedErr
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+// runningUnitOfWork calls fn right away, the way the real unit of work does inside a transaction
+func runningUnitOfWork() *mocks.UnitOfWorkInterface {
+	unitOfWork := new(mocks.UnitOfWorkInterface)
+	unitOfWork.On("InTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+
+	return unitOfWork
+}
+
+func TestSearchUsers(t *testing.T) {
+	ctx := context.Background()
+	mockAdminRepo := new(mocks.UserAdminRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetUserAdminService(mockAdminRepo, new(mocks.ItemRepositoryInterface),
+		new(mocks.UserTypeRepositoryInterface), nil, nil, mockLog, mockCfg)
+
+	mockAdminRepo.On("SearchUsers", mock.Anything, "john", maxUsersPerPage, maxUsersPerPage).
+		Return([]*models.User{{Id: 1, PasswordHash: "secret"}}, 101, nil)
+
+	result, err := service.SearchUsers(ctx, "john", 2, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, 101, result.Total)
+	assert.Equal(t, 2, result.Page)
+	assert.Equal(t, maxUsersPerPage, result.PerPage)
+	assert.Empty(t, result.Users[0].PasswordHash)
+
+	mockAdminRepo.On("SearchUsers", mock.Anything, "", defaultUsersPerPage, 0).Return([]*models.User{}, 0, nil)
+
+	result, err = service.SearchUsers(ctx, "", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, defaultUsersPerPage, result.PerPage)
+}
+
+func TestModerateUser(t *testing.T) {
+	ctx := context.Background()
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	admin := &models.User{Id: 1}
+
+	newService := func() (UserAdminServiceInterface, *mocks.UserAdminRepositoryInterface,
+		*mocks.RevocationRepositoryInterface, *mocks.SessionRepositoryInterface) {
+		unitOfWork := runningUnitOfWork()
+		mockAdminRepo := new(mocks.UserAdminRepositoryInterface)
+		mockRevocationRepo := new(mocks.RevocationRepositoryInterface)
+		mockSessionRepo := new(mocks.SessionRepositoryInterface)
+		revocation := GetRevocationService(mockRevocationRepo, mockSessionRepo, mockLog, mockCfg)
+		service := GetUserAdminService(mockAdminRepo, new(mocks.ItemRepositoryInterface),
+			new(mocks.UserTypeRepositoryInterface), unitOfWork, revocation, mockLog, mockCfg)
+
+		return service, mockAdminRepo, mockRevocationRepo, mockSessionRepo
+	}
+
+	t.Run("Cannot moderate self", func(t *testing.T) {
+		service, _, _, _ := newService()
+		assert.ErrorIs(t, service.BanUser(ctx, admin, admin.Id, "spam"), apperrors.CannotModerateSelfErr)
+		assert.ErrorIs(t, service.ChangeUserType(ctx, admin, admin.Id, "BUYER"), apperrors.CannotModerateSelfErr)
+	})
+
+	t.Run("Change of type from the command line", func(t *testing.T) {
+		mockAdminRepo := new(mocks.UserAdminRepositoryInterface)
+		mockTypeRepo := new(mocks.UserTypeRepositoryInterface)
+		service := GetUserAdminService(mockAdminRepo, new(mocks.ItemRepositoryInterface), mockTypeRepo,
+			runningUnitOfWork(), nil, mockLog, mockCfg)
+		mockTypeRepo.On("GetUserTypeByCode", mock.Anything, models.AdminTypeCode).
+			Return(&models.UserType{Id: 3, TypeCode: models.AdminTypeCode}, nil)
+		mockAdminRepo.On("SetUserType", mock.Anything, 2, 3).Return(nil)
+		mockAdminRepo.On("AddModerationEvent", mock.Anything, mock.MatchedBy(func(e *models.UserModerationEvent) bool {
+			return e.UserId == 2 && e.AdminId == nil && e.Action == models.ModerationActionChangeRole
+		})).Return(nil)
+
+		assert.NoError(t, service.ChangeUserType(ctx, nil, 2, models.AdminTypeCode))
+		mockAdminRepo.AssertExpectations(t)
+	})
+
+	t.Run("Suspension in the past", func(t *testing.T) {
+		service, _, _, _ := newService()
+		err := service.SuspendUser(ctx, admin, 2, time.Now().Add(-time.Hour), "spam")
+		assert.ErrorIs(t, err, apperrors.InvalidSuspensionErr)
+	})
+
+	t.Run("Ban revokes tokens and records the event", func(t *testing.T) {
+		service, mockAdminRepo, mockRevocationRepo, mockSessionRepo := newService()
+		mockAdminRepo.On("SetUserStatus", mock.Anything, 2, models.UserStatusBanned, (*time.Time)(nil),
+			"spam").Return(nil)
+		mockRevocationRepo.On("SetTokensValidAfter", mock.Anything, 2, mock.AnythingOfType("time.Time")).Return(nil)
+		mockSessionRepo.On("RevokeAllSessions", mock.Anything, 2).Return(nil)
+		mockAdminRepo.On("AddModerationEvent", mock.Anything, mock.MatchedBy(func(e *models.UserModerationEvent) bool {
+			return e.UserId == 2 && *e.AdminId == admin.Id && e.Action == models.ModerationActionBan
+		})).Return(nil)
+
+		assert.NoError(t, service.BanUser(ctx, admin, 2, "spam"))
+		mockRevocationRepo.AssertExpectations(t)
+		mockSessionRepo.AssertExpectations(t)
+		mockAdminRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reactivation keeps tokens", func(t *testing.T) {
+		service, mockAdminRepo, mockRevocationRepo, _ := newService()
+		mockAdminRepo.On("SetUserStatus", mock.Anything, 2, models.UserStatusActive, (*time.Time)(nil),
+			"appeal").Return(nil)
+		mockAdminRepo.On("AddModerationEvent", mock.Anything,
+			mock.AnythingOfType("*models.UserModerationEvent")).Return(nil)
+
+		assert.NoError(t, service.ReactivateUser(ctx, admin, 2, "appeal"))
+		mockRevocationRepo.AssertNotCalled(t, "SetTokensValidAfter", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		service, mockAdminRepo, _, _ := newService()
+		mockAdminRepo.On("SetUserStatus", mock.Anything, 3, models.UserStatusBanned, (*time.Time)(nil), "spam").
+			Return(apperrors.NotFoundErr)
+
+		assert.ErrorIs(t, service.BanUser(ctx, admin, 3, "spam"), apperrors.NotFoundErr)
+		mockAdminRepo.AssertNotCalled(t, "AddModerationEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed moderation event fails the change", func(t *testing.T) {
+		service, mockAdminRepo, _, _ := newService()
+		mockAdminRepo.On("SetUserStatus", mock.Anything, 2, models.UserStatusActive, (*time.Time)(nil),
+			"appeal").Return(nil)
+		mockAdminRepo.On("AddModerationEvent", mock.Anything, mock.Anything).Return(sql.ErrConnDone)
+
+		assert.ErrorIs(t, service.ReactivateUser(ctx, admin, 2, "appeal"), sql.ErrConnDone)
+	})
+}
+
+func TestCheckAccountStatus(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		user     *models.User
+		expected error
+	}{
+		{"Active", &models.User{Status: models.UserStatusActive}, nil},
+		{"Banned", &models.User{Status: models.UserStatusBanned}, apperrors.AccountBannedErr},
+		{"Suspended", &models.User{Status: models.UserStatusSuspended, SuspendedUntil: &future},
+			apperrors.AccountSuspendedErr},
+		{"Suspension is over", &models.User{Status: models.UserStatusSuspended, SuspendedUntil: &past}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAccountStatus(tt.user)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
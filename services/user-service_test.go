@@ -1,23 +1,41 @@
 package services
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"strings"
 	"testing"
+	"time"
 	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
 	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/mailer"
+	"ypeskov/go_hillel_9/internal/password"
 	"ypeskov/go_hillel_9/repository/models"
 	"ypeskov/go_hillel_9/repository/repositories/mocks"
 )
 
 func TestCreateUser(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(mocks.UserRepositoryInterface)
+	mockTypeRepo := new(mocks.UserTypeRepositoryInterface)
 	mockCfg, _ := config.NewConfig()
 	mockLog := log.New(mockCfg)
 
-	service := GetUserService(mockRepo, mockLog, mockCfg)
+	retiredAt := time.Now()
+	mockTypeRepo.On("GetUserTypeById", mock.Anything, 2).
+		Return(&models.UserType{Id: 2, TypeCode: models.BuyerTypeCode, SelfAssignable: true}, nil)
+	mockTypeRepo.On("GetUserTypeById", mock.Anything, 3).
+		Return(&models.UserType{Id: 3, TypeCode: models.AdminTypeCode, SelfAssignable: true}, nil)
+	mockTypeRepo.On("GetUserTypeById", mock.Anything, 4).Return(&models.UserType{Id: 4, TypeCode: "STAFF"}, nil)
+	mockTypeRepo.On("GetUserTypeById", mock.Anything, 5).Return(&models.UserType{Id: 5, RetiredAt: &retiredAt}, nil)
+	mockTypeRepo.On("GetUserTypeById", mock.Anything, 99).Return(nil, apperrors.NotFoundErr)
+
+	hasher, _ := password.NewHasher(mockCfg)
+	policy, _ := password.NewPolicy(mockCfg)
+	service := GetUserService(mockRepo, mockTypeRepo, mailer.New(mockCfg, mockLog), hasher, policy, mockLog, mockCfg)
 
 	tests := []struct {
 		name        string
@@ -25,6 +43,8 @@ func TestCreateUser(t *testing.T) {
 		lastName    string
 		email       string
 		password    string
+		userTypeId  int32
+		operator    bool
 		expectedErr error
 		mockReturn  func()
 	}{
@@ -33,10 +53,11 @@ func TestCreateUser(t *testing.T) {
 			firstName:   "Test",
 			lastName:    "User",
 			email:       "example@example.com",
-			password:    "1",
+			password:    "correct horse battery staple",
+			userTypeId:  2,
 			expectedErr: nil,
 			mockReturn: func() {
-				mockRepo.On("CreateUser", mock.MatchedBy(func(user *models.User) bool {
+				mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
 					return user.FirstName == "Test" &&
 						user.LastName == "User" &&
 						user.Email == "example@example.com" &&
@@ -47,45 +68,107 @@ func TestCreateUser(t *testing.T) {
 					LastName:  "User",
 					Email:     "example@example.com",
 				}, nil)
+				mockRepo.On("AddEmailVerificationToken", mock.Anything, 1, mock.Anything, mock.Anything).Return(nil)
 			},
 		},
 		{
-			name:        "TestCreateUser Failed Password Hash Empty String",
+			name:        "TestCreateUser Failed Password Too Short",
 			firstName:   "Test",
 			lastName:    "User",
 			email:       "example@example.com",
 			password:    "",
-			expectedErr: errors.New("password too short to be a bcrypted password"),
-			mockReturn: func() {
-				mockRepo.On("CreateUser", mock.Anything).Return(nil, errors.New("password too short to be a bcrypted password"))
-			},
+			expectedErr: apperrors.WeakPasswordErr,
+			mockReturn:  func() {},
 		},
 		{
-			name:        "TestCreateUser Failed Password Hash Long String",
+			name:        "TestCreateUser Failed Password Too Long",
 			firstName:   "Test",
 			lastName:    "User",
 			email:       "example@example.com",
-			password:    string(make([]byte, 100)),
-			expectedErr: errors.New("bcrypt: password length exceeds 72 bytes"),
-			mockReturn: func() {
-				mockRepo.On("CreateUser", mock.Anything).Return(nil, errors.New("bcrypt: password length exceeds 72 bytes"))
-			},
+			password:    strings.Repeat("a", 200),
+			expectedErr: apperrors.WeakPasswordErr,
+			mockReturn:  func() {},
+		},
+		{
+			name:        "TestCreateUser Failed Common Password",
+			firstName:   "Test",
+			lastName:    "User",
+			email:       "example@example.com",
+			password:    "Password123",
+			expectedErr: apperrors.WeakPasswordErr,
+			mockReturn:  func() {},
+		},
+		{
+			name:        "TestCreateUser Failed Unknown User Type",
+			firstName:   "Test",
+			lastName:    "User",
+			email:       "example@example.com",
+			password:    "correct horse battery staple",
+			userTypeId:  99,
+			expectedErr: apperrors.InvalidUserTypeErr,
+			mockReturn:  func() {},
+		},
+		{
+			name:        "TestCreateUser Failed Retired User Type",
+			firstName:   "Test",
+			lastName:    "User",
+			email:       "example@example.com",
+			password:    "correct horse battery staple",
+			userTypeId:  5,
+			expectedErr: apperrors.InvalidUserTypeErr,
+			mockReturn:  func() {},
+		},
+		{
+			name:        "TestCreateUser Failed Admin User Type",
+			firstName:   "Test",
+			lastName:    "User",
+			email:       "example@example.com",
+			password:    "correct horse battery staple",
+			userTypeId:  3,
+			expectedErr: apperrors.UserTypeNotSelfAssignableErr,
+			mockReturn:  func() {},
+		},
+		{
+			name:        "TestCreateUser Failed Not Self-Assignable User Type",
+			firstName:   "Test",
+			lastName:    "User",
+			email:       "example@example.com",
+			password:    "correct horse battery staple",
+			userTypeId:  4,
+			expectedErr: apperrors.UserTypeNotSelfAssignableErr,
+			mockReturn:  func() {},
+		},
+		{
+			name:        "TestCreateUser Admin User Type By Operator",
+			firstName:   "Test",
+			lastName:    "User",
+			email:       "example@example.com",
+			password:    "correct horse battery staple",
+			userTypeId:  3,
+			operator:    true,
+			expectedErr: nil,
+			mockReturn:  func() {},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockReturn()
-			user, err := service.CreateUser(&models.User{
+			create := service.CreateUser
+			if tt.operator {
+				create = service.CreateUserByOperator
+			}
+			user, err := create(ctx, &models.User{
 				FirstName:    tt.firstName,
 				LastName:     tt.lastName,
 				Email:        tt.email,
 				PasswordHash: tt.password,
+				UserTypeId:   tt.userTypeId,
 			})
 			fmt.Printf("user: %v\n", user)
 			if tt.expectedErr != nil {
-				assert.Error(t, err)
-				assert.Equal(t, tt.expectedErr, err)
+				assert.Nil(t, user)
+				assert.ErrorIs(t, err, tt.expectedErr)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.firstName, user.FirstName)
@@ -98,11 +181,15 @@ func TestCreateUser(t *testing.T) {
 }
 
 func TestGetUsersList(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(mocks.UserRepositoryInterface)
 	mockCfg, _ := config.NewConfig()
 	mockLog := log.New(mockCfg)
 
-	service := GetUserService(mockRepo, mockLog, mockCfg)
+	hasher, _ := password.NewHasher(mockCfg)
+	policy, _ := password.NewPolicy(mockCfg)
+	service := GetUserService(mockRepo, new(mocks.UserTypeRepositoryInterface), mailer.New(mockCfg, mockLog), hasher,
+		policy, mockLog, mockCfg)
 
 	tests := []struct {
 		name       string
@@ -111,7 +198,7 @@ func TestGetUsersList(t *testing.T) {
 		{
 			name: "TestGetUsersList",
 			mockReturn: func() {
-				mockRepo.On("GetUsersList").Return([]*models.User{
+				mockRepo.On("GetUsersList", mock.Anything, mock.Anything).Return([]*models.User{
 					{
 						Id:        1,
 						FirstName: "Test",
@@ -126,7 +213,7 @@ func TestGetUsersList(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockReturn()
-			users, err := service.GetUsersList()
+			users, err := service.GetUsersList(ctx)
 			assert.NoError(t, err)
 			assert.Len(t, users, 1)
 		})
@@ -134,11 +221,15 @@ func TestGetUsersList(t *testing.T) {
 }
 
 func TestGetUserByEmail(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(mocks.UserRepositoryInterface)
 	mockCfg, _ := config.NewConfig()
 	mockLog := log.New(mockCfg)
 
-	service := GetUserService(mockRepo, mockLog, mockCfg)
+	hasher, _ := password.NewHasher(mockCfg)
+	policy, _ := password.NewPolicy(mockCfg)
+	service := GetUserService(mockRepo, new(mocks.UserTypeRepositoryInterface), mailer.New(mockCfg, mockLog), hasher,
+		policy, mockLog, mockCfg)
 
 	tests := []struct {
 		name       string
@@ -149,7 +240,7 @@ func TestGetUserByEmail(t *testing.T) {
 			name:  "TestGetUserByEmail",
 			email: "example@example.com",
 			mockReturn: func() {
-				mockRepo.On("GetUserByEmail", "example@example.com").Return(&models.User{
+				mockRepo.On("GetUserByEmail", mock.Anything, "example@example.com").Return(&models.User{
 					Id:        1,
 					FirstName: "Test",
 					LastName:  "User",
@@ -162,9 +253,81 @@ func TestGetUserByEmail(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockReturn()
-			user := service.GetUserByEmail(tt.email)
+			user := service.GetUserByEmail(ctx, tt.email)
 			assert.NotNil(t, user)
 			assert.Equal(t, tt.email, user.Email)
 		})
 	}
 }
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.UserRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+
+	hasher, _ := password.NewHasher(mockCfg)
+	policy, _ := password.NewPolicy(mockCfg)
+	service := GetUserService(mockRepo, new(mocks.UserTypeRepositoryInterface), mailer.New(mockCfg, mockLog), hasher,
+		policy, mockLog, mockCfg)
+
+	tests := []struct {
+		name        string
+		token       string
+		expectedErr error
+		mockReturn  func()
+	}{
+		{
+			name:        "Valid token",
+			token:       "valid",
+			expectedErr: nil,
+			mockReturn: func() {
+				mockRepo.On("GetUserByEmailVerificationToken", mock.Anything,
+					hashToken("valid")).Return(&models.User{Id: 1})
+				mockRepo.On("SetEmailVerified", mock.Anything, 1).Return(nil)
+			},
+		},
+		{
+			name:        "Unknown or expired token",
+			token:       "invalid",
+			expectedErr: apperrors.InvalidVerificationTokenErr,
+			mockReturn: func() {
+				mockRepo.On("GetUserByEmailVerificationToken", mock.Anything, hashToken("invalid")).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+			err := service.VerifyEmail(ctx, tt.token)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetPassword(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.UserRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+
+	hasher, _ := password.NewHasher(mockCfg)
+	policy, _ := password.NewPolicy(mockCfg)
+	service := GetUserService(mockRepo, new(mocks.UserTypeRepositoryInterface), mailer.New(mockCfg, mockLog), hasher,
+		policy, mockLog, mockCfg)
+
+	mockRepo.On("UpdatePasswordHash", mock.Anything, 1, mock.MatchedBy(func(hash string) bool {
+		ok, _ := hasher.Verify("correct horse battery staple", hash)
+
+		return ok
+	})).Return(nil)
+
+	assert.NoError(t, service.SetPassword(ctx, 1, "correct horse battery staple"))
+	assert.ErrorIs(t, service.SetPassword(ctx, 1, "short"), apperrors.WeakPasswordErr)
+	mockRepo.AssertNumberOfCalls(t, "UpdatePasswordHash", 1)
+}
@@ -1,7 +1,12 @@
 package services
 
 import (
+	"context"
+	goErrors "errors"
+	"regexp"
+	"strings"
 	"ypeskov/go_hillel_9/internal/config"
+	"ypeskov/go_hillel_9/internal/errors"
 	"ypeskov/go_hillel_9/internal/log"
 	"ypeskov/go_hillel_9/repository/models"
 	"ypeskov/go_hillel_9/repository/repositories"
@@ -14,6 +19,12 @@ type UserTypeService struct {
 }
 
 type UserTypeServiceInterface interface {
+	GetUserTypesList(ctx context.Context) ([]*models.UserType, error)
+	CreateUserType(ctx context.Context, userType *models.UserType) (*models.UserType, error)
+	UpdateUserTypeDescription(ctx context.Context, id int, description string) error
+	RetireUserType(ctx context.Context, id int, replacementCode string) (int, error)
+	SetMfaRequired(ctx context.Context, id int, required bool) error
+	SetSelfAssignable(ctx context.Context, id int, assignable bool) error
 }
 
 func GetUserTypeService(userType repositories.UserTypeRepositoryInterface,
@@ -26,6 +37,90 @@ func GetUserTypeService(userType repositories.UserTypeRepositoryInterface,
 	}
 }
 
-func (uts *UserTypeService) GetUserTypesList() ([]*models.UserType, error) {
-	return uts.userTypeRepo.GetUserTypesList()
+func (uts *UserTypeService) GetUserTypesList(ctx context.Context) ([]*models.UserType, error) {
+	return uts.userTypeRepo.GetUserTypesList(ctx)
+}
+
+var userTypeCodeRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+func (uts *UserTypeService) CreateUserType(ctx context.Context, userType *models.UserType) (*models.UserType, error) {
+	userType.TypeCode = strings.ToUpper(strings.TrimSpace(userType.TypeCode))
+	if !userTypeCodeRe.MatchString(userType.TypeCode) {
+		return nil, errors.InvalidUserTypeCodeErr
+	}
+	if userType.SelfAssignable && userType.IsAdmin() {
+		return nil, errors.AdminTypeSelfAssignableErr
+	}
+
+	_, err := uts.userTypeRepo.GetUserTypeByCode(ctx, userType.TypeCode)
+	if err == nil {
+		return nil, errors.UserTypeExistsErr
+	}
+	if !goErrors.Is(err, errors.NotFoundErr) {
+		return nil, err
+	}
+
+	newUserType, err := uts.userTypeRepo.CreateUserType(ctx, userType)
+	if err != nil {
+		return nil, err
+	}
+
+	uts.log.Infof("User type %s created", newUserType.TypeCode)
+
+	return newUserType, nil
+}
+
+func (uts *UserTypeService) UpdateUserTypeDescription(ctx context.Context, id int, description string) error {
+	return uts.userTypeRepo.UpdateUserTypeDescription(ctx, id, description)
+}
+
+// RetireUserType closes the type for new users and moves its current users to the replacement type.
+// Built-in types can't be retired because the code relies on them.
+func (uts *UserTypeService) RetireUserType(ctx context.Context, id int, replacementCode string) (int, error) {
+	userType, err := uts.userTypeRepo.GetUserTypeById(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if userType.IsBuiltIn() {
+		return 0, errors.UserTypeBuiltInErr
+	}
+	if userType.RetiredAt != nil {
+		return 0, errors.UserTypeRetiredErr
+	}
+
+	replacement, err := uts.userTypeRepo.GetUserTypeByCode(ctx, replacementCode)
+	if err != nil {
+		return 0, err
+	}
+	if replacement.Id == userType.Id || replacement.RetiredAt != nil {
+		return 0, errors.InvalidReplacementUserTypeErr
+	}
+
+	moved, err := uts.userTypeRepo.RetireUserType(ctx, userType.Id, replacement.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	uts.log.Infof("User type %s retired, %d users moved to %s", userType.TypeCode, moved, replacement.TypeCode)
+
+	return moved, nil
+}
+
+// SetMfaRequired makes two-factor authentication mandatory for users of the type.
+// Users who haven't enabled it yet can only enroll until they do.
+func (uts *UserTypeService) SetMfaRequired(ctx context.Context, id int, required bool) error {
+	return uts.userTypeRepo.SetMfaRequired(ctx, id, required)
+}
+
+// SetSelfAssignable opens the type to sign-up or closes it, the administrator type can't be opened.
+func (uts *UserTypeService) SetSelfAssignable(ctx context.Context, id int, assignable bool) error {
+	userType, err := uts.userTypeRepo.GetUserTypeById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if assignable && userType.IsAdmin() {
+		return errors.AdminTypeSelfAssignableErr
+	}
+
+	return uts.userTypeRepo.SetSelfAssignable(ctx, id, assignable)
 }
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"ypeskov/go_hillel_9/internal/config"
+	apperrors "ypeskov/go_hillel_9/internal/errors"
+	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/repository/models"
+	"ypeskov/go_hillel_9/repository/repositories/mocks"
+)
+
+func TestCreateUserType(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.UserTypeRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetUserTypeService(mockRepo, mockLog, mockCfg)
+
+	mockRepo.On("GetUserTypeByCode", mock.Anything, models.SellerTypeCode).Return(&models.UserType{Id: 1}, nil)
+	mockRepo.On("GetUserTypeByCode", mock.Anything, "MODERATOR").Return(nil, apperrors.NotFoundErr)
+	mockRepo.On("CreateUserType", mock.Anything, mock.MatchedBy(func(userType *models.UserType) bool {
+		return userType.TypeCode == "MODERATOR"
+	})).Return(&models.UserType{Id: 4, TypeCode: "MODERATOR"}, nil)
+
+	tests := []struct {
+		name        string
+		typeCode    string
+		expectedErr error
+	}{
+		{"Code is normalized", " moderator ", nil},
+		{"Invalid code", "1 moderator", apperrors.InvalidUserTypeCodeErr},
+		{"Duplicate code", "seller", apperrors.UserTypeExistsErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userType, err := service.CreateUserType(ctx, &models.UserType{TypeName: "Moderator", TypeCode: tt.typeCode})
+			if tt.expectedErr != nil {
+				assert.Nil(t, userType)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 4, userType.Id)
+			}
+		})
+	}
+}
+
+func TestRetireUserType(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.UserTypeRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetUserTypeService(mockRepo, mockLog, mockCfg)
+
+	retiredAt := time.Now()
+	mockRepo.On("GetUserTypeById", mock.Anything, 1).
+		Return(&models.UserType{Id: 1, TypeCode: models.SellerTypeCode}, nil)
+	mockRepo.On("GetUserTypeById", mock.Anything, 4).Return(&models.UserType{Id: 4, TypeCode: "MODERATOR"}, nil)
+	mockRepo.On("GetUserTypeById", mock.Anything, 5).Return(&models.UserType{Id: 5, TypeCode: "OLD",
+		RetiredAt: &retiredAt}, nil)
+	mockRepo.On("GetUserTypeById", mock.Anything, 9).Return(nil, apperrors.NotFoundErr)
+	mockRepo.On("GetUserTypeByCode", mock.Anything, models.BuyerTypeCode).
+		Return(&models.UserType{Id: 2, TypeCode: models.BuyerTypeCode}, nil)
+	mockRepo.On("GetUserTypeByCode", mock.Anything, "MODERATOR").
+		Return(&models.UserType{Id: 4, TypeCode: "MODERATOR"}, nil)
+	mockRepo.On("GetUserTypeByCode", mock.Anything, "OLD").
+		Return(&models.UserType{Id: 5, TypeCode: "OLD", RetiredAt: &retiredAt}, nil)
+	mockRepo.On("RetireUserType", mock.Anything, 4, 2).Return(3, nil)
+
+	tests := []struct {
+		name            string
+		id              int
+		replacementCode string
+		expectedErr     error
+	}{
+		{"Unknown type", 9, models.BuyerTypeCode, apperrors.NotFoundErr},
+		{"Built-in type", 1, models.BuyerTypeCode, apperrors.UserTypeBuiltInErr},
+		{"Already retired", 5, models.BuyerTypeCode, apperrors.UserTypeRetiredErr},
+		{"Replacement is the same type", 4, "MODERATOR", apperrors.InvalidReplacementUserTypeErr},
+		{"Replacement is retired", 4, "OLD", apperrors.InvalidReplacementUserTypeErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.RetireUserType(ctx, tt.id, tt.replacementCode)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+
+	moved, err := service.RetireUserType(ctx, 4, models.BuyerTypeCode)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, moved)
+}
+
+func TestSetSelfAssignable(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.UserTypeRepositoryInterface)
+	mockCfg, _ := config.NewConfig()
+	mockLog := log.New(mockCfg)
+	service := GetUserTypeService(mockRepo, mockLog, mockCfg)
+
+	mockRepo.On("GetUserTypeById", mock.Anything, 3).Return(&models.UserType{Id: 3, TypeCode: models.AdminTypeCode}, nil)
+	mockRepo.On("GetUserTypeById", mock.Anything, 4).Return(&models.UserType{Id: 4, TypeCode: "MODERATOR"}, nil)
+	mockRepo.On("GetUserTypeById", mock.Anything, 9).Return(nil, apperrors.NotFoundErr)
+	mockRepo.On("SetSelfAssignable", mock.Anything, 3, false).Return(nil)
+	mockRepo.On("SetSelfAssignable", mock.Anything, 4, true).Return(nil)
+
+	tests := []struct {
+		name        string
+		id          int
+		assignable  bool
+		expectedErr error
+	}{
+		{"Unknown type", 9, true, apperrors.NotFoundErr},
+		{"Open administrator type", 3, true, apperrors.AdminTypeSelfAssignableErr},
+		{"Close administrator type", 3, false, nil},
+		{"Open custom type", 4, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.SetSelfAssignable(ctx, tt.id, tt.assignable)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -1,159 +1,215 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	goErrors "errors"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"fmt"
 	"time"
 	"ypeskov/go_hillel_9/internal/config"
 	"ypeskov/go_hillel_9/internal/errors"
 	"ypeskov/go_hillel_9/internal/log"
+	"ypeskov/go_hillel_9/internal/mailer"
+	"ypeskov/go_hillel_9/internal/password"
 	"ypeskov/go_hillel_9/repository/models"
 	"ypeskov/go_hillel_9/repository/repositories"
 )
 
 type UsersService struct {
-	log      *log.Logger
-	cfg      *config.Config
-	userRepo repositories.UserRepositoryInterface
-}
-
-type Claims struct {
-	Id    int    `json:"id"`
-	Email string `json:"email"`
-	jwt.RegisteredClaims
+	log          *log.Logger
+	cfg          *config.Config
+	userRepo     repositories.UserRepositoryInterface
+	userTypeRepo repositories.UserTypeRepositoryInterface
+	mailer       mailer.Mailer
+	hasher       *password.Hasher
+	policy       *password.Policy
 }
 
 type UsersServiceInterface interface {
-	CreateUser(srcUser *models.User) (*models.User, error)
-	GetUsersList() ([]*models.User, error)
-	GetJWT(email string, password string, minutes time.Duration, refreshToken bool) (string, error)
-	GetRefreshToken(email string, password string, update bool) (string, error)
-	GetUserByEmail(email string) *models.User
-	GetUserByRefreshToken(token string) (*models.User, error)
+	CreateUser(ctx context.Context, srcUser *models.User) (*models.User, error)
+	CreateUserByOperator(ctx context.Context, srcUser *models.User) (*models.User, error)
+	GetUsersList(ctx context.Context) ([]*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) *models.User
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerificationEmail(ctx context.Context, email string) error
+	GetUserType(ctx context.Context, user *models.User) (*models.UserType, error)
+	SetEmailVerified(ctx context.Context, userId int) error
+	SetPassword(ctx context.Context, userId int, plainPassword string) error
 }
 
+const verificationTokenBytes = 32
+
 func GetUserService(userRepo repositories.UserRepositoryInterface,
-	log *log.Logger, cfg *config.Config) UsersServiceInterface {
+	userTypeRepo repositories.UserTypeRepositoryInterface, mailer mailer.Mailer, hasher *password.Hasher,
+	policy *password.Policy, log *log.Logger, cfg *config.Config) UsersServiceInterface {
 
 	return &UsersService{
-		log:      log,
-		cfg:      cfg,
-		userRepo: userRepo,
+		log:          log,
+		cfg:          cfg,
+		userRepo:     userRepo,
+		userTypeRepo: userTypeRepo,
+		mailer:       mailer,
+		hasher:       hasher,
+		policy:       policy,
 	}
 }
 
-func (us *UsersService) CreateUser(srcUser *models.User) (*models.User, error) {
-	if len(srcUser.PasswordHash) == 0 {
-		return nil, goErrors.New("password too short to be a bcrypted password")
+// CreateUser signs up a user, only self-assignable types can be chosen
+func (us *UsersService) CreateUser(ctx context.Context, srcUser *models.User) (*models.User, error) {
+	return us.createUser(ctx, srcUser, true)
+}
+
+// CreateUserByOperator creates a user of any active type including ADMIN, for the command line
+func (us *UsersService) CreateUserByOperator(ctx context.Context, srcUser *models.User) (*models.User, error) {
+	return us.createUser(ctx, srcUser, false)
+}
+
+func (us *UsersService) createUser(ctx context.Context, srcUser *models.User, signUp bool) (*models.User, error) {
+	// the password comes in the PasswordHash field and is replaced by its hash
+	err := us.policy.Validate(srcUser.PasswordHash)
+	if err != nil {
+		return nil, err
 	}
 
-	hash, err := hashPassword(srcUser.PasswordHash)
+	err = us.checkUserType(ctx, int(srcUser.UserTypeId), signUp)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := us.hasher.Hash(srcUser.PasswordHash)
 	if err != nil {
 		us.log.Error("failed to hash password", err)
 
 		return nil, err
 	}
 	srcUser.PasswordHash = hash
+	srcUser.EmailVerified = false
 
-	return us.userRepo.CreateUser(srcUser)
-}
-
-func (us *UsersService) GetUsersList() ([]*models.User, error) {
-	return us.userRepo.GetUsersList()
-}
-
-func (us *UsersService) GetJWT(email string,
-	password string,
-	minutes time.Duration,
-	refreshToken bool) (string, error) {
-	user := us.userRepo.GetUserByEmail(email)
-	if user == nil {
-		return "", errors.NotFoundErr
+	newUser, err := us.userRepo.CreateUser(ctx, srcUser)
+	if err != nil {
+		return nil, err
 	}
 
-	// If we are not refreshing the token, we need to check the password
-	if refreshToken == false {
-		if !checkPasswordHash(password, user.PasswordHash) {
-			return "", errors.UnauthorizedErr
-		}
+	// the account is created anyway, the user can ask to resend the email
+	err = us.sendVerificationEmail(ctx, newUser)
+	if err != nil {
+		us.log.Errorln("failed to send verification email", err)
 	}
 
-	expirationTime := time.Now().Add(minutes * time.Minute)
-
-	claims := &Claims{
-		Id:    user.Id,
-		Email: user.Email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-		},
-	}
+	return newUser, nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(us.cfg.SecretKey))
+// checkUserType makes sure new users get a type that exists and isn't retired.
+// At sign-up the type must be self-assignable, so nobody can register as an administrator.
+func (us *UsersService) checkUserType(ctx context.Context, id int, signUp bool) error {
+	userType, err := us.userTypeRepo.GetUserTypeById(ctx, id)
 	if err != nil {
-		us.log.Errorln("failed to sign token: ", err)
+		if goErrors.Is(err, errors.NotFoundErr) {
+			return errors.InvalidUserTypeErr
+		}
 
-		return "", errors.InternalServerErr
+		return err
+	}
+	if userType.RetiredAt != nil {
+		return errors.InvalidUserTypeErr
 	}
+	if signUp && (!userType.SelfAssignable || userType.IsAdmin()) {
+		return errors.UserTypeNotSelfAssignableErr
+	}
+
+	return nil
+}
+
+func (us *UsersService) GetUsersList(ctx context.Context) ([]*models.User, error) {
+	return us.userRepo.GetUsersList(ctx)
+}
+
+func (us *UsersService) GetUserByEmail(ctx context.Context, email string) *models.User {
+	return us.userRepo.GetUserByEmail(ctx, email)
+}
 
-	return tokenString, nil
+func (us *UsersService) GetUserType(ctx context.Context, user *models.User) (*models.UserType, error) {
+	return us.userRepo.GetUserType(ctx, user)
 }
 
-func (us *UsersService) GetRefreshToken(email string, password string, update bool) (string, error) {
-	user := us.userRepo.GetUserByEmail(email)
+func (us *UsersService) VerifyEmail(ctx context.Context, token string) error {
+	user := us.userRepo.GetUserByEmailVerificationToken(ctx, hashToken(token))
 	if user == nil {
-		return "", errors.NotFoundErr
+		return errors.InvalidVerificationTokenErr
 	}
 
-	minutes := time.Duration(us.cfg.RefreshTokenLifetimeMinutes)
-	token, err := us.GetJWT(email, password, minutes, update)
-	if err != nil {
-		us.log.Errorln("failed to get JWT", err)
+	return us.userRepo.SetEmailVerified(ctx, user.Id)
+}
 
-		return "", err
+// SetEmailVerified confirms the email without a token, for accounts created by an operator
+func (us *UsersService) SetEmailVerified(ctx context.Context, userId int) error {
+	return us.userRepo.SetEmailVerified(ctx, userId)
+}
+
+// SetPassword replaces the password of the user, the new one has to satisfy the password policy
+func (us *UsersService) SetPassword(ctx context.Context, userId int, plainPassword string) error {
+	err := us.policy.Validate(plainPassword)
+	if err != nil {
+		return err
 	}
 
-	err = us.userRepo.AddOrUpdateRefreshToken(user.Id, token)
+	hash, err := us.hasher.Hash(plainPassword)
 	if err != nil {
-		us.log.Errorln("failed to add refresh token", err)
+		us.log.Error("failed to hash password", err)
 
-		return "", errors.InternalServerErr
+		return err
 	}
 
-	return token, nil
+	return us.userRepo.UpdatePasswordHash(ctx, userId, hash)
 }
 
-func (us *UsersService) GetUserByEmail(email string) *models.User {
-	return us.userRepo.GetUserByEmail(email)
+func (us *UsersService) ResendVerificationEmail(ctx context.Context, email string) error {
+	user := us.userRepo.GetUserByEmail(ctx, email)
+	if user == nil {
+		return errors.NotFoundErr
+	}
+
+	if user.EmailVerified {
+		return nil
+	}
+
+	return us.sendVerificationEmail(ctx, user)
 }
 
-func hashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+func (us *UsersService) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := generateToken(verificationTokenBytes)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	return string(bytes), nil
-}
+	lifetime := time.Duration(us.cfg.EmailVerificationLifetimeMinutes) * time.Minute
+	err = us.userRepo.AddEmailVerificationToken(ctx, user.Id, hashToken(token), time.Now().UTC().Add(lifetime))
+	if err != nil {
+		return err
+	}
 
-func checkPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	link := fmt.Sprintf("%s/users/verify-email/?token=%s", us.cfg.AppBaseUrl, token)
+	body := fmt.Sprintf("Hello %s,\n\nPlease confirm your email address by opening the link below:\n%s\n",
+		user.FirstName, link)
 
-	return err == nil
+	return us.mailer.Send(user.Email, "Confirm your email address", body)
 }
 
-func (us *UsersService) GetUserByRefreshToken(token string) (*models.User, error) {
-	user := us.userRepo.GetUserByRefreshToken(token)
-	if user == nil {
-		us.log.Errorln("failed to get user by refresh token")
-
-		return nil, errors.UnauthorizedErr
+// generateToken returns a random hex encoded token of n bytes
+func generateToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
 
-	return user, nil
+	return hex.EncodeToString(b), nil
 }
 
-func (us *UsersService) GetUserType(user *models.User) (*models.UserType, error) {
-	return us.userRepo.GetUserType(user)
+// hashToken is used to store one-time tokens so a leaked table can't be used to act as a user
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
 }